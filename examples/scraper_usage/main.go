@@ -12,12 +12,11 @@ import (
 
 func main() {
 	// スクレイパー設定
-	config := &scraper.ScraperConfig{
-		UserID:       "your-user-id",
-		Password:     "your-password",
-		DownloadPath: "./temp",
-		Headless:     true,
-	}
+	config := scraper.DefaultScraperConfig()
+	config.UserID = "your-user-id"
+	config.Password = "your-password"
+	config.DownloadPath = "./temp"
+	config.Headless = true
 
 	scraperInstance, err := scraper.NewETCScraper(config, nil)
 	if err != nil {
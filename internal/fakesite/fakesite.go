@@ -0,0 +1,441 @@
+// Package fakesite serves a local imitation of the ETC meisai service (www.etc-meisai.jp).
+//
+// It reproduces the pages and form field names the scraper relies on: the top page login
+// link, the login form (risLoginId / risPassword), the search-condition form (sokoKbn,
+// fromYYYY ... toDD, focusTarget_Save, focusTarget), the result list (hakkoMeisai) and the
+// goOutput CSV download. Point ScraperConfig.BaseURL at Site.URL to run the real browser
+// path without network access.
+package fakesite
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// Function codes used in the site's /etc/R?funccode= URLs
+const (
+	FuncLogin  = "1013000000"
+	FuncSearch = "1032000000"
+	FuncCSV    = "1032500000"
+	FuncLogout = "1011000000"
+)
+
+// Messages shown by the site. The scraper derives its typed login errors from these.
+const (
+	MessageInvalidCredentials = "ログインIDまたはパスワードが正しくありません。"
+	MessageAccountLocked      = "アカウントがロックされています。"
+	MessagePasswordExpired    = "パスワードの有効期限が切れています。パスワードの変更を行ってください。"
+	MessageMaintenance        = "現在システムメンテナンス中のため、サービスを停止しています。"
+)
+
+// sessionCookie is the name of the cookie carrying the login session
+const sessionCookie = "JSESSIONID"
+
+// CSVHeader is the header line of the meisai CSV
+var CSVHeader = []string{
+	"利用年月日（自）", "時分（自）", "利用年月日（至）", "時分（至）",
+	"利用ＩＣ（自）", "利用ＩＣ（至）", "割引前料金", "ＥＴＣ割引額", "通行料金",
+	"車種", "車両番号", "ＥＴＣカード番号", "備考",
+}
+
+// Record is one toll usage row. Searches match on the exit date.
+type Record struct {
+	EntryAt       time.Time
+	ExitAt        time.Time
+	EntryIC       string
+	ExitIC        string
+	BaseFare      int
+	Discount      int
+	Fare          int
+	VehicleClass  string
+	VehicleNumber string
+	CardNumber    string
+	Note          string
+}
+
+// Account is a registered user of the fake site
+type Account struct {
+	Password        string
+	Locked          bool
+	PasswordExpired bool
+	// Rejected answers every login with the bare login form and no message
+	Rejected bool
+	Records  []Record
+}
+
+type session struct {
+	userID  string
+	sokoKbn string
+	from    time.Time
+	to      time.Time
+	results []Record
+}
+
+// Site is a running fake ETC meisai service
+type Site struct {
+	*httptest.Server
+
+	// Now is used for the year range of the search form; it defaults to time.Now
+	Now func() time.Time
+
+	mu          sync.Mutex
+	accounts    map[string]*Account
+	sessions    map[string]*session
+	maintenance bool
+	shiftJIS    bool
+	downloads   int
+}
+
+// New starts a fake site. Call Close when done.
+func New() *Site {
+	site := &Site{
+		Now:      time.Now,
+		accounts: make(map[string]*Account),
+		sessions: make(map[string]*session),
+	}
+	site.Server = httptest.NewServer(site)
+	return site
+}
+
+// AddAccount registers userID with the given password and meisai records
+func (s *Site) AddAccount(userID, password string, records ...Record) *Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account := &Account{Password: password, Records: records}
+	s.accounts[userID] = account
+	return account
+}
+
+// SetMaintenance switches every page to the maintenance notice
+func (s *Site) SetMaintenance(on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maintenance = on
+}
+
+// SetShiftJIS serves the pages in Shift_JIS, as the real site does, and reads posted forms in
+// Shift_JIS, the charset a browser sends them in from such a page
+func (s *Site) SetShiftJIS(on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shiftJIS = on
+}
+
+// ExpireSessions drops all login sessions, as the real site does after inactivity
+func (s *Site) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = make(map[string]*session)
+}
+
+// Downloads returns how many CSV files have been served
+func (s *Site) Downloads() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.downloads
+}
+
+// Sessions returns how many login sessions are open (logging out closes one)
+func (s *Site) Sessions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+// ServeHTTP routes requests the way the real site does, by funccode
+func (s *Site) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	maintenance := s.maintenance
+	s.mu.Unlock()
+	if maintenance {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		s.render(w, maintenancePage, nil)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/":
+		s.render(w, topPage, map[string]string{"LoginFunc": FuncLogin})
+		return
+	case "/etc/R":
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.URL.Query().Get("funccode") {
+	case FuncLogin:
+		s.handleLogin(w, r)
+	case FuncSearch:
+		s.handleSearch(w, r)
+	case FuncCSV:
+		s.handleCSV(w, r)
+	case FuncLogout:
+		s.handleLogout(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Site) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.renderLogin(w, "")
+		return
+	}
+
+	userID := s.formValue(r, "risLoginId")
+	password := s.formValue(r, "risPassword")
+
+	s.mu.Lock()
+	account, exists := s.accounts[userID]
+	s.mu.Unlock()
+
+	switch {
+	case !exists || account.Password != password:
+		s.renderLogin(w, MessageInvalidCredentials)
+		return
+	case account.Locked:
+		s.renderLogin(w, MessageAccountLocked)
+		return
+	case account.PasswordExpired:
+		s.renderLogin(w, MessagePasswordExpired)
+		return
+	case account.Rejected:
+		s.renderLogin(w, "")
+		return
+	}
+
+	id := newSessionID()
+	s.mu.Lock()
+	s.sessions[id] = &session{userID: userID, sokoKbn: "0"}
+	s.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: id, Path: "/"})
+	http.Redirect(w, r, "/etc/R?funccode="+FuncSearch, http.StatusSeeOther)
+}
+
+func (s *Site) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		s.mu.Lock()
+		delete(s.sessions, cookie.Value)
+		s.mu.Unlock()
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (s *Site) handleSearch(w http.ResponseWriter, r *http.Request) {
+	sess := s.session(r)
+	if sess == nil {
+		// Expired session: the site falls back to the login form
+		s.renderLogin(w, "")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		s.renderSearch(w, sess, "")
+		return
+	}
+
+	from, fromErr := formDate(r, "from")
+	to, toErr := formDate(r, "to")
+	if fromErr != nil || toErr != nil || from.After(to) {
+		s.renderSearch(w, sess, "利用年月日の指定が正しくありません。")
+		return
+	}
+
+	sokoKbn := s.formValue(r, "sokoKbn")
+	s.mu.Lock()
+	sess.sokoKbn = sokoKbn
+	sess.from, sess.to = from, to
+	s.mu.Unlock()
+
+	if r.FormValue("focusTarget_Save") != "" {
+		s.renderSearch(w, sess, "検索条件を記憶しました。")
+		return
+	}
+
+	s.mu.Lock()
+	var results []Record
+	for _, record := range s.accounts[sess.userID].Records {
+		day := time.Date(record.ExitAt.Year(), record.ExitAt.Month(), record.ExitAt.Day(), 0, 0, 0, 0, time.Local)
+		if !day.Before(from) && !day.After(to) {
+			results = append(results, record)
+		}
+	}
+	sess.results = results
+	s.mu.Unlock()
+
+	rows := make([]map[string]string, len(results))
+	for i, record := range results {
+		rows[i] = map[string]string{
+			"Index":   strconv.Itoa(i),
+			"ExitAt":  record.ExitAt.Format("2006/01/02 15:04"),
+			"EntryIC": record.EntryIC,
+			"ExitIC":  record.ExitIC,
+			"Fare":    strconv.Itoa(record.Fare),
+		}
+	}
+	s.render(w, resultPage, map[string]interface{}{
+		"Period":     fmt.Sprintf("%s ～ %s", from.Format("2006年01月02日"), to.Format("2006年01月02日")),
+		"Rows":       rows,
+		"CSVFunc":    FuncCSV,
+		"SearchFunc": FuncSearch,
+		"LogoutFunc": FuncLogout,
+	})
+}
+
+func (s *Site) handleCSV(w http.ResponseWriter, r *http.Request) {
+	sess := s.session(r)
+	if sess == nil {
+		s.renderLogin(w, "")
+		return
+	}
+
+	s.mu.Lock()
+	results := sess.results
+	from, to := sess.from, sess.to
+	s.downloads++
+	s.mu.Unlock()
+
+	data, err := EncodeCSV(results)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("meisai_%s_%s.csv", from.Format("20060102"), to.Format("20060102"))
+	w.Header().Set("Content-Type", "text/csv; charset=Shift_JIS")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Write(data)
+}
+
+// EncodeCSV renders records the way the site does: Shift_JIS with CRLF line endings
+func EncodeCSV(records []Record) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(transform.NewWriter(&buf, japanese.ShiftJIS.NewEncoder()))
+	writer.UseCRLF = true
+
+	rows := [][]string{CSVHeader}
+	for _, record := range records {
+		rows = append(rows, []string{
+			record.EntryAt.Format("06/01/02"), record.EntryAt.Format("15:04"),
+			record.ExitAt.Format("06/01/02"), record.ExitAt.Format("15:04"),
+			record.EntryIC, record.ExitIC,
+			strconv.Itoa(record.BaseFare), strconv.Itoa(record.Discount), strconv.Itoa(record.Fare),
+			record.VehicleClass, record.VehicleNumber, record.CardNumber, record.Note,
+		})
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("failed to encode CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *Site) session(r *http.Request) *session {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[cookie.Value]
+}
+
+func (s *Site) renderLogin(w http.ResponseWriter, message string) {
+	s.render(w, loginPage, map[string]string{"LoginFunc": FuncLogin, "Error": message})
+}
+
+func (s *Site) renderSearch(w http.ResponseWriter, sess *session, message string) {
+	now := s.Now()
+	s.mu.Lock()
+	from, to, sokoKbn := sess.from, sess.to, sess.sokoKbn
+	s.mu.Unlock()
+	if from.IsZero() {
+		from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+		to = now
+	}
+
+	var years, months, days []string
+	for year := now.Year() - 2; year <= now.Year(); year++ {
+		years = append(years, fmt.Sprintf("%04d", year))
+	}
+	for month := 1; month <= 12; month++ {
+		months = append(months, fmt.Sprintf("%02d", month))
+	}
+	for day := 1; day <= 31; day++ {
+		days = append(days, fmt.Sprintf("%02d", day))
+	}
+
+	s.render(w, searchPage, map[string]interface{}{
+		"Message":    message,
+		"SearchFunc": FuncSearch,
+		"LogoutFunc": FuncLogout,
+		"SokoKbn":    sokoKbn,
+		"Selects": map[string]selectField{
+			"fromYYYY": newSelectField("fromYYYY", years, fmt.Sprintf("%04d", from.Year())),
+			"fromMM":   newSelectField("fromMM", months, fmt.Sprintf("%02d", int(from.Month()))),
+			"fromDD":   newSelectField("fromDD", days, fmt.Sprintf("%02d", from.Day())),
+			"toYYYY":   newSelectField("toYYYY", years, fmt.Sprintf("%04d", to.Year())),
+			"toMM":     newSelectField("toMM", months, fmt.Sprintf("%02d", int(to.Month()))),
+			"toDD":     newSelectField("toDD", days, fmt.Sprintf("%02d", to.Day())),
+		},
+	})
+}
+
+type selectOption struct {
+	Value    string
+	Selected bool
+}
+
+type selectField struct {
+	Name    string
+	Options []selectOption
+}
+
+func newSelectField(name string, values []string, selected string) selectField {
+	field := selectField{Name: name}
+	for _, value := range values {
+		field.Options = append(field.Options, selectOption{Value: value, Selected: value == selected})
+	}
+	return field
+}
+
+// formValue returns a posted value, decoded from Shift_JIS when the pages are served in it
+func (s *Site) formValue(r *http.Request, name string) string {
+	value := r.FormValue(name)
+	s.mu.Lock()
+	shiftJIS := s.shiftJIS
+	s.mu.Unlock()
+	if !shiftJIS {
+		return value
+	}
+	decoded, err := japanese.ShiftJIS.NewDecoder().String(value)
+	if err != nil {
+		return ""
+	}
+	return decoded
+}
+
+// formDate reads the <prefix>YYYY / <prefix>MM / <prefix>DD selectors
+func formDate(r *http.Request, prefix string) (time.Time, error) {
+	value := r.FormValue(prefix+"YYYY") + "-" + r.FormValue(prefix+"MM") + "-" + r.FormValue(prefix+"DD")
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,131 @@
+package fakesite
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+const pageHeader = `<!DOCTYPE html>
+<html lang="ja">
+<head><meta charset="UTF-8"><title>ETC利用照会サービス</title></head>
+<body>
+`
+
+const pageFooter = `
+</body>
+</html>
+`
+
+var topPage = template.Must(template.New("top").Parse(pageHeader + `
+<h1>ETC利用照会サービス</h1>
+<ul>
+  <li><a href="/etc/R?funccode={{.LoginFunc}}&nextfunc={{.LoginFunc}}">ログイン</a></li>
+</ul>
+` + pageFooter))
+
+var loginPage = template.Must(template.New("login").Parse(pageHeader + `
+<h1>ログイン</h1>
+{{if .Error}}<div class="error-message">{{.Error}}</div>{{end}}
+<form name="loginForm" method="post" action="/etc/R?funccode={{.LoginFunc}}">
+  <label>ログインID <input type="text" name="risLoginId"></label>
+  <label>パスワード <input type="password" name="risPassword"></label>
+  <input type="button" value="ログイン" onclick="document.loginForm.submit()">
+</form>
+` + pageFooter))
+
+var searchPage = template.Must(template.New("search").Parse(pageHeader + `{{define "select"}}<select name="{{.Name}}">{{range .Options}}<option value="{{.Value}}"{{if .Selected}} selected{{end}}>{{.Value}}</option>{{end}}</select>{{end}}
+<div class="menu">
+  <a href="/etc/R?funccode={{.SearchFunc}}">検索条件の指定</a>
+  <a href="/etc/R?funccode={{.LogoutFunc}}">ログアウト</a>
+</div>
+<h1>検索条件の指定</h1>
+{{if .Message}}<div class="message">{{.Message}}</div>{{end}}
+<form name="searchForm" method="post" action="/etc/R?funccode={{.SearchFunc}}">
+  <fieldset>
+    <legend>走行区分</legend>
+    <label><input type="radio" name="sokoKbn" value="0"{{if or (eq .SokoKbn "0") (eq .SokoKbn "")}} checked{{end}}>全て</label>
+    <label><input type="radio" name="sokoKbn" value="1"{{if eq .SokoKbn "1"}} checked{{end}}>高速道路</label>
+    <label><input type="radio" name="sokoKbn" value="2"{{if eq .SokoKbn "2"}} checked{{end}}>駐車場等</label>
+  </fieldset>
+  <fieldset>
+    <legend>利用年月日</legend>
+    {{template "select" .Selects.fromYYYY}}年
+    {{template "select" .Selects.fromMM}}月
+    {{template "select" .Selects.fromDD}}日 ～
+    {{template "select" .Selects.toYYYY}}年
+    {{template "select" .Selects.toMM}}月
+    {{template "select" .Selects.toDD}}日
+  </fieldset>
+  <input type="submit" name="focusTarget_Save" value="この条件を記憶する">
+  <input type="submit" name="focusTarget" value="検索">
+</form>
+` + pageFooter))
+
+var resultPage = template.Must(template.New("result").Parse(pageHeader + `
+<div class="menu">
+  <a href="/etc/R?funccode={{.SearchFunc}}">検索条件の指定</a>
+  <a href="/etc/R?funccode={{.LogoutFunc}}">ログアウト</a>
+</div>
+<h1>利用明細</h1>
+<p>利用年月日: <span id="searchPeriod">{{.Period}}</span></p>
+{{if .Rows}}
+<script>
+function goOutput(funccode, target) {
+  if (confirm('明細ＣＳＶを出力します。よろしいですか？')) {
+    var form = document.forms['meisaiForm'];
+    form.action = '/etc/R?funccode=' + funccode;
+    form.submit();
+  }
+}
+</script>
+<form name="meisaiForm" method="post" action="/etc/R?funccode={{.SearchFunc}}">
+<table>
+  <tr><th></th><th>利用日時</th><th>入口</th><th>出口</th><th>通行料金</th></tr>
+  {{range .Rows}}
+  <tr>
+    <td><input type="checkbox" name="hakkoMeisai" value="{{.Index}}" checked></td>
+    <td>{{.ExitAt}}</td><td>{{.EntryIC}}</td><td>{{.ExitIC}}</td><td>{{.Fare}}</td>
+  </tr>
+  {{end}}
+</table>
+</form>
+<a href="javascript:void(0)" onclick="goOutput('{{.CSVFunc}}', 'hakkoMeisai')">明細ＣＳＶ</a>
+{{else}}
+<p class="no-result">該当する利用明細はありません。</p>
+{{end}}
+` + pageFooter))
+
+var maintenancePage = template.Must(template.New("maintenance").Parse(pageHeader + `
+<h1>システムメンテナンスのお知らせ</h1>
+<p>` + MessageMaintenance + `</p>
+` + pageFooter))
+
+// render writes a page in the site's charset, reporting template and encoding errors as 500
+func (s *Site) render(w http.ResponseWriter, page *template.Template, data interface{}) {
+	var buf bytes.Buffer
+	if err := page.Execute(&buf, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.Lock()
+	shiftJIS := s.shiftJIS
+	s.mu.Unlock()
+	if !shiftJIS {
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		w.Write(buf.Bytes())
+		return
+	}
+
+	body := bytes.Replace(buf.Bytes(), []byte(`<meta charset="UTF-8">`), []byte(`<meta charset="Shift_JIS">`), 1)
+	encoded, err := japanese.ShiftJIS.NewEncoder().Bytes(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=Shift_JIS")
+	w.Write(encoded)
+}
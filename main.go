@@ -1,16 +1,22 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"database/sql"
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/yhonda-ohishi/etc_meisai_scraper/src/grpc"
 	"github.com/yhonda-ohishi/etc_meisai_scraper/src/handlers"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
 	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
 )
 
@@ -21,6 +27,12 @@ func main() {
 		grpcPort   = flag.String("grpc-port", "50052", "gRPC server port for etc_meisai_scraper")
 		httpPort   = flag.String("http-port", "8080", "HTTP server port (legacy mode)")
 		showHelp   = flag.Bool("help", false, "Show help message")
+
+		validateSelectors = flag.String("validate-selectors", "", "Report which selector pack elements resolve on the given page URL and exit")
+		selectorPack      = flag.String("selector-pack", os.Getenv("ETC_SELECTOR_PACK"), "Selector pack JSON file (default: built-in pack)")
+
+		vaultCommand = flag.String("vault", "", "Manage the credential vault (ETC_VAULT_PATH) and exit: add, rotate, remove or list")
+		vaultAccount = flag.String("account", "", "Account ID for --vault add, rotate and remove (the password is read from stdin)")
 	)
 	flag.Parse()
 
@@ -32,6 +44,16 @@ func main() {
 	// ロガー設定
 	logger := log.New(os.Stdout, "[ETC-MEISAI] ", log.LstdFlags)
 
+	if *validateSelectors != "" {
+		runValidateSelectors(logger, *validateSelectors, *selectorPack)
+		return
+	}
+
+	if *vaultCommand != "" {
+		runVault(logger, *vaultCommand, *vaultAccount)
+		return
+	}
+
 	// DB接続は不要（スクレイピング専用サービス）
 	var db *sql.DB
 
@@ -65,6 +87,12 @@ func printHelp() {
 	log.Println("  # Start as HTTP server (legacy)")
 	log.Println("  etc_meisai_scraper.exe --grpc=false --http-port 8080")
 	log.Println()
+	log.Println("  # Check a selector pack against a page (e.g. page.html of a failure evidence bundle)")
+	log.Println("  etc_meisai_scraper.exe --validate-selectors file:///path/to/page.html --selector-pack selectors.json")
+	log.Println()
+	log.Println("  # Store an account password in the credential vault (the password is read from stdin)")
+	log.Println("  etc_meisai_scraper.exe --vault add --account corp1")
+	log.Println()
 	log.Println("Integration with desktop-server:")
 	log.Println("  This service is designed to run as a separate process and be called")
 	log.Println("  by desktop-server via gRPC. See README.md for integration details.")
@@ -91,6 +119,19 @@ func runHTTPServer(db *sql.DB, logger *log.Logger, port string) {
 	// ダウンロードサービス初期化
 	downloadService := services.NewDownloadService(db, logger)
 
+	// シグナルハンドリング（実行中のジョブをキャンセルしてブラウザを閉じる）
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := downloadService.Shutdown(ctx); err != nil {
+			logger.Printf("Failed to stop download jobs: %v", err)
+		}
+		os.Exit(0)
+	}()
+
 	// ハンドラー初期化
 	downloadHandler := handlers.NewDownloadHandler(downloadService)
 
@@ -98,6 +139,8 @@ func runHTTPServer(db *sql.DB, logger *log.Logger, port string) {
 	http.HandleFunc("/api/download/sync", downloadHandler.DownloadSync)
 	http.HandleFunc("/api/download/async", downloadHandler.DownloadAsync)
 	http.HandleFunc("/api/download/status", downloadHandler.GetDownloadStatus)
+	http.HandleFunc("/api/download/cancel", downloadHandler.CancelDownload)
+	http.HandleFunc("/api/download/jobs", downloadHandler.ListDownloads)
 
 	logger.Printf("Starting HTTP server on port %s", port)
 	logger.Printf("GitHub repository: https://github.com/yhonda-ohishi/etc_meisai_scraper")
@@ -105,8 +148,99 @@ func runHTTPServer(db *sql.DB, logger *log.Logger, port string) {
 	logger.Printf("  POST /api/download/sync  - 同期ダウンロード")
 	logger.Printf("  POST /api/download/async - 非同期ダウンロード")
 	logger.Printf("  GET  /api/download/status?job_id={id} - ステータス確認")
+	logger.Printf("  POST /api/download/cancel?job_id={id} - ジョブのキャンセル")
+	logger.Printf("  GET  /api/download/jobs?status=&account_id=&page_token= - ジョブ一覧")
 
 	if err := http.ListenAndServe(":"+port, nil); err != nil {
 		logger.Fatalf("HTTP server failed to start: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+// runValidateSelectors はセレクタパックの各要素が指定ページで見つかるかを表示する
+func runValidateSelectors(logger *log.Logger, url, packPath string) {
+	pack := scraper.DefaultSelectorPack()
+	if packPath != "" {
+		loaded, err := scraper.LoadSelectorPack(packPath)
+		if err != nil {
+			logger.Fatalf("Failed to load selector pack: %v", err)
+		}
+		pack = loaded
+	}
+
+	s, err := scraper.NewETCScraper(&scraper.ScraperConfig{
+		Headless:  services.GetHeadlessMode(),
+		Selectors: pack,
+	}, logger)
+	if err != nil {
+		logger.Fatalf("Failed to create scraper: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Initialize(); err != nil {
+		logger.Fatalf("Failed to start browser: %v", err)
+	}
+	checks, err := s.ValidateSelectors(url)
+	if err != nil {
+		logger.Fatalf("Failed to validate selectors: %v", err)
+	}
+
+	resolved := 0
+	fmt.Printf("Selector pack %s on %s\n", pack.Version, url)
+	for _, check := range checks {
+		if check.Resolved {
+			resolved++
+			fmt.Printf("  ✅ %-22s %s (%d)\n", check.Element, check.Selector, check.Count)
+		} else {
+			fmt.Printf("  ❌ %-22s not found\n", check.Element)
+		}
+	}
+	fmt.Printf("%d/%d elements resolved\n", resolved, len(checks))
+}
+
+// runVault は資格情報の保管庫のパスワードを追加・変更・削除する
+// パスワードはコマンド履歴に残らないよう標準入力から1行読み込む
+func runVault(logger *log.Logger, command, accountID string) {
+	vault, err := services.OpenVaultFromEnv()
+	if err != nil {
+		logger.Fatalf("Failed to open credential vault: %v", err)
+	}
+
+	if command == "list" {
+		accountIDs, err := vault.AccountIDs()
+		if err != nil {
+			logger.Fatalf("Failed to read credential vault: %v", err)
+		}
+		for _, accountID := range accountIDs {
+			fmt.Println(accountID)
+		}
+		return
+	}
+
+	if accountID == "" {
+		logger.Fatalf("--account is required for --vault %s", command)
+	}
+	switch command {
+	case "add", "rotate":
+		fmt.Fprintf(os.Stderr, "Password for %s: ", accountID)
+		password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && password == "" {
+			logger.Fatalf("Failed to read password: %v", err)
+		}
+		password = strings.TrimRight(password, "\r\n")
+		if command == "add" {
+			err = vault.Add(accountID, password)
+		} else {
+			err = vault.Rotate(accountID, password)
+		}
+		if err != nil {
+			logger.Fatalf("Failed to store password: %v", err)
+		}
+	case "remove":
+		if err := vault.Remove(accountID); err != nil {
+			logger.Fatalf("Failed to remove password: %v", err)
+		}
+	default:
+		logger.Fatalf("Unknown vault command %q (use add, rotate, remove or list)", command)
+	}
+	logger.Printf("Vault %s: %s done", command, accountID)
+}
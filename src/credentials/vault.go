@@ -0,0 +1,310 @@
+// Package credentials keeps ETC account passwords in a vault file encrypted with AES-256-GCM.
+package credentials
+
+import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/cryptoutil"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+)
+
+// PassphraseIterations is the PBKDF2-SHA256 iteration count for new passphrase-protected vaults
+const PassphraseIterations = 600000
+
+const (
+	vaultVersion = 1
+	kdfPBKDF2    = "pbkdf2-sha256"
+	saltSize     = 16
+)
+
+var (
+	// ErrNotFound is returned when the vault holds no password for the account.
+	// It wraps scraper.ErrSecretNotFound so callers can fall back to another provider.
+	ErrNotFound = fmt.Errorf("credential not found: %w", scraper.ErrSecretNotFound)
+	// ErrExists is returned by Add for an account that already has a password
+	ErrExists = errors.New("credential already exists")
+	// ErrWrongKey is returned when the vault was sealed with a different key or passphrase
+	ErrWrongKey = errors.New("vault key or passphrase does not match")
+)
+
+// vaultFile is the on-disk format. Only the secrets are encrypted; the KDF parameters
+// are stored in clear so the key can be derived again from the passphrase.
+type vaultFile struct {
+	Version int        `json:"version"`
+	KDF     *kdfParams `json:"kdf,omitempty"` // Set for passphrase-protected vaults
+	Data    []byte     `json:"data"`          // cryptoutil.Seal of the JSON encoded secrets
+}
+
+type kdfParams struct {
+	Algorithm  string `json:"algorithm"`
+	Salt       []byte `json:"salt"`
+	Iterations int    `json:"iterations"`
+}
+
+// Vault stores account passwords in an encrypted file.
+// The file is decrypted on every lookup so plaintext passwords are not kept in memory,
+// and changes made by another process (e.g. the vault command) take effect immediately.
+type Vault struct {
+	mu   sync.Mutex
+	path string
+	key  []byte
+	kdf  *kdfParams
+}
+
+// Open opens the vault at path with a 32-byte key. A missing file is created on the first Add.
+func Open(path string, key []byte) (*Vault, error) {
+	if len(key) != cryptoutil.KeySize {
+		return nil, cryptoutil.ErrInvalidKey
+	}
+	file, err := readVaultFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if file != nil && file.KDF != nil {
+		return nil, fmt.Errorf("vault %s is protected by a passphrase", path)
+	}
+
+	v := &Vault{path: path, key: key}
+	if file != nil {
+		if _, err := v.decrypt(file); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// OpenWithKeyFile opens the vault at path with the base64 or hex encoded key stored in keyPath
+func OpenWithKeyFile(path, keyPath string) (*Vault, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault key file: %w", err)
+	}
+	key, err := cryptoutil.ParseKey(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault key file %s: %w", keyPath, err)
+	}
+	return Open(path, key)
+}
+
+// OpenWithPassphrase opens the vault at path with a key derived from passphrase.
+// A new vault gets a random salt; an existing one reuses the salt and iteration count in its file.
+func OpenWithPassphrase(path, passphrase string) (*Vault, error) {
+	if passphrase == "" {
+		return nil, errors.New("vault passphrase must not be empty")
+	}
+	file, err := readVaultFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var kdf *kdfParams
+	switch {
+	case file == nil:
+		salt := make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+		kdf = &kdfParams{Algorithm: kdfPBKDF2, Salt: salt, Iterations: PassphraseIterations}
+	case file.KDF == nil:
+		return nil, fmt.Errorf("vault %s is protected by a key file", path)
+	case file.KDF.Algorithm != kdfPBKDF2 || file.KDF.Iterations <= 0 || len(file.KDF.Salt) == 0:
+		return nil, fmt.Errorf("vault %s has unsupported key derivation %q", path, file.KDF.Algorithm)
+	default:
+		kdf = file.KDF
+	}
+
+	key, err := pbkdf2.Key(sha256.New, passphrase, kdf.Salt, kdf.Iterations, cryptoutil.KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive vault key: %w", err)
+	}
+	v := &Vault{path: path, key: key, kdf: kdf}
+	if file != nil {
+		if _, err := v.decrypt(file); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// Password returns the password of accountID. It implements scraper.SecretProvider.
+func (v *Vault) Password(ctx context.Context, accountID string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	secrets, err := v.load()
+	if err != nil {
+		return "", err
+	}
+	password, ok := secrets[accountID]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return password, nil
+}
+
+// AccountIDs returns the accounts that have a password in the vault, sorted
+func (v *Vault) AccountIDs() ([]string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	secrets, err := v.load()
+	if err != nil {
+		return nil, err
+	}
+	accountIDs := make([]string, 0, len(secrets))
+	for accountID := range secrets {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Strings(accountIDs)
+	return accountIDs, nil
+}
+
+// Add stores the password of a new account
+func (v *Vault) Add(accountID, password string) error {
+	return v.update(accountID, func(secrets map[string]string) error {
+		if _, exists := secrets[accountID]; exists {
+			return ErrExists
+		}
+		if password == "" {
+			return errors.New("password must not be empty")
+		}
+		secrets[accountID] = password
+		return nil
+	})
+}
+
+// Rotate replaces the password of an account already in the vault
+func (v *Vault) Rotate(accountID, password string) error {
+	return v.update(accountID, func(secrets map[string]string) error {
+		if _, exists := secrets[accountID]; !exists {
+			return ErrNotFound
+		}
+		if password == "" {
+			return errors.New("password must not be empty")
+		}
+		secrets[accountID] = password
+		return nil
+	})
+}
+
+// Remove deletes the password of an account
+func (v *Vault) Remove(accountID string) error {
+	return v.update(accountID, func(secrets map[string]string) error {
+		if _, exists := secrets[accountID]; !exists {
+			return ErrNotFound
+		}
+		delete(secrets, accountID)
+		return nil
+	})
+}
+
+// update applies change to the secrets and writes the vault back
+func (v *Vault) update(accountID string, change func(secrets map[string]string) error) error {
+	if accountID == "" || strings.ContainsAny(accountID, ":,") {
+		return fmt.Errorf("invalid account ID %q", accountID)
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	secrets, err := v.load()
+	if err != nil {
+		return err
+	}
+	if err := change(secrets); err != nil {
+		return fmt.Errorf("account %s: %w", accountID, err)
+	}
+	return v.save(secrets)
+}
+
+// load reads and decrypts the secrets. A missing file is an empty vault.
+func (v *Vault) load() (map[string]string, error) {
+	file, err := readVaultFile(v.path)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return map[string]string{}, nil
+	}
+	return v.decrypt(file)
+}
+
+func (v *Vault) decrypt(file *vaultFile) (map[string]string, error) {
+	plaintext, err := cryptoutil.Open(v.key, file.Data)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("invalid vault %s: %w", v.path, err)
+	}
+	return secrets, nil
+}
+
+// save encrypts the secrets and replaces the vault file atomically
+func (v *Vault) save(secrets map[string]string) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	sealed, err := cryptoutil.Seal(v.key, plaintext)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(vaultFile{Version: vaultVersion, KDF: v.kdf, Data: sealed}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(v.path), 0700); err != nil {
+		return fmt.Errorf("failed to create vault directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(v.path), filepath.Base(v.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), v.path); err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	return nil
+}
+
+// readVaultFile reads the vault file at path, returning nil if it does not exist
+func readVaultFile(path string) (*vaultFile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault: %w", err)
+	}
+	var file vaultFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid vault %s: %w", path, err)
+	}
+	if file.Version != vaultVersion {
+		return nil, fmt.Errorf("vault %s has unsupported version %d", path, file.Version)
+	}
+	return &file, nil
+}
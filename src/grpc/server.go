@@ -1,10 +1,12 @@
 package grpc
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	pb "github.com/yhonda-ohishi/etc_meisai_scraper/src/pb"
 	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
@@ -12,6 +14,9 @@ import (
 	"google.golang.org/grpc/reflection"
 )
 
+// shutdownTimeout は停止時に実行中のジョブの終了を待つ最大時間
+const shutdownTimeout = 30 * time.Second
+
 // Server はgRPCサーバー
 type Server struct {
 	grpcServer      *grpc.Server
@@ -27,10 +32,14 @@ func NewServerWithListener(db *sql.DB, logger *log.Logger, listener NetListener)
 	}
 
 	grpcServer := grpc.NewServer()
-	downloadService := services.NewDownloadServiceGRPC(db, logger)
+	// 各サービスでブラウザ、リクエスト間隔の制限、アカウントを共有する
+	service := services.NewDownloadService(db, logger)
+	downloadService := services.NewDownloadServiceGRPCWithService(service)
 
 	// サービスを登録
 	pb.RegisterDownloadServiceServer(grpcServer, downloadService)
+	pb.RegisterDownloadBufferServiceServer(grpcServer, services.NewDownloadBufferServiceGRPC(service))
+	pb.RegisterAccountServiceServer(grpcServer, services.NewAccountServiceGRPC(service))
 
 	// リフレクションを有効化（開発用）
 	reflection.Register(grpcServer)
@@ -69,7 +78,19 @@ func (s *Server) Start(port string) error {
 	s.logger.Printf("    * DownloadSync")
 	s.logger.Printf("    * DownloadAsync")
 	s.logger.Printf("    * GetJobStatus")
+	s.logger.Printf("    * CancelJob")
+	s.logger.Printf("    * ListJobs")
 	s.logger.Printf("    * GetAllAccountIDs")
+	s.logger.Printf("  - DownloadBufferService")
+	s.logger.Printf("    * DownloadAsBuffer")
+	s.logger.Printf("    * DownloadStream")
+	s.logger.Printf("    * DownloadAsProto")
+	s.logger.Printf("  - AccountService")
+	s.logger.Printf("    * ListAccounts")
+	s.logger.Printf("    * AddAccount")
+	s.logger.Printf("    * UpdateAccount")
+	s.logger.Printf("    * DisableAccount")
+	s.logger.Printf("    * ValidateAccount")
 
 	return s.grpcServer.Serve(lis)
 }
@@ -77,5 +98,13 @@ func (s *Server) Start(port string) error {
 // Stop はgRPCサーバーを停止
 func (s *Server) Stop() {
 	s.logger.Println("Stopping gRPC server...")
+
+	// 実行中のジョブをキャンセルしてブラウザを閉じる
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := s.downloadService.Shutdown(ctx); err != nil {
+		s.logger.Printf("Failed to stop download jobs: %v", err)
+	}
+
 	s.grpcServer.GracefulStop()
 }
\ No newline at end of file
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,12 +28,38 @@ type DownloadRequest struct {
 
 // JobStatus はジョブステータス
 type JobStatus struct {
-	JobID        string     `json:"job_id"`
-	Status       string     `json:"status"`
-	Progress     int        `json:"progress"`
-	TotalRecords int        `json:"total_records"`
-	ErrorMessage *string    `json:"error_message,omitempty"`
-	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	JobID           string            `json:"job_id"`
+	Status          string            `json:"status"`
+	Progress        int               `json:"progress"`
+	TotalRecords    int               `json:"total_records"`
+	RejectedRows    int               `json:"rejected_rows"`
+	BytesDownloaded int64             `json:"bytes_downloaded"`
+	RetryCount      int               `json:"retry_count"`
+	ErrorCode       string            `json:"error_code,omitempty"`
+	ErrorDetails    map[string]string `json:"error_details,omitempty"`
+	ErrorMessage    *string           `json:"error_message,omitempty"`
+	StartedAt       time.Time         `json:"started_at"`
+	CompletedAt     *time.Time        `json:"completed_at,omitempty"`
+	CancelledAt     *time.Time        `json:"cancelled_at,omitempty"`
+	Accounts        []AccountStatus   `json:"accounts"`
+}
+
+// AccountStatus はジョブ内の1アカウントの結果
+type AccountStatus struct {
+	services.AccountResult
+	DurationMs int64 `json:"duration_ms"` // 処理時間（処理中の場合は開始からの経過時間）
+}
+
+// JobSummary はジョブ一覧の1件（ステータスとアカウントごとの結果の集計）
+type JobSummary struct {
+	JobStatus
+	AccountStatusCounts map[string]int `json:"account_status_counts"`
+}
+
+// JobList はジョブ一覧のレスポンス
+type JobList struct {
+	Jobs          []JobSummary `json:"jobs"`
+	NextPageToken string       `json:"next_page_token,omitempty"`
 }
 
 // NewDownloadHandler creates a new download handler
@@ -65,17 +93,21 @@ func (h *DownloadHandler) DownloadSync(w http.ResponseWriter, r *http.Request) {
 		h.respondError(w, http.StatusBadRequest, "At least one account is required")
 		return
 	}
+	accounts, err := h.DownloadService.ResolveAccounts(req.Accounts)
+	if err != nil {
+		h.respondAccountError(w, err)
+		return
+	}
 
-	// TODO: 実際のダウンロード処理を実装
-	response := map[string]interface{}{
-		"success":      true,
-		"record_count": 0,
-		"csv_path":     "",
-		"records":      []interface{}{},
-		"message":      "Download completed successfully",
+	// リクエストの期限内でダウンロードと解析を実行（クライアントの切断で中断）
+	result, err := h.DownloadService.DownloadSync(r.Context(), accounts, req.FromDate, req.ToDate)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	h.respondJSON(w, http.StatusOK, response)
+	// アカウントごとの失敗は error フィールドで返す
+	h.respondJSON(w, http.StatusOK, result)
 }
 
 // DownloadAsync は非同期ダウンロードを開始
@@ -98,20 +130,24 @@ func (h *DownloadHandler) DownloadAsync(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	if len(req.Accounts) == 0 {
-		// デフォルトで全アカウントを使用
-		req.Accounts = h.DownloadService.GetAllAccountIDs()
-		if len(req.Accounts) == 0 {
-			h.respondError(w, http.StatusBadRequest, "No accounts configured")
-			return
-		}
+	// 期間がサイトで検索可能か事前に検証
+	if _, err := services.PlanDateRanges(req.FromDate, req.ToDate); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// アカウントIDをサーバー側の認証情報で解決（省略時は全アカウント）
+	accounts, err := h.DownloadService.ResolveAccounts(req.Accounts)
+	if err != nil {
+		h.respondAccountError(w, err)
+		return
 	}
 
 	// ジョブIDを生成
 	jobID := uuid.New().String()
 
 	// 非同期でダウンロード開始
-	h.DownloadService.ProcessAsync(jobID, req.Accounts, req.FromDate, req.ToDate)
+	h.DownloadService.ProcessAsync(jobID, accounts, req.FromDate, req.ToDate)
 
 	response := map[string]interface{}{
 		"job_id":  jobID,
@@ -124,17 +160,8 @@ func (h *DownloadHandler) DownloadAsync(w http.ResponseWriter, r *http.Request)
 
 // GetDownloadStatus はダウンロードステータスを取得
 func (h *DownloadHandler) GetDownloadStatus(w http.ResponseWriter, r *http.Request) {
-	jobID := r.URL.Query().Get("job_id")
+	jobID := requestJobID(r, "status")
 	if jobID == "" {
-		// URLパスから取得を試みる
-		// 例: /api/download/status/{jobId}
-		parts := strings.Split(r.URL.Path, "/")
-		if len(parts) > 0 {
-			jobID = parts[len(parts)-1]
-		}
-	}
-
-	if jobID == "" || jobID == "status" {
 		h.respondError(w, http.StatusBadRequest, "Job ID is required")
 		return
 	}
@@ -146,19 +173,159 @@ func (h *DownloadHandler) GetDownloadStatus(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	h.respondJSON(w, http.StatusOK, newJobStatus(job))
+}
+
+// ListDownloads はジョブの一覧を開始日時順に返す
+// 例: GET /api/download/jobs?status=failed,cancelled&account_id=acc1&started_after=2025-01-01&page_size=20
+// started_after / started_before はRFC3339またはYYYY-MM-DD（ローカル時刻の0時）、order_by は "started_at desc"（既定）または "started_at"
+func (h *DownloadHandler) ListDownloads(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		h.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := services.JobFilter{
+		AccountID: query.Get("account_id"),
+		PageToken: query.Get("page_token"),
+	}
+	for _, value := range query["status"] {
+		for _, status := range strings.Split(value, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				filter.Statuses = append(filter.Statuses, status)
+			}
+		}
+	}
+
+	var err error
+	if filter.StartedAfter, err = parseQueryTime(query.Get("started_after")); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid started_after: "+err.Error())
+		return
+	}
+	if filter.StartedBefore, err = parseQueryTime(query.Get("started_before")); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid started_before: "+err.Error())
+		return
+	}
+	if value := query.Get("page_size"); value != "" {
+		if filter.PageSize, err = strconv.Atoi(value); err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid page_size")
+			return
+		}
+	}
+	if filter.Ascending, err = services.ParseJobOrder(query.Get("order_by")); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.DownloadService.ListJobs(filter)
+	switch {
+	case errors.Is(err, services.ErrInvalidJobFilter), errors.Is(err, services.ErrInvalidPageToken):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		h.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := JobList{
+		Jobs:          make([]JobSummary, 0, len(page.Jobs)),
+		NextPageToken: page.NextPageToken,
+	}
+	for _, job := range page.Jobs {
+		response.Jobs = append(response.Jobs, JobSummary{
+			JobStatus:           newJobStatus(job),
+			AccountStatusCounts: job.AccountStatusCounts(),
+		})
+	}
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// parseQueryTime はRFC3339またはYYYY-MM-DDの日時を解析する（空の場合はゼロ値）
+func parseQueryTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+// CancelDownload は実行中のジョブをキャンセルし、キャンセル後のステータスを返す
+// 例: POST /api/download/cancel?job_id={jobId}
+func (h *DownloadHandler) CancelDownload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		h.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	jobID := requestJobID(r, "cancel")
+	if jobID == "" {
+		h.respondError(w, http.StatusBadRequest, "Job ID is required")
+		return
+	}
+
+	job, err := h.DownloadService.CancelJob(r.Context(), jobID)
+	switch {
+	case errors.Is(err, services.ErrJobNotFound):
+		h.respondError(w, http.StatusNotFound, fmt.Sprintf("Job %s not found", jobID))
+		return
+	case errors.Is(err, services.ErrJobAlreadyFinished):
+		h.respondError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		h.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, newJobStatus(job))
+}
+
+// requestJobID はクエリの job_id、なければURLパスの最後の要素（例: /api/download/status/{jobId}）を返す
+// パスの最後がエンドポイント名 endpoint の場合は空文字を返す
+func requestJobID(r *http.Request, endpoint string) string {
+	if jobID := r.URL.Query().Get("job_id"); jobID != "" {
+		return jobID
+	}
+	parts := strings.Split(r.URL.Path, "/")
+	if jobID := parts[len(parts)-1]; jobID != endpoint {
+		return jobID
+	}
+	return ""
+}
+
+// newJobStatus はジョブの状態をレスポンスに変換
+func newJobStatus(job *services.DownloadJob) JobStatus {
 	status := JobStatus{
-		JobID:        job.ID,
-		Status:       job.Status,
-		Progress:     job.Progress,
-		TotalRecords: job.TotalRecords,
-		CompletedAt:  job.CompletedAt,
+		JobID:           job.ID,
+		Status:          job.Status,
+		Progress:        job.Progress,
+		TotalRecords:    job.TotalRecords,
+		RejectedRows:    job.RejectedRows,
+		BytesDownloaded: job.BytesDownloaded,
+		RetryCount:      job.RetryCount,
+		ErrorCode:       job.ErrorCode,
+		ErrorDetails:    job.ErrorDetails,
+		StartedAt:       job.StartedAt,
+		CompletedAt:     job.CompletedAt,
+		CancelledAt:     job.CancelledAt,
+		Accounts:        make([]AccountStatus, 0, len(job.Accounts)),
 	}
 
 	if job.ErrorMessage != "" {
 		status.ErrorMessage = &job.ErrorMessage
 	}
+	for _, account := range job.Accounts {
+		status.Accounts = append(status.Accounts, AccountStatus{
+			AccountResult: account,
+			DurationMs:    account.Duration().Milliseconds(),
+		})
+	}
 
-	h.respondJSON(w, http.StatusOK, status)
+	return status
 }
 
 // Helper methods
@@ -169,6 +336,25 @@ func (h *DownloadHandler) respondJSON(w http.ResponseWriter, code int, payload i
 	w.Write(response)
 }
 
+// respondAccountError はアカウントの解決に失敗した理由を返す
+func (h *DownloadHandler) respondAccountError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrNoAccountsConfigured):
+		h.respondError(w, http.StatusBadRequest, "No accounts configured")
+	case errors.Is(err, services.ErrUnknownAccount):
+		var accountErr *services.AccountError
+		if errors.As(err, &accountErr) {
+			h.respondError(w, http.StatusNotFound, fmt.Sprintf("Account %s not found", accountErr.AccountID))
+			return
+		}
+		h.respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrAccountDisabled):
+		h.respondError(w, http.StatusConflict, err.Error())
+	default:
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
 func (h *DownloadHandler) respondError(w http.ResponseWriter, code int, message string) {
 	h.respondJSON(w, code, map[string]string{"error": message})
 }
\ No newline at end of file
@@ -2,24 +2,47 @@ package models
 
 import "time"
 
+// アカウントの種別
+const (
+	AccountTypeCorporate = "corporate" // 法人
+	AccountTypePersonal  = "personal"  // 個人
+)
+
 // ETCAccount はETCアカウント情報
 type ETCAccount struct {
-	ID       string
-	Username string
-	Password string
-	Type     string // "corporate" or "personal"
+	ID          string
+	Username    string
+	Password    string
+	Type        string // "corporate" or "personal"
+	DisplayName string
+	Tags        []string
+	Enabled     bool   // false の場合は全アカウントのダウンロードに含めず、指定されてもダウンロードしない
+	DownloadDir string // アカウントごとのダウンロード先（空の場合は既定の ./downloads）
+	Scraper     ScraperOverrides
+}
+
+// ScraperOverrides はアカウントごとに既定値から変更するスクレイパーの設定
+// ゼロ値（nil）の項目は既定値のまま
+type ScraperOverrides struct {
+	Timeout  time.Duration // ページ操作のタイムアウト
+	Headless *bool         // ETC_HEADLESS の代わりに使う
 }
 
 // ETCMeisaiRecord はETC明細レコード
 type ETCMeisaiRecord struct {
 	ID             int64      `json:"id"`
 	AccountID      string     `json:"account_id"`
-	UsageDate      time.Time  `json:"usage_date"`
+	UsageDate      time.Time  `json:"usage_date"`           // 利用日時（出口）
+	EntryDate      *time.Time `json:"entry_date,omitempty"` // 入口の利用日時（ない明細もある）
 	EntryIC        string     `json:"entry_ic"`
 	ExitIC         string     `json:"exit_ic"`
+	VehicleClass   string     `json:"vehicle_class"`
 	VehicleNumber  string     `json:"vehicle_number"`
 	ETCCardNumber  string     `json:"etc_card_number"`
-	Amount         int        `json:"amount"`
+	BaseAmount     int        `json:"base_amount"`     // 割引前料金
+	DiscountAmount int        `json:"discount_amount"` // ETC割引額
+	Amount         int        `json:"amount"`          // 通行料金（割引後）
+	Remarks        string     `json:"remarks,omitempty"`
 	CSVFileName    string     `json:"csv_file_name"`
 	DownloadedAt   time.Time  `json:"downloaded_at"`
 	CreatedAt      time.Time  `json:"created_at"`
@@ -0,0 +1,294 @@
+// Package parser reads the meisai CSV files downloaded from the ETC meisai service
+package parser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/models"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// Encodings reported in Result.Encoding
+const (
+	EncodingShiftJIS = "Shift_JIS"
+	EncodingUTF8     = "UTF-8"
+)
+
+// Column headers of the meisai CSV
+const (
+	ColumnEntryDate     = "利用年月日（自）"
+	ColumnEntryTime     = "時分（自）"
+	ColumnExitDate      = "利用年月日（至）"
+	ColumnExitTime      = "時分（至）"
+	ColumnEntryIC       = "利用ＩＣ（自）"
+	ColumnExitIC        = "利用ＩＣ（至）"
+	ColumnBaseAmount    = "割引前料金"
+	ColumnDiscount      = "ＥＴＣ割引額"
+	ColumnAmount        = "通行料金"
+	ColumnVehicleClass  = "車種"
+	ColumnVehicleNumber = "車両番号"
+	ColumnCardNumber    = "ＥＴＣカード番号"
+	ColumnRemarks       = "備考"
+)
+
+// Columns lists every column in the order the site writes them
+var Columns = []string{
+	ColumnEntryDate, ColumnEntryTime, ColumnExitDate, ColumnExitTime,
+	ColumnEntryIC, ColumnExitIC, ColumnBaseAmount, ColumnDiscount, ColumnAmount,
+	ColumnVehicleClass, ColumnVehicleNumber, ColumnCardNumber, ColumnRemarks,
+}
+
+// Location is the time zone of the dates and times in the CSV
+var Location = time.FixedZone("JST", 9*60*60)
+
+// ErrMissingHeader is returned when the file does not start with the meisai CSV header
+var ErrMissingHeader = errors.New("meisai CSV header not found")
+
+// dateLayouts are the date formats the site has used (two- and four-digit years)
+var dateLayouts = []string{"06/01/02", "2006/01/02"}
+
+// LineError is a row of the CSV that could not be turned into a record
+type LineError struct {
+	Line   int    // 1-based line number in the file
+	Column string // Offending column, empty if the whole row is malformed
+	Err    error
+}
+
+func (e *LineError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d: %s: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Result is the outcome of parsing one CSV file. Rows with errors are skipped
+// and reported in Errors; the other rows are still returned in Records.
+type Result struct {
+	Records  []models.ETCMeisaiRecord
+	Errors   []*LineError
+	Encoding string
+}
+
+// ParseFile parses the CSV at path and sets CSVFileName on every record
+func ParseFile(path string) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+
+	result, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	for i := range result.Records {
+		result.Records[i].CSVFileName = filepath.Base(path)
+	}
+	return result, nil
+}
+
+// Parse decodes data and maps each row to a record. Only a file that cannot be
+// decoded or has no header fails as a whole; bad rows end up in Result.Errors.
+func Parse(data []byte) (*Result, error) {
+	text, encoding, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(bytes.NewReader(text))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrMissingHeader
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns, err := columnIndexes(header)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Encoding: encoding}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				line = parseErr.StartLine
+				err = parseErr.Err
+			}
+			result.Errors = append(result.Errors, &LineError{Line: line, Err: err})
+			continue
+		}
+		if isBlank(row) {
+			continue
+		}
+
+		record, lineErr := parseRow(row, columns)
+		if lineErr != nil {
+			lineErr.Line = line
+			result.Errors = append(result.Errors, lineErr)
+			continue
+		}
+		result.Records = append(result.Records, record)
+	}
+	return result, nil
+}
+
+// Decode returns data as UTF-8 together with the encoding it was written in.
+// The site writes Shift_JIS (CP932); UTF-8 files, with or without BOM, are passed through.
+func Decode(data []byte) ([]byte, string, error) {
+	if trimmed, ok := bytes.CutPrefix(data, []byte("\xef\xbb\xbf")); ok {
+		return trimmed, EncodingUTF8, nil
+	}
+	if utf8.Valid(data) {
+		return data, EncodingUTF8, nil
+	}
+
+	decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode Shift_JIS: %w", err)
+	}
+	return decoded, EncodingShiftJIS, nil
+}
+
+// columnIndexes maps each known column to its position in header
+func columnIndexes(header []string) (map[string]int, error) {
+	indexes := make(map[string]int, len(header))
+	for i, name := range header {
+		indexes[strings.TrimSpace(name)] = i
+	}
+
+	var missing []string
+	for _, column := range Columns {
+		if _, ok := indexes[column]; !ok {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) == len(Columns) {
+		return nil, ErrMissingHeader
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: missing columns %s", ErrMissingHeader, strings.Join(missing, ", "))
+	}
+	return indexes, nil
+}
+
+// parseRow converts one CSV row. Line is filled in by the caller.
+func parseRow(row []string, columns map[string]int) (models.ETCMeisaiRecord, *LineError) {
+	if len(row) < len(columns) {
+		return models.ETCMeisaiRecord{}, &LineError{Err: fmt.Errorf("expected %d fields, got %d", len(columns), len(row))}
+	}
+	field := func(column string) string {
+		return strings.TrimSpace(row[columns[column]])
+	}
+
+	record := models.ETCMeisaiRecord{
+		EntryIC:       field(ColumnEntryIC),
+		ExitIC:        field(ColumnExitIC),
+		VehicleClass:  field(ColumnVehicleClass),
+		VehicleNumber: field(ColumnVehicleNumber),
+		ETCCardNumber: field(ColumnCardNumber),
+		Remarks:       field(ColumnRemarks),
+	}
+
+	usageDate, err := parseDateTime(field(ColumnExitDate), field(ColumnExitTime))
+	if err != nil {
+		return record, &LineError{Column: ColumnExitDate, Err: err}
+	}
+	record.UsageDate = usageDate
+
+	// Single-gantry tolls have no entry date
+	if date := field(ColumnEntryDate); date != "" {
+		entryDate, err := parseDateTime(date, field(ColumnEntryTime))
+		if err != nil {
+			return record, &LineError{Column: ColumnEntryDate, Err: err}
+		}
+		record.EntryDate = &entryDate
+	}
+
+	amounts := []struct {
+		column   string
+		dest     *int
+		required bool
+	}{
+		{ColumnBaseAmount, &record.BaseAmount, false},
+		{ColumnDiscount, &record.DiscountAmount, false},
+		{ColumnAmount, &record.Amount, true},
+	}
+	for _, amount := range amounts {
+		value, err := parseAmount(field(amount.column), amount.required)
+		if err != nil {
+			return record, &LineError{Column: amount.column, Err: err}
+		}
+		*amount.dest = value
+	}
+	return record, nil
+}
+
+// parseDateTime parses a date like 25/01/15 and an optional time like 09:30 in Location
+func parseDateTime(date, clock string) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, errors.New("date is empty")
+	}
+	for _, layout := range dateLayouts {
+		day, err := time.ParseInLocation(layout, date, Location)
+		if err != nil {
+			continue
+		}
+		if clock == "" {
+			return day, nil
+		}
+		t, err := time.ParseInLocation("15:04", clock, Location)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q", clock)
+		}
+		return day.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", date)
+}
+
+// parseAmount parses a yen amount such as "1,320" or "-100". Empty optional amounts are 0.
+func parseAmount(value string, required bool) (int, error) {
+	value = strings.ReplaceAll(value, ",", "")
+	if value == "" {
+		if required {
+			return 0, errors.New("amount is empty")
+		}
+		return 0, nil
+	}
+	amount, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
+
+func isBlank(row []string) bool {
+	for _, field := range row {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -25,7 +25,7 @@ const (
 // ダウンロードリクエスト
 type DownloadRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Accounts      []string               `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	Accounts      []string               `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"` // アカウントID（パスワードはサーバー側で解決。非同期では省略時に全アカウント）
 	FromDate      string                 `protobuf:"bytes,2,opt,name=from_date,json=fromDate,proto3" json:"from_date,omitempty"`
 	ToDate        string                 `protobuf:"bytes,3,opt,name=to_date,json=toDate,proto3" json:"to_date,omitempty"`
 	Mode          string                 `protobuf:"bytes,4,opt,name=mode,proto3" json:"mode,omitempty"`
@@ -274,23 +274,76 @@ func (x *GetJobStatusRequest) GetJobId() string {
 	return ""
 }
 
-// ジョブステータス
-type JobStatus struct {
+// ジョブキャンセルリクエスト
+type CancelJobRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
-	Progress      int32                  `protobuf:"varint,3,opt,name=progress,proto3" json:"progress,omitempty"`
-	TotalRecords  int32                  `protobuf:"varint,4,opt,name=total_records,json=totalRecords,proto3" json:"total_records,omitempty"`
-	ErrorMessage  string                 `protobuf:"bytes,5,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
-	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
-	CompletedAt   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *CancelJobRequest) Reset() {
+	*x = CancelJobRequest{}
+	mi := &file_download_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelJobRequest) ProtoMessage() {}
+
+func (x *CancelJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_download_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelJobRequest.ProtoReflect.Descriptor instead.
+func (*CancelJobRequest) Descriptor() ([]byte, []int) {
+	return file_download_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CancelJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+// ジョブステータス
+// status は processing, completed, partially_failed（一部のアカウントが失敗）, failed（すべて失敗）, cancelled, interrupted
+type JobStatus struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	JobId           string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Status          string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Progress        int32                  `protobuf:"varint,3,opt,name=progress,proto3" json:"progress,omitempty"`
+	TotalRecords    int32                  `protobuf:"varint,4,opt,name=total_records,json=totalRecords,proto3" json:"total_records,omitempty"` // 解析できた明細の件数（各アカウントの合計）
+	ErrorMessage    string                 `protobuf:"bytes,5,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	StartedAt       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	CompletedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	RetryCount      int32                  `protobuf:"varint,8,opt,name=retry_count,json=retryCount,proto3" json:"retry_count,omitempty"`
+	ErrorCode       string                 `protobuf:"bytes,9,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	ErrorDetails    map[string]string      `protobuf:"bytes,10,rep,name=error_details,json=errorDetails,proto3" json:"error_details,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	CancelledAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=cancelled_at,json=cancelledAt,proto3" json:"cancelled_at,omitempty"`
+	Accounts        []*AccountResult       `protobuf:"bytes,12,rep,name=accounts,proto3" json:"accounts,omitempty"`                                       // アカウントごとの結果
+	RejectedRows    int32                  `protobuf:"varint,13,opt,name=rejected_rows,json=rejectedRows,proto3" json:"rejected_rows,omitempty"`          // 検証で除外した行数（各アカウントの合計）
+	BytesDownloaded int64                  `protobuf:"varint,14,opt,name=bytes_downloaded,json=bytesDownloaded,proto3" json:"bytes_downloaded,omitempty"` // ダウンロードしたCSVのバイト数（各アカウントの合計）
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
 func (x *JobStatus) Reset() {
 	*x = JobStatus{}
-	mi := &file_download_proto_msgTypes[4]
+	mi := &file_download_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -302,7 +355,7 @@ func (x *JobStatus) String() string {
 func (*JobStatus) ProtoMessage() {}
 
 func (x *JobStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_download_proto_msgTypes[4]
+	mi := &file_download_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -315,7 +368,7 @@ func (x *JobStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JobStatus.ProtoReflect.Descriptor instead.
 func (*JobStatus) Descriptor() ([]byte, []int) {
-	return file_download_proto_rawDescGZIP(), []int{4}
+	return file_download_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *JobStatus) GetJobId() string {
@@ -367,6 +420,476 @@ func (x *JobStatus) GetCompletedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *JobStatus) GetRetryCount() int32 {
+	if x != nil {
+		return x.RetryCount
+	}
+	return 0
+}
+
+func (x *JobStatus) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *JobStatus) GetErrorDetails() map[string]string {
+	if x != nil {
+		return x.ErrorDetails
+	}
+	return nil
+}
+
+func (x *JobStatus) GetCancelledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CancelledAt
+	}
+	return nil
+}
+
+func (x *JobStatus) GetAccounts() []*AccountResult {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+func (x *JobStatus) GetRejectedRows() int32 {
+	if x != nil {
+		return x.RejectedRows
+	}
+	return 0
+}
+
+func (x *JobStatus) GetBytesDownloaded() int64 {
+	if x != nil {
+		return x.BytesDownloaded
+	}
+	return 0
+}
+
+// ジョブ一覧取得リクエスト
+// 指定しない条件では絞り込まない
+type ListJobsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Statuses      []string               `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"`                                // いずれかのステータスに一致するジョブ
+	StartedAfter  *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=started_after,json=startedAfter,proto3" json:"started_after,omitempty"`    // この日時以降に開始したジョブ
+	StartedBefore *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=started_before,json=startedBefore,proto3" json:"started_before,omitempty"` // この日時より前に開始したジョブ
+	AccountId     string                 `protobuf:"bytes,4,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`             // このアカウントを含むジョブ
+	PageSize      int32                  `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`               // 既定50、最大500
+	PageToken     string                 `protobuf:"bytes,6,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`             // 前のレスポンスの next_page_token
+	OrderBy       string                 `protobuf:"bytes,7,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`                   // "started_at desc"（既定）または "started_at"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsRequest) Reset() {
+	*x = ListJobsRequest{}
+	mi := &file_download_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsRequest) ProtoMessage() {}
+
+func (x *ListJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_download_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsRequest.ProtoReflect.Descriptor instead.
+func (*ListJobsRequest) Descriptor() ([]byte, []int) {
+	return file_download_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListJobsRequest) GetStatuses() []string {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListJobsRequest) GetStartedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAfter
+	}
+	return nil
+}
+
+func (x *ListJobsRequest) GetStartedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedBefore
+	}
+	return nil
+}
+
+func (x *ListJobsRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ListJobsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListJobsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListJobsRequest) GetOrderBy() string {
+	if x != nil {
+		return x.OrderBy
+	}
+	return ""
+}
+
+// ジョブ一覧取得レスポンス
+type ListJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*JobSummary          `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // 次のページがない場合は空
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsResponse) Reset() {
+	*x = ListJobsResponse{}
+	mi := &file_download_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsResponse) ProtoMessage() {}
+
+func (x *ListJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_download_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListJobsResponse) Descriptor() ([]byte, []int) {
+	return file_download_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListJobsResponse) GetJobs() []*JobSummary {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+func (x *ListJobsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// ジョブの概要
+type JobSummary struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	JobId               string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Status              string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Progress            int32                  `protobuf:"varint,3,opt,name=progress,proto3" json:"progress,omitempty"`
+	TotalRecords        int32                  `protobuf:"varint,4,opt,name=total_records,json=totalRecords,proto3" json:"total_records,omitempty"`
+	ErrorCode           string                 `protobuf:"bytes,5,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	StartedAt           *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	CompletedAt         *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	CancelledAt         *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=cancelled_at,json=cancelledAt,proto3" json:"cancelled_at,omitempty"`
+	AccountStatusCounts map[string]int32       `protobuf:"bytes,9,rep,name=account_status_counts,json=accountStatusCounts,proto3" json:"account_status_counts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // アカウントのステータスごとの件数（例: completed: 2, failed: 1）
+	Accounts            []*AccountResult       `protobuf:"bytes,10,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	RejectedRows        int32                  `protobuf:"varint,11,opt,name=rejected_rows,json=rejectedRows,proto3" json:"rejected_rows,omitempty"`
+	BytesDownloaded     int64                  `protobuf:"varint,12,opt,name=bytes_downloaded,json=bytesDownloaded,proto3" json:"bytes_downloaded,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *JobSummary) Reset() {
+	*x = JobSummary{}
+	mi := &file_download_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobSummary) ProtoMessage() {}
+
+func (x *JobSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_download_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobSummary.ProtoReflect.Descriptor instead.
+func (*JobSummary) Descriptor() ([]byte, []int) {
+	return file_download_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *JobSummary) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *JobSummary) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *JobSummary) GetProgress() int32 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *JobSummary) GetTotalRecords() int32 {
+	if x != nil {
+		return x.TotalRecords
+	}
+	return 0
+}
+
+func (x *JobSummary) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *JobSummary) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *JobSummary) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *JobSummary) GetCancelledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CancelledAt
+	}
+	return nil
+}
+
+func (x *JobSummary) GetAccountStatusCounts() map[string]int32 {
+	if x != nil {
+		return x.AccountStatusCounts
+	}
+	return nil
+}
+
+func (x *JobSummary) GetAccounts() []*AccountResult {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+func (x *JobSummary) GetRejectedRows() int32 {
+	if x != nil {
+		return x.RejectedRows
+	}
+	return 0
+}
+
+func (x *JobSummary) GetBytesDownloaded() int64 {
+	if x != nil {
+		return x.BytesDownloaded
+	}
+	return 0
+}
+
+// ジョブ内の1アカウントの結果
+type AccountResult struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AccountId       string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Status          string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // pending, processing, completed, failed, cancelled, interrupted
+	Progress        int32                  `protobuf:"varint,3,opt,name=progress,proto3" json:"progress,omitempty"`
+	ErrorCode       string                 `protobuf:"bytes,4,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	ErrorMessage    string                 `protobuf:"bytes,5,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	CsvPath         string                 `protobuf:"bytes,6,opt,name=csv_path,json=csvPath,proto3" json:"csv_path,omitempty"`
+	RecordCount     int32                  `protobuf:"varint,7,opt,name=record_count,json=recordCount,proto3" json:"record_count,omitempty"` // 解析できた明細の件数
+	Attempts        int32                  `protobuf:"varint,8,opt,name=attempts,proto3" json:"attempts,omitempty"`                          // スクレイパーの各ステップの試行回数の合計（リトライを含む）
+	DurationMs      int64                  `protobuf:"varint,9,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`    // 処理時間（処理中の場合は開始からの経過時間）
+	StartedAt       *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	CompletedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	RejectedRows    int32                  `protobuf:"varint,12,opt,name=rejected_rows,json=rejectedRows,proto3" json:"rejected_rows,omitempty"`          // 検証で除外した行数
+	BytesDownloaded int64                  `protobuf:"varint,13,opt,name=bytes_downloaded,json=bytesDownloaded,proto3" json:"bytes_downloaded,omitempty"` // ダウンロードしたCSVのバイト数
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AccountResult) Reset() {
+	*x = AccountResult{}
+	mi := &file_download_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountResult) ProtoMessage() {}
+
+func (x *AccountResult) ProtoReflect() protoreflect.Message {
+	mi := &file_download_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountResult.ProtoReflect.Descriptor instead.
+func (*AccountResult) Descriptor() ([]byte, []int) {
+	return file_download_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AccountResult) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *AccountResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AccountResult) GetProgress() int32 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *AccountResult) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *AccountResult) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *AccountResult) GetCsvPath() string {
+	if x != nil {
+		return x.CsvPath
+	}
+	return ""
+}
+
+func (x *AccountResult) GetRecordCount() int32 {
+	if x != nil {
+		return x.RecordCount
+	}
+	return 0
+}
+
+func (x *AccountResult) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *AccountResult) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *AccountResult) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *AccountResult) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *AccountResult) GetRejectedRows() int32 {
+	if x != nil {
+		return x.RejectedRows
+	}
+	return 0
+}
+
+func (x *AccountResult) GetBytesDownloaded() int64 {
+	if x != nil {
+		return x.BytesDownloaded
+	}
+	return 0
+}
+
 // アカウントID取得リクエスト
 type GetAllAccountIDsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -376,7 +899,7 @@ type GetAllAccountIDsRequest struct {
 
 func (x *GetAllAccountIDsRequest) Reset() {
 	*x = GetAllAccountIDsRequest{}
-	mi := &file_download_proto_msgTypes[5]
+	mi := &file_download_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -388,7 +911,7 @@ func (x *GetAllAccountIDsRequest) String() string {
 func (*GetAllAccountIDsRequest) ProtoMessage() {}
 
 func (x *GetAllAccountIDsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_download_proto_msgTypes[5]
+	mi := &file_download_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -401,7 +924,7 @@ func (x *GetAllAccountIDsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAllAccountIDsRequest.ProtoReflect.Descriptor instead.
 func (*GetAllAccountIDsRequest) Descriptor() ([]byte, []int) {
-	return file_download_proto_rawDescGZIP(), []int{5}
+	return file_download_proto_rawDescGZIP(), []int{10}
 }
 
 // アカウントID取得レスポンス
@@ -414,7 +937,7 @@ type GetAllAccountIDsResponse struct {
 
 func (x *GetAllAccountIDsResponse) Reset() {
 	*x = GetAllAccountIDsResponse{}
-	mi := &file_download_proto_msgTypes[6]
+	mi := &file_download_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -426,7 +949,7 @@ func (x *GetAllAccountIDsResponse) String() string {
 func (*GetAllAccountIDsResponse) ProtoMessage() {}
 
 func (x *GetAllAccountIDsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_download_proto_msgTypes[6]
+	mi := &file_download_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -439,7 +962,7 @@ func (x *GetAllAccountIDsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAllAccountIDsResponse.ProtoReflect.Descriptor instead.
 func (*GetAllAccountIDsResponse) Descriptor() ([]byte, []int) {
-	return file_download_proto_rawDescGZIP(), []int{6}
+	return file_download_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *GetAllAccountIDsResponse) GetAccountIds() []string {
@@ -470,7 +993,7 @@ type ETCMeisaiRecord struct {
 
 func (x *ETCMeisaiRecord) Reset() {
 	*x = ETCMeisaiRecord{}
-	mi := &file_download_proto_msgTypes[7]
+	mi := &file_download_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -482,7 +1005,7 @@ func (x *ETCMeisaiRecord) String() string {
 func (*ETCMeisaiRecord) ProtoMessage() {}
 
 func (x *ETCMeisaiRecord) ProtoReflect() protoreflect.Message {
-	mi := &file_download_proto_msgTypes[7]
+	mi := &file_download_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -495,7 +1018,7 @@ func (x *ETCMeisaiRecord) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ETCMeisaiRecord.ProtoReflect.Descriptor instead.
 func (*ETCMeisaiRecord) Descriptor() ([]byte, []int) {
-	return file_download_proto_rawDescGZIP(), []int{7}
+	return file_download_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *ETCMeisaiRecord) GetId() int64 {
@@ -603,7 +1126,9 @@ const file_download_proto_rawDesc = "" +
 	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
 	"\amessage\x18\x03 \x01(\tR\amessage\",\n" +
 	"\x13GetJobStatusRequest\x12\x15\n" +
-	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\x9a\x02\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\")\n" +
+	"\x10CancelJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\xc7\x05\n" +
 	"\tJobStatus\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x16\n" +
 	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1a\n" +
@@ -612,7 +1137,72 @@ const file_download_proto_rawDesc = "" +
 	"\rerror_message\x18\x05 \x01(\tR\ferrorMessage\x129\n" +
 	"\n" +
 	"started_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12=\n" +
-	"\fcompleted_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\"\x19\n" +
+	"\fcompleted_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12\x1f\n" +
+	"\vretry_count\x18\b \x01(\x05R\n" +
+	"retryCount\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\t \x01(\tR\terrorCode\x12X\n" +
+	"\rerror_details\x18\n" +
+	" \x03(\v23.etc_meisai.download.v1.JobStatus.ErrorDetailsEntryR\ferrorDetails\x12=\n" +
+	"\fcancelled_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\vcancelledAt\x12A\n" +
+	"\baccounts\x18\f \x03(\v2%.etc_meisai.download.v1.AccountResultR\baccounts\x12#\n" +
+	"\rrejected_rows\x18\r \x01(\x05R\frejectedRows\x12)\n" +
+	"\x10bytes_downloaded\x18\x0e \x01(\x03R\x0fbytesDownloaded\x1a?\n" +
+	"\x11ErrorDetailsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa7\x02\n" +
+	"\x0fListJobsRequest\x12\x1a\n" +
+	"\bstatuses\x18\x01 \x03(\tR\bstatuses\x12?\n" +
+	"\rstarted_after\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\fstartedAfter\x12A\n" +
+	"\x0estarted_before\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\rstartedBefore\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x04 \x01(\tR\taccountId\x12\x1b\n" +
+	"\tpage_size\x18\x05 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x06 \x01(\tR\tpageToken\x12\x19\n" +
+	"\border_by\x18\a \x01(\tR\aorderBy\"r\n" +
+	"\x10ListJobsResponse\x126\n" +
+	"\x04jobs\x18\x01 \x03(\v2\".etc_meisai.download.v1.JobSummaryR\x04jobs\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xa0\x05\n" +
+	"\n" +
+	"JobSummary\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1a\n" +
+	"\bprogress\x18\x03 \x01(\x05R\bprogress\x12#\n" +
+	"\rtotal_records\x18\x04 \x01(\x05R\ftotalRecords\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x05 \x01(\tR\terrorCode\x129\n" +
+	"\n" +
+	"started_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12=\n" +
+	"\fcompleted_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12=\n" +
+	"\fcancelled_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\vcancelledAt\x12o\n" +
+	"\x15account_status_counts\x18\t \x03(\v2;.etc_meisai.download.v1.JobSummary.AccountStatusCountsEntryR\x13accountStatusCounts\x12A\n" +
+	"\baccounts\x18\n" +
+	" \x03(\v2%.etc_meisai.download.v1.AccountResultR\baccounts\x12#\n" +
+	"\rrejected_rows\x18\v \x01(\x05R\frejectedRows\x12)\n" +
+	"\x10bytes_downloaded\x18\f \x01(\x03R\x0fbytesDownloaded\x1aF\n" +
+	"\x18AccountStatusCountsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\xeb\x03\n" +
+	"\rAccountResult\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1a\n" +
+	"\bprogress\x18\x03 \x01(\x05R\bprogress\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x04 \x01(\tR\terrorCode\x12#\n" +
+	"\rerror_message\x18\x05 \x01(\tR\ferrorMessage\x12\x19\n" +
+	"\bcsv_path\x18\x06 \x01(\tR\acsvPath\x12!\n" +
+	"\frecord_count\x18\a \x01(\x05R\vrecordCount\x12\x1a\n" +
+	"\battempts\x18\b \x01(\x05R\battempts\x12\x1f\n" +
+	"\vduration_ms\x18\t \x01(\x03R\n" +
+	"durationMs\x129\n" +
+	"\n" +
+	"started_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12=\n" +
+	"\fcompleted_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12#\n" +
+	"\rrejected_rows\x18\f \x01(\x05R\frejectedRows\x12)\n" +
+	"\x10bytes_downloaded\x18\r \x01(\x03R\x0fbytesDownloaded\"\x19\n" +
 	"\x17GetAllAccountIDsRequest\";\n" +
 	"\x18GetAllAccountIDsResponse\x12\x1f\n" +
 	"\vaccount_ids\x18\x01 \x03(\tR\n" +
@@ -634,11 +1224,13 @@ const file_download_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
-	"updated_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt2\xb2\x03\n" +
+	"updated_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt2\xeb\x04\n" +
 	"\x0fDownloadService\x12a\n" +
 	"\fDownloadSync\x12'.etc_meisai.download.v1.DownloadRequest\x1a(.etc_meisai.download.v1.DownloadResponse\x12e\n" +
 	"\rDownloadAsync\x12'.etc_meisai.download.v1.DownloadRequest\x1a+.etc_meisai.download.v1.DownloadJobResponse\x12^\n" +
-	"\fGetJobStatus\x12+.etc_meisai.download.v1.GetJobStatusRequest\x1a!.etc_meisai.download.v1.JobStatus\x12u\n" +
+	"\fGetJobStatus\x12+.etc_meisai.download.v1.GetJobStatusRequest\x1a!.etc_meisai.download.v1.JobStatus\x12]\n" +
+	"\bListJobs\x12'.etc_meisai.download.v1.ListJobsRequest\x1a(.etc_meisai.download.v1.ListJobsResponse\x12X\n" +
+	"\tCancelJob\x12(.etc_meisai.download.v1.CancelJobRequest\x1a!.etc_meisai.download.v1.JobStatus\x12u\n" +
 	"\x10GetAllAccountIDs\x12/.etc_meisai.download.v1.GetAllAccountIDsRequest\x1a0.etc_meisai.download.v1.GetAllAccountIDsResponseB4Z2github.com/yhonda-ohishi/etc_meisai_scraper/src/pbb\x06proto3"
 
 var (
@@ -653,39 +1245,63 @@ func file_download_proto_rawDescGZIP() []byte {
 	return file_download_proto_rawDescData
 }
 
-var file_download_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_download_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_download_proto_goTypes = []any{
 	(*DownloadRequest)(nil),          // 0: etc_meisai.download.v1.DownloadRequest
 	(*DownloadResponse)(nil),         // 1: etc_meisai.download.v1.DownloadResponse
 	(*DownloadJobResponse)(nil),      // 2: etc_meisai.download.v1.DownloadJobResponse
 	(*GetJobStatusRequest)(nil),      // 3: etc_meisai.download.v1.GetJobStatusRequest
-	(*JobStatus)(nil),                // 4: etc_meisai.download.v1.JobStatus
-	(*GetAllAccountIDsRequest)(nil),  // 5: etc_meisai.download.v1.GetAllAccountIDsRequest
-	(*GetAllAccountIDsResponse)(nil), // 6: etc_meisai.download.v1.GetAllAccountIDsResponse
-	(*ETCMeisaiRecord)(nil),          // 7: etc_meisai.download.v1.ETCMeisaiRecord
-	(*timestamppb.Timestamp)(nil),    // 8: google.protobuf.Timestamp
+	(*CancelJobRequest)(nil),         // 4: etc_meisai.download.v1.CancelJobRequest
+	(*JobStatus)(nil),                // 5: etc_meisai.download.v1.JobStatus
+	(*ListJobsRequest)(nil),          // 6: etc_meisai.download.v1.ListJobsRequest
+	(*ListJobsResponse)(nil),         // 7: etc_meisai.download.v1.ListJobsResponse
+	(*JobSummary)(nil),               // 8: etc_meisai.download.v1.JobSummary
+	(*AccountResult)(nil),            // 9: etc_meisai.download.v1.AccountResult
+	(*GetAllAccountIDsRequest)(nil),  // 10: etc_meisai.download.v1.GetAllAccountIDsRequest
+	(*GetAllAccountIDsResponse)(nil), // 11: etc_meisai.download.v1.GetAllAccountIDsResponse
+	(*ETCMeisaiRecord)(nil),          // 12: etc_meisai.download.v1.ETCMeisaiRecord
+	nil,                              // 13: etc_meisai.download.v1.JobStatus.ErrorDetailsEntry
+	nil,                              // 14: etc_meisai.download.v1.JobSummary.AccountStatusCountsEntry
+	(*timestamppb.Timestamp)(nil),    // 15: google.protobuf.Timestamp
 }
 var file_download_proto_depIdxs = []int32{
-	7,  // 0: etc_meisai.download.v1.DownloadResponse.records:type_name -> etc_meisai.download.v1.ETCMeisaiRecord
-	8,  // 1: etc_meisai.download.v1.JobStatus.started_at:type_name -> google.protobuf.Timestamp
-	8,  // 2: etc_meisai.download.v1.JobStatus.completed_at:type_name -> google.protobuf.Timestamp
-	8,  // 3: etc_meisai.download.v1.ETCMeisaiRecord.usage_date:type_name -> google.protobuf.Timestamp
-	8,  // 4: etc_meisai.download.v1.ETCMeisaiRecord.downloaded_at:type_name -> google.protobuf.Timestamp
-	8,  // 5: etc_meisai.download.v1.ETCMeisaiRecord.created_at:type_name -> google.protobuf.Timestamp
-	8,  // 6: etc_meisai.download.v1.ETCMeisaiRecord.updated_at:type_name -> google.protobuf.Timestamp
-	0,  // 7: etc_meisai.download.v1.DownloadService.DownloadSync:input_type -> etc_meisai.download.v1.DownloadRequest
-	0,  // 8: etc_meisai.download.v1.DownloadService.DownloadAsync:input_type -> etc_meisai.download.v1.DownloadRequest
-	3,  // 9: etc_meisai.download.v1.DownloadService.GetJobStatus:input_type -> etc_meisai.download.v1.GetJobStatusRequest
-	5,  // 10: etc_meisai.download.v1.DownloadService.GetAllAccountIDs:input_type -> etc_meisai.download.v1.GetAllAccountIDsRequest
-	1,  // 11: etc_meisai.download.v1.DownloadService.DownloadSync:output_type -> etc_meisai.download.v1.DownloadResponse
-	2,  // 12: etc_meisai.download.v1.DownloadService.DownloadAsync:output_type -> etc_meisai.download.v1.DownloadJobResponse
-	4,  // 13: etc_meisai.download.v1.DownloadService.GetJobStatus:output_type -> etc_meisai.download.v1.JobStatus
-	6,  // 14: etc_meisai.download.v1.DownloadService.GetAllAccountIDs:output_type -> etc_meisai.download.v1.GetAllAccountIDsResponse
-	11, // [11:15] is the sub-list for method output_type
-	7,  // [7:11] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	12, // 0: etc_meisai.download.v1.DownloadResponse.records:type_name -> etc_meisai.download.v1.ETCMeisaiRecord
+	15, // 1: etc_meisai.download.v1.JobStatus.started_at:type_name -> google.protobuf.Timestamp
+	15, // 2: etc_meisai.download.v1.JobStatus.completed_at:type_name -> google.protobuf.Timestamp
+	13, // 3: etc_meisai.download.v1.JobStatus.error_details:type_name -> etc_meisai.download.v1.JobStatus.ErrorDetailsEntry
+	15, // 4: etc_meisai.download.v1.JobStatus.cancelled_at:type_name -> google.protobuf.Timestamp
+	9,  // 5: etc_meisai.download.v1.JobStatus.accounts:type_name -> etc_meisai.download.v1.AccountResult
+	15, // 6: etc_meisai.download.v1.ListJobsRequest.started_after:type_name -> google.protobuf.Timestamp
+	15, // 7: etc_meisai.download.v1.ListJobsRequest.started_before:type_name -> google.protobuf.Timestamp
+	8,  // 8: etc_meisai.download.v1.ListJobsResponse.jobs:type_name -> etc_meisai.download.v1.JobSummary
+	15, // 9: etc_meisai.download.v1.JobSummary.started_at:type_name -> google.protobuf.Timestamp
+	15, // 10: etc_meisai.download.v1.JobSummary.completed_at:type_name -> google.protobuf.Timestamp
+	15, // 11: etc_meisai.download.v1.JobSummary.cancelled_at:type_name -> google.protobuf.Timestamp
+	14, // 12: etc_meisai.download.v1.JobSummary.account_status_counts:type_name -> etc_meisai.download.v1.JobSummary.AccountStatusCountsEntry
+	9,  // 13: etc_meisai.download.v1.JobSummary.accounts:type_name -> etc_meisai.download.v1.AccountResult
+	15, // 14: etc_meisai.download.v1.AccountResult.started_at:type_name -> google.protobuf.Timestamp
+	15, // 15: etc_meisai.download.v1.AccountResult.completed_at:type_name -> google.protobuf.Timestamp
+	15, // 16: etc_meisai.download.v1.ETCMeisaiRecord.usage_date:type_name -> google.protobuf.Timestamp
+	15, // 17: etc_meisai.download.v1.ETCMeisaiRecord.downloaded_at:type_name -> google.protobuf.Timestamp
+	15, // 18: etc_meisai.download.v1.ETCMeisaiRecord.created_at:type_name -> google.protobuf.Timestamp
+	15, // 19: etc_meisai.download.v1.ETCMeisaiRecord.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 20: etc_meisai.download.v1.DownloadService.DownloadSync:input_type -> etc_meisai.download.v1.DownloadRequest
+	0,  // 21: etc_meisai.download.v1.DownloadService.DownloadAsync:input_type -> etc_meisai.download.v1.DownloadRequest
+	3,  // 22: etc_meisai.download.v1.DownloadService.GetJobStatus:input_type -> etc_meisai.download.v1.GetJobStatusRequest
+	6,  // 23: etc_meisai.download.v1.DownloadService.ListJobs:input_type -> etc_meisai.download.v1.ListJobsRequest
+	4,  // 24: etc_meisai.download.v1.DownloadService.CancelJob:input_type -> etc_meisai.download.v1.CancelJobRequest
+	10, // 25: etc_meisai.download.v1.DownloadService.GetAllAccountIDs:input_type -> etc_meisai.download.v1.GetAllAccountIDsRequest
+	1,  // 26: etc_meisai.download.v1.DownloadService.DownloadSync:output_type -> etc_meisai.download.v1.DownloadResponse
+	2,  // 27: etc_meisai.download.v1.DownloadService.DownloadAsync:output_type -> etc_meisai.download.v1.DownloadJobResponse
+	5,  // 28: etc_meisai.download.v1.DownloadService.GetJobStatus:output_type -> etc_meisai.download.v1.JobStatus
+	7,  // 29: etc_meisai.download.v1.DownloadService.ListJobs:output_type -> etc_meisai.download.v1.ListJobsResponse
+	5,  // 30: etc_meisai.download.v1.DownloadService.CancelJob:output_type -> etc_meisai.download.v1.JobStatus
+	11, // 31: etc_meisai.download.v1.DownloadService.GetAllAccountIDs:output_type -> etc_meisai.download.v1.GetAllAccountIDsResponse
+	26, // [26:32] is the sub-list for method output_type
+	20, // [20:26] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
 }
 
 func init() { file_download_proto_init() }
@@ -699,7 +1315,7 @@ func file_download_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_download_proto_rawDesc), len(file_download_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -22,6 +22,8 @@ const (
 	DownloadService_DownloadSync_FullMethodName     = "/etc_meisai.download.v1.DownloadService/DownloadSync"
 	DownloadService_DownloadAsync_FullMethodName    = "/etc_meisai.download.v1.DownloadService/DownloadAsync"
 	DownloadService_GetJobStatus_FullMethodName     = "/etc_meisai.download.v1.DownloadService/GetJobStatus"
+	DownloadService_ListJobs_FullMethodName         = "/etc_meisai.download.v1.DownloadService/ListJobs"
+	DownloadService_CancelJob_FullMethodName        = "/etc_meisai.download.v1.DownloadService/CancelJob"
 	DownloadService_GetAllAccountIDs_FullMethodName = "/etc_meisai.download.v1.DownloadService/GetAllAccountIDs"
 )
 
@@ -37,6 +39,10 @@ type DownloadServiceClient interface {
 	DownloadAsync(ctx context.Context, in *DownloadRequest, opts ...grpc.CallOption) (*DownloadJobResponse, error)
 	// ジョブステータス取得
 	GetJobStatus(ctx context.Context, in *GetJobStatusRequest, opts ...grpc.CallOption) (*JobStatus, error)
+	// ジョブ一覧取得（開始日時順、ページング付き）
+	ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error)
+	// ジョブのキャンセル（ダウンロード済みのファイルは残す）
+	CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*JobStatus, error)
 	// 全アカウントID取得
 	GetAllAccountIDs(ctx context.Context, in *GetAllAccountIDsRequest, opts ...grpc.CallOption) (*GetAllAccountIDsResponse, error)
 }
@@ -79,6 +85,26 @@ func (c *downloadServiceClient) GetJobStatus(ctx context.Context, in *GetJobStat
 	return out, nil
 }
 
+func (c *downloadServiceClient) ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListJobsResponse)
+	err := c.cc.Invoke(ctx, DownloadService_ListJobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *downloadServiceClient) CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*JobStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JobStatus)
+	err := c.cc.Invoke(ctx, DownloadService_CancelJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *downloadServiceClient) GetAllAccountIDs(ctx context.Context, in *GetAllAccountIDsRequest, opts ...grpc.CallOption) (*GetAllAccountIDsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetAllAccountIDsResponse)
@@ -101,6 +127,10 @@ type DownloadServiceServer interface {
 	DownloadAsync(context.Context, *DownloadRequest) (*DownloadJobResponse, error)
 	// ジョブステータス取得
 	GetJobStatus(context.Context, *GetJobStatusRequest) (*JobStatus, error)
+	// ジョブ一覧取得（開始日時順、ページング付き）
+	ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error)
+	// ジョブのキャンセル（ダウンロード済みのファイルは残す）
+	CancelJob(context.Context, *CancelJobRequest) (*JobStatus, error)
 	// 全アカウントID取得
 	GetAllAccountIDs(context.Context, *GetAllAccountIDsRequest) (*GetAllAccountIDsResponse, error)
 }
@@ -121,6 +151,12 @@ func (UnimplementedDownloadServiceServer) DownloadAsync(context.Context, *Downlo
 func (UnimplementedDownloadServiceServer) GetJobStatus(context.Context, *GetJobStatusRequest) (*JobStatus, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetJobStatus not implemented")
 }
+func (UnimplementedDownloadServiceServer) ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListJobs not implemented")
+}
+func (UnimplementedDownloadServiceServer) CancelJob(context.Context, *CancelJobRequest) (*JobStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelJob not implemented")
+}
 func (UnimplementedDownloadServiceServer) GetAllAccountIDs(context.Context, *GetAllAccountIDsRequest) (*GetAllAccountIDsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllAccountIDs not implemented")
 }
@@ -198,6 +234,42 @@ func _DownloadService_GetJobStatus_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DownloadService_ListJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DownloadServiceServer).ListJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DownloadService_ListJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DownloadServiceServer).ListJobs(ctx, req.(*ListJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DownloadService_CancelJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DownloadServiceServer).CancelJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DownloadService_CancelJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DownloadServiceServer).CancelJob(ctx, req.(*CancelJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DownloadService_GetAllAccountIDs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetAllAccountIDsRequest)
 	if err := dec(in); err != nil {
@@ -235,6 +307,14 @@ var DownloadService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetJobStatus",
 			Handler:    _DownloadService_GetJobStatus_Handler,
 		},
+		{
+			MethodName: "ListJobs",
+			Handler:    _DownloadService_ListJobs_Handler,
+		},
+		{
+			MethodName: "CancelJob",
+			Handler:    _DownloadService_CancelJob_Handler,
+		},
 		{
 			MethodName: "GetAllAccountIDs",
 			Handler:    _DownloadService_GetAllAccountIDs_Handler,
@@ -11,13 +11,19 @@ import (
 
 // ServiceRegistry holds all etc_meisai_scraper gRPC service implementations
 type ServiceRegistry struct {
-	DownloadService pb.DownloadServiceServer
+	DownloadService       pb.DownloadServiceServer
+	DownloadBufferService pb.DownloadBufferServiceServer
+	AccountService        pb.AccountServiceServer
 }
 
 // NewServiceRegistry creates a new service registry
 func NewServiceRegistry(db *sql.DB, logger *log.Logger) *ServiceRegistry {
+	// 各サービスでブラウザ、リクエスト間隔の制限、アカウントを共有する
+	downloadService := services.NewDownloadService(db, logger)
 	return &ServiceRegistry{
-		DownloadService: services.NewDownloadServiceGRPC(db, logger),
+		DownloadService:       services.NewDownloadServiceGRPCWithService(downloadService),
+		DownloadBufferService: services.NewDownloadBufferServiceGRPC(downloadService),
+		AccountService:        services.NewAccountServiceGRPC(downloadService),
 	}
 }
 
@@ -29,6 +35,18 @@ func (r *ServiceRegistry) RegisterAll(server *grpc.Server) {
 			log.Println("Registered: DownloadService")
 		}
 	}
+	if r.DownloadBufferService != nil {
+		pb.RegisterDownloadBufferServiceServer(server, r.DownloadBufferService)
+		if log.Default() != nil {
+			log.Println("Registered: DownloadBufferService")
+		}
+	}
+	if r.AccountService != nil {
+		pb.RegisterAccountServiceServer(server, r.AccountService)
+		if log.Default() != nil {
+			log.Println("Registered: AccountService")
+		}
+	}
 }
 
 // Register is a convenience function that creates a registry and registers all services
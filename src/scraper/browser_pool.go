@@ -0,0 +1,212 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+)
+
+// Defaults for BrowserPoolConfig
+const (
+	DefaultPoolMaxContexts = 4
+	DefaultPoolMaxUses     = 50
+)
+
+// ErrPoolClosed is returned by Acquire once the pool has been closed
+var ErrPoolClosed = errors.New("browser pool closed")
+
+// BrowserPoolConfig controls the browser shared by a BrowserPool
+type BrowserPoolConfig struct {
+	Headless    bool
+	SlowMo      float64
+	MaxContexts int // Contexts open at the same time; Acquire waits beyond this (default: DefaultPoolMaxContexts)
+	MaxUses     int // Contexts created on one browser before it is replaced (default: DefaultPoolMaxUses)
+}
+
+// BrowserPool shares one Playwright instance and browser between scrapers.
+// Every scraper still gets its own BrowserContext, so cookies and downloads stay isolated per account.
+// The browser is launched on first use, checked before each lease and replaced after
+// MaxUses contexts or a crash. Leases of a replaced browser keep working until they are released.
+type BrowserPool struct {
+	factory PlaywrightFactory
+	config  BrowserPoolConfig
+	logger  *log.Logger
+	slots   chan struct{}
+
+	mu      sync.Mutex
+	pw      PlaywrightInterface
+	current *pooledBrowser
+	closed  bool
+}
+
+// pooledBrowser is a launched browser and the leases handed out on it
+type pooledBrowser struct {
+	browser BrowserInterface
+	uses    int
+	active  int
+	retired bool
+	closed  bool
+}
+
+// NewBrowserPool creates a pool that starts Playwright through factory when the first lease is acquired
+func NewBrowserPool(factory PlaywrightFactory, config BrowserPoolConfig, logger *log.Logger) *BrowserPool {
+	if config.MaxContexts <= 0 {
+		config.MaxContexts = DefaultPoolMaxContexts
+	}
+	if config.MaxUses <= 0 {
+		config.MaxUses = DefaultPoolMaxUses
+	}
+	if logger == nil {
+		logger = log.New(os.Stdout, "[BROWSER POOL] ", log.LstdFlags)
+	}
+	return &BrowserPool{
+		factory: factory,
+		config:  config,
+		logger:  logger,
+		slots:   make(chan struct{}, config.MaxContexts),
+	}
+}
+
+// Acquire waits for a free context slot and returns a lease on a healthy browser.
+// The lease must be released when the caller's context has been closed.
+func (p *BrowserPool) Acquire(ctx context.Context) (*BrowserLease, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a browser: %w", ctx.Err())
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		<-p.slots
+		return nil, ErrPoolClosed
+	}
+	b, err := p.browserLocked()
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+	b.uses++
+	b.active++
+	return &BrowserLease{pool: p, pooled: b}, nil
+}
+
+// browserLocked returns the current browser, launching a new one if there is none or it is
+// no longer usable. p.mu must be held.
+func (p *BrowserPool) browserLocked() (*pooledBrowser, error) {
+	if b := p.current; b != nil {
+		switch {
+		case !b.browser.IsConnected():
+			p.logger.Println("⚠️ Browser is no longer connected, launching a new one")
+			p.retireLocked(b)
+		case b.uses >= p.config.MaxUses:
+			p.logger.Printf("♻️ Browser served %d contexts, launching a new one", b.uses)
+			p.retireLocked(b)
+		default:
+			return b, nil
+		}
+	}
+
+	if p.pw == nil {
+		if err := p.factory.Install(); err != nil {
+			return nil, fmt.Errorf("could not install playwright: %w", err)
+		}
+		pw, err := p.factory.Run()
+		if err != nil {
+			return nil, fmt.Errorf("could not start playwright: %w", err)
+		}
+		p.pw = pw
+	}
+
+	launchOptions := BrowserTypeLaunchOptions{
+		Headless: Bool(p.config.Headless),
+	}
+	if p.config.SlowMo > 0 {
+		launchOptions.SlowMo = Float(p.config.SlowMo)
+	}
+	browser, err := p.pw.GetChromium().Launch(launchOptions)
+	if err != nil {
+		return nil, fmt.Errorf("could not launch browser: %w", err)
+	}
+	p.logger.Printf("🚀 Launched shared browser (max %d contexts)", p.config.MaxContexts)
+
+	p.current = &pooledBrowser{browser: browser}
+	return p.current, nil
+}
+
+// retireLocked stops handing out b and closes it once its last lease is released. p.mu must be held.
+func (p *BrowserPool) retireLocked(b *pooledBrowser) {
+	b.retired = true
+	if p.current == b {
+		p.current = nil
+	}
+	if b.active == 0 {
+		b.close()
+	}
+}
+
+// Close closes the browser and stops Playwright. Leases still held fail on their next browser call.
+func (p *BrowserPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+
+	if p.current != nil {
+		p.current.retired = true
+		p.current.close()
+		p.current = nil
+	}
+	if p.pw != nil {
+		return p.pw.Stop()
+	}
+	return nil
+}
+
+func (b *pooledBrowser) close() {
+	if !b.closed {
+		b.closed = true
+		b.browser.Close()
+	}
+}
+
+// BrowserLease is a slot on the pool's browser held by one scraper
+type BrowserLease struct {
+	pool   *BrowserPool
+	pooled *pooledBrowser
+	once   sync.Once
+}
+
+// Browser returns the shared browser to create the scraper's context on
+func (l *BrowserLease) Browser() BrowserInterface {
+	return l.pooled.browser
+}
+
+// Release gives the slot back. crashed retires the browser so the next lease gets a fresh one.
+// Calling Release more than once has no effect.
+func (l *BrowserLease) Release(crashed bool) {
+	l.once.Do(func() {
+		p, b := l.pool, l.pooled
+
+		p.mu.Lock()
+		b.active--
+		switch {
+		case crashed && !b.retired:
+			p.logger.Println("⚠️ Browser crashed, it will be replaced")
+			p.retireLocked(b)
+		case b.retired && b.active == 0:
+			b.close()
+		}
+		p.mu.Unlock()
+
+		<-p.slots
+	})
+}
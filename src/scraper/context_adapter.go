@@ -0,0 +1,87 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+)
+
+// AsContextScraper returns s as a ContextScraper. Scrapers without native context support
+// are wrapped so that a done ctx closes the scraper and returns without waiting for it.
+func AsContextScraper(s ScraperInterface) ContextScraper {
+	if cs, ok := s.(ContextScraper); ok {
+		return cs
+	}
+	return &contextAdapter{ScraperInterface: s}
+}
+
+// contextAdapter adds context support to a plain ScraperInterface
+type contextAdapter struct {
+	ScraperInterface
+}
+
+func (a *contextAdapter) InitializeContext(ctx context.Context) error {
+	return a.run(ctx, a.Initialize)
+}
+
+func (a *contextAdapter) LoginContext(ctx context.Context) error {
+	return a.run(ctx, a.Login)
+}
+
+func (a *contextAdapter) DownloadMeisaiContext(ctx context.Context, fromDate, toDate string) (string, error) {
+	var path string
+	err := a.run(ctx, func() error {
+		var err error
+		path, err = a.DownloadMeisai(fromDate, toDate)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func (a *contextAdapter) run(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	panicked := make(chan interface{}, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				panicked <- r
+			}
+		}()
+		done <- fn()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case r := <-panicked:
+		// Re-panic on the caller's goroutine so its recover still applies
+		panic(r)
+	case <-ctx.Done():
+		a.Close()
+		return ctx.Err()
+	}
+}
+
+// DownloadToBuffer downloads the CSV for the range with s and returns its content.
+// Scrapers without BufferScraper download to a file, which is read and deleted.
+func DownloadToBuffer(ctx context.Context, s ContextScraper, fromDate, toDate string) ([]byte, error) {
+	if bs, ok := s.(BufferScraper); ok {
+		return bs.DownloadMeisaiToBufferContext(ctx, fromDate, toDate)
+	}
+
+	csvPath, err := s.DownloadMeisaiContext(ctx, fromDate, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download CSV: %w", err)
+	}
+	data, err := ReadAndDeleteFile(csvPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV file: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,113 @@
+package scraper
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// DateRange is an inclusive usage-date period (利用年月日) for a meisai search
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// DateRangeError is returned when a requested period is invalid or cannot be
+// represented on the site's search form
+type DateRangeError struct {
+	FromDate string
+	ToDate   string
+	Reason   string
+}
+
+func (e *DateRangeError) Error() string {
+	return fmt.Sprintf("invalid date range %s - %s: %s", e.FromDate, e.ToDate, e.Reason)
+}
+
+// dateLayouts are the accepted input formats for fromDate/toDate
+var dateLayouts = []string{"2006-01-02", "2006/01/02"}
+
+// periodPattern matches dates shown on the result page, e.g. "2024年01月01日" or "2024/1/1"
+var periodPattern = regexp.MustCompile(`(\d{4})\s*[年/\-.]\s*(\d{1,2})\s*[月/\-.]\s*(\d{1,2})`)
+
+// ParseDateRange parses and validates a fromDate/toDate pair
+func ParseDateRange(fromDate, toDate string) (DateRange, error) {
+	from, err := parseDate(fromDate)
+	if err != nil {
+		return DateRange{}, &DateRangeError{FromDate: fromDate, ToDate: toDate, Reason: fmt.Sprintf("invalid from date: %v", err)}
+	}
+
+	to, err := parseDate(toDate)
+	if err != nil {
+		return DateRange{}, &DateRangeError{FromDate: fromDate, ToDate: toDate, Reason: fmt.Sprintf("invalid to date: %v", err)}
+	}
+
+	if from.After(to) {
+		return DateRange{}, &DateRangeError{FromDate: fromDate, ToDate: toDate, Reason: "from date is after to date"}
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if to.After(today) {
+		return DateRange{}, &DateRangeError{FromDate: fromDate, ToDate: toDate, Reason: "to date is in the future"}
+	}
+
+	return DateRange{From: from, To: to}, nil
+}
+
+// String formats the range the same way it is accepted
+func (r DateRange) String() string {
+	return fmt.Sprintf("%s - %s", r.From.Format("2006-01-02"), r.To.Format("2006-01-02"))
+}
+
+func parseDate(value string) (time.Time, error) {
+	var lastErr error
+	for _, layout := range dateLayouts {
+		t, err := time.ParseInLocation(layout, value, time.Local)
+		if err == nil {
+			return t, nil
+		}
+		lastErr = err
+	}
+	return time.Time{}, lastErr
+}
+
+// parsePeriodText extracts the first two dates shown in a result period text
+func parsePeriodText(text string) (time.Time, time.Time, bool) {
+	matches := periodPattern.FindAllStringSubmatch(text, 2)
+	if len(matches) < 2 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	var dates [2]time.Time
+	for i, m := range matches {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		dates[i] = time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
+	}
+	return dates[0], dates[1], true
+}
+
+// checkResultPeriod compares the period text shown on the result page with the requested range
+func checkResultPeriod(dateRange DateRange, text string) error {
+	shownFrom, shownTo, ok := parsePeriodText(text)
+	if !ok {
+		return &DateRangeError{
+			FromDate: dateRange.From.Format("2006-01-02"),
+			ToDate:   dateRange.To.Format("2006-01-02"),
+			Reason:   fmt.Sprintf("could not read period from result page: %q", text),
+		}
+	}
+
+	if !shownFrom.Equal(dateRange.From) || !shownTo.Equal(dateRange.To) {
+		return &DateRangeError{
+			FromDate: dateRange.From.Format("2006-01-02"),
+			ToDate:   dateRange.To.Format("2006-01-02"),
+			Reason: fmt.Sprintf("result page shows %s - %s",
+				shownFrom.Format("2006-01-02"), shownTo.Format("2006-01-02")),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Sentinel errors returned by Login and DownloadMeisai. Match them with errors.Is.
+var (
+	// ErrInvalidCredentials is returned when the site rejects the user ID or password
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrAccountLocked is returned when the account is locked after repeated login failures
+	ErrAccountLocked = errors.New("account locked")
+	// ErrPasswordExpired is returned when the site requires a password change before login
+	ErrPasswordExpired = errors.New("password expired")
+	// ErrSiteMaintenance is returned while the meisai service is down for maintenance
+	ErrSiteMaintenance = errors.New("site under maintenance")
+	// ErrNoResults is returned when the search result page offers nothing to download
+	ErrNoResults = errors.New("no results for range")
+	// ErrSelectorDrift is returned when an element the scraper relies on is no longer on the page
+	ErrSelectorDrift = errors.New("selector no longer matches page")
+	// ErrDownloadTimeout is returned when the CSV download event does not arrive in time
+	ErrDownloadTimeout = errors.New("download timeout")
+	// ErrBrowserCrash is returned when the browser or page died while a step was running
+	ErrBrowserCrash = errors.New("browser crashed")
+	// ErrSessionExpired is returned by a step when the site has dropped the login session
+	ErrSessionExpired = errors.New("login session expired")
+	// ErrUnsupportedSite is returned by the HTTP backend when a page cannot be handled without a browser
+	ErrUnsupportedSite = errors.New("site shape not supported by HTTP backend")
+)
+
+// maintenancePattern finds the notice the site shows instead of its pages during maintenance in raw HTML.
+// The browser backend uses the maintenance_notice element of the selector pack instead.
+var maintenancePattern = regexp.MustCompile("システムメンテナンス中|メンテナンスのため")
+
+// LoginError carries the message the site showed when it refused a login.
+// Kind is ErrInvalidCredentials, ErrAccountLocked, ErrPasswordExpired or ErrSiteMaintenance.
+type LoginError struct {
+	Kind    error
+	Message string
+}
+
+func (e *LoginError) Error() string {
+	return fmt.Sprintf("login failed: %s", e.Message)
+}
+
+func (e *LoginError) Unwrap() error {
+	return e.Kind
+}
+
+// newLoginError picks the error kind from the message shown on the login page
+func newLoginError(message string) *LoginError {
+	kind := ErrInvalidCredentials
+	switch {
+	case strings.Contains(message, "ロック"):
+		kind = ErrAccountLocked
+	case strings.Contains(message, "有効期限") || strings.Contains(message, "パスワードの変更"):
+		kind = ErrPasswordExpired
+	case strings.Contains(message, "メンテナンス"):
+		kind = ErrSiteMaintenance
+	}
+	return &LoginError{Kind: kind, Message: message}
+}
+
+// SelectorError reports which selectors were tried when an element could not be found
+type SelectorError struct {
+	Element   string
+	Selectors []string
+	Hint      string
+}
+
+func (e *SelectorError) Error() string {
+	msg := fmt.Sprintf("%s not found with any selector", e.Element)
+	if e.Hint != "" {
+		msg += " - " + e.Hint
+	}
+	return msg
+}
+
+func (e *SelectorError) Unwrap() error {
+	return ErrSelectorDrift
+}
+
+// StepError is returned when a scraper step still fails after its retries
+type StepError struct {
+	Step     string
+	Attempts int
+	Err      error
+	Evidence string // Directory of the failure evidence bundle, empty if none was captured
+}
+
+func (e *StepError) Error() string {
+	if e.Attempts > 1 {
+		return fmt.Sprintf("%s failed after %d attempts: %v", e.Step, e.Attempts, e.Err)
+	}
+	return e.Err.Error()
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// browserCrashMarkers are substrings of Playwright errors raised when the browser or page is gone
+var browserCrashMarkers = []string{
+	"target closed",
+	"has been closed",
+	"page crashed",
+	"browser crashed",
+	"browser has disconnected",
+}
+
+// wrapBrowserCrash marks err as ErrBrowserCrash if Playwright reports the browser as gone
+func wrapBrowserCrash(err error) error {
+	if err == nil || errors.Is(err, ErrBrowserCrash) {
+		return err
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range browserCrashMarkers {
+		if strings.Contains(msg, marker) {
+			return fmt.Errorf("%w: %w", ErrBrowserCrash, err)
+		}
+	}
+	return err
+}
+
+// errorCodes maps each sentinel error to a stable code for API responses
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrInvalidCredentials, "INVALID_CREDENTIALS"},
+	{ErrAccountLocked, "ACCOUNT_LOCKED"},
+	{ErrPasswordExpired, "PASSWORD_EXPIRED"},
+	{ErrSiteMaintenance, "SITE_MAINTENANCE"},
+	{ErrNoResults, "NO_RESULTS"},
+	{ErrSelectorDrift, "SELECTOR_DRIFT"},
+	{ErrDownloadTimeout, "DOWNLOAD_TIMEOUT"},
+	{ErrBrowserCrash, "BROWSER_CRASH"},
+	{ErrSessionExpired, "SESSION_EXPIRED"},
+	{ErrUnsupportedSite, "UNSUPPORTED_SITE"},
+	{context.Canceled, "CANCELLED"},
+	{context.DeadlineExceeded, "DEADLINE_EXCEEDED"},
+}
+
+// ErrorCode returns a stable code such as "INVALID_CREDENTIALS" for err, or "" for nil
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.code
+		}
+	}
+	var rangeErr *DateRangeError
+	if errors.As(err, &rangeErr) {
+		return "INVALID_DATE_RANGE"
+	}
+	return "UNKNOWN"
+}
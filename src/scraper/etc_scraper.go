@@ -1,10 +1,13 @@
 package scraper
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 )
 
@@ -17,20 +20,72 @@ type ETCScraper struct {
 	config  *ScraperConfig
 	logger  *log.Logger
 	factory PlaywrightFactory
+
+	closeMu sync.Mutex
+	closed  bool
+
+	downloads chan string // Receives saved CSV paths from the page's download handler
+	steps     *stepRunner
+
+	restored *SavedSession // Session loaded into the browser context, verified by the next login
+	messages pageLog       // Dialog and console messages kept for failure evidence
+
+	lease         *BrowserLease // Slot on config.Browsers when the browser is shared
+	contextFailed bool          // The browser could not create a context, so it may be broken
 }
 
+// DefaultBaseURL is the top page of the ETC meisai service
+const DefaultBaseURL = "https://www.etc-meisai.jp/"
+
+// Scraper backends selectable with ScraperConfig.Backend
+const (
+	BackendPlaywright = "playwright"
+	BackendHTTP       = "http"
+)
+
 // ScraperConfig holds configuration for the scraper
 type ScraperConfig struct {
 	UserID        string
-	Password      string
+	Password      string // Ignored when Secrets is set
+	BaseURL       string // Top page of the meisai site (default: DefaultBaseURL)
+	Backend       string // BackendPlaywright (default) or BackendHTTP
 	DownloadPath  string
 	SessionFolder string // Current session folder for this execution
 	Headless      bool
 	Timeout       float64
-	RetryCount    int
+	RetryCount    int // Retries per step after the first attempt (negative disables retries)
 	UserAgent     string
 	SlowMo        float64
 	TestMode      bool // Skip time.Sleep in tests
+
+	RetryBackoff    time.Duration // Wait before the first retry, doubled on each further retry (0 retries at once)
+	RetryMaxBackoff time.Duration
+	RetryJitter     float64 // Fraction of the backoff randomized in both directions (0-1, 0 disables)
+	DownloadTimeout time.Duration
+
+	Sessions  *SessionStore  // Saved logins reused across runs (nil always logs in from scratch)
+	Selectors *SelectorPack  // Selectors for the site's pages (default: DefaultSelectorPack)
+	Browsers  *BrowserPool   // Shared browser; Headless and SlowMo then come from the pool (nil launches one per scraper)
+	Limiter   *RateLimiter   // Shared limit on requests to the site (nil sends them as fast as the steps run)
+	Secrets   SecretProvider // Looks up the password of UserID at login (nil uses Password)
+}
+
+// Retry settings of DefaultScraperConfig
+const (
+	DefaultRetryBackoff = 2 * time.Second
+	DefaultRetryJitter  = 0.2
+)
+
+// DefaultScraperConfig returns a configuration with every default filled in.
+// Start from it rather than a zero ScraperConfig to get the retry backoff and jitter:
+// zero RetryBackoff and RetryJitter are kept as set, while other zero values get their defaults.
+func DefaultScraperConfig() *ScraperConfig {
+	config := &ScraperConfig{
+		RetryBackoff: DefaultRetryBackoff,
+		RetryJitter:  DefaultRetryJitter,
+	}
+	applyConfigDefaults(config)
+	return config
 }
 
 // NewETCScraper creates a new ETC scraper instance (for production use)
@@ -47,7 +102,32 @@ func NewETCScraperWithFactory(config *ScraperConfig, logger *log.Logger, factory
 		return nil, fmt.Errorf("factory is required for testable scraper")
 	}
 
-	// Set default values
+	applyConfigDefaults(config)
+
+	// Skip directory creation for better testability
+
+	if logger == nil {
+		logger = log.New(os.Stdout, "[SCRAPER] ", log.LstdFlags)
+	}
+
+	s := &ETCScraper{
+		config:  config,
+		logger:  logger,
+		factory: factory,
+	}
+	s.steps = &stepRunner{config: config, logger: logger, relogin: s.login, capture: s.captureEvidence}
+	return s, nil
+}
+
+// applyConfigDefaults fills in unset configuration values. It is shared by all backends.
+// RetryBackoff and RetryJitter are left alone so that callers can turn them off with zero.
+func applyConfigDefaults(config *ScraperConfig) {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultBaseURL
+	}
+	if config.Backend == "" {
+		config.Backend = BackendPlaywright
+	}
 	if config.DownloadPath == "" {
 		config.DownloadPath = "./downloads"
 	}
@@ -60,56 +140,58 @@ func NewETCScraperWithFactory(config *ScraperConfig, logger *log.Logger, factory
 	if config.UserAgent == "" {
 		config.UserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"
 	}
-
-	// Skip directory creation for better testability
-
-	if logger == nil {
-		logger = log.New(os.Stdout, "[SCRAPER] ", log.LstdFlags)
+	if config.RetryMaxBackoff == 0 {
+		config.RetryMaxBackoff = 30 * time.Second
+	}
+	if config.DownloadTimeout == 0 {
+		config.DownloadTimeout = 60 * time.Second
+	}
+	if config.Selectors == nil {
+		config.Selectors = DefaultSelectorPack()
 	}
-
-	return &ETCScraper{
-		config:  config,
-		logger:  logger,
-		factory: factory,
-	}, nil
 }
 
-// Initialize sets up Playwright and browser
-func (s *ETCScraper) Initialize() error {
-	var err error
-
-	// Install playwright browsers if needed
-	err = s.factory.Install()
-	if err != nil {
-		return fmt.Errorf("could not install playwright: %w", err)
+// prepareSessionFolder returns the folder downloads of this session are saved to, creating it if needed
+func prepareSessionFolder(config *ScraperConfig, logger *log.Logger) (string, error) {
+	if config.SessionFolder != "" {
+		// Use existing session folder (for multiple downloads in same session)
+		logger.Printf("Using existing session folder: %s", config.SessionFolder)
+		return config.SessionFolder, nil
 	}
 
-	// Start Playwright
-	s.pw, err = s.factory.Run()
-	if err != nil {
-		return fmt.Errorf("could not start playwright: %w", err)
+	// Create new timestamped subfolder for this download session
+	timestamp := time.Now().Format("20060102_150405")
+	sessionFolder := filepath.Join(config.DownloadPath, timestamp)
+	if err := os.MkdirAll(sessionFolder, 0755); err != nil {
+		return "", fmt.Errorf("failed to create session folder: %w", err)
 	}
+	logger.Printf("Created new session folder: %s", sessionFolder)
+	config.SessionFolder = sessionFolder
+	return sessionFolder, nil
+}
 
-	// Launch browser
-	launchOptions := BrowserTypeLaunchOptions{
-		Headless: Bool(s.config.Headless),
-	}
+// Initialize sets up Playwright and browser
+func (s *ETCScraper) Initialize() error {
+	return s.InitializeContext(context.Background())
+}
 
-	if s.config.SlowMo > 0 {
-		launchOptions.SlowMo = Float(s.config.SlowMo)
-	}
+// InitializeContext sets up Playwright and browser, stopping early when ctx is done
+func (s *ETCScraper) InitializeContext(ctx context.Context) error {
+	var err error
 
-	// Log Headless mode setting
-	if s.config.Headless {
-		s.logger.Println("🔒 Launching browser in Headless mode (browser not visible)")
-	} else {
-		s.logger.Println("👁️  Launching browser in VISIBLE mode (browser will appear)")
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("initialization aborted: %w", err)
 	}
 
-	chromium := s.pw.GetChromium()
-	s.browser, err = chromium.Launch(launchOptions)
-	if err != nil {
-		return fmt.Errorf("could not launch browser: %w", err)
+	if s.config.Browsers != nil {
+		// Borrow the shared browser instead of starting Playwright for this account
+		s.lease, err = s.config.Browsers.Acquire(ctx)
+		if err != nil {
+			return fmt.Errorf("could not acquire browser: %w", err)
+		}
+		s.browser = s.lease.Browser()
+	} else if err := s.launchBrowser(ctx); err != nil {
+		return err
 	}
 
 	// Create browser context with download settings
@@ -122,14 +204,31 @@ func (s *ETCScraper) Initialize() error {
 		UserAgent: String(s.config.UserAgent),
 	}
 
+	// Restore the last login of this account if it is still within its TTL
+	if s.config.Sessions != nil {
+		saved, err := s.config.Sessions.Load(s.config.UserID)
+		if err != nil {
+			s.logger.Printf("Warning: ignoring saved session: %v", err)
+		}
+		if saved != nil {
+			contextOptions.StorageState = saved.State
+			s.restored = saved
+		}
+	}
+
 	s.context, err = s.browser.NewContext(contextOptions)
 	if err != nil {
+		s.contextFailed = true
 		return fmt.Errorf("could not create browser context: %w", err)
 	}
 
 	// Set default timeout
 	s.context.SetDefaultTimeout(s.config.Timeout)
 
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("initialization aborted: %w", err)
+	}
+
 	// Create page
 	s.page, err = s.context.NewPage()
 	if err != nil {
@@ -140,6 +239,9 @@ func (s *ETCScraper) Initialize() error {
 	s.logger.Println("Setting up global dialog handler...")
 	s.page.On("dialog", func(dialog interface{}) {
 		s.logger.Printf("🔔 Dialog detected! Type: %T", dialog)
+		if d, ok := dialog.(interface{ Message() string }); ok {
+			s.messages.addDialog(d.Message())
+		}
 		// playwright.Dialog has Accept() method - cast to playwright.Dialog
 		if d, ok := dialog.(interface {
 			Accept(promptText ...string) error
@@ -155,37 +257,203 @@ func (s *ETCScraper) Initialize() error {
 		}
 	})
 
+	s.page.On("console", func(message string) {
+		s.messages.addConsole(message)
+	})
+
 	s.logger.Printf("Scraper initialized with download path: %s", s.config.DownloadPath)
+	return ctx.Err()
+}
+
+// launchBrowser starts Playwright and a browser owned by this scraper
+func (s *ETCScraper) launchBrowser(ctx context.Context) error {
+	var err error
+
+	// Install playwright browsers if needed
+	err = s.factory.Install()
+	if err != nil {
+		return fmt.Errorf("could not install playwright: %w", err)
+	}
+
+	// Start Playwright
+	s.pw, err = s.factory.Run()
+	if err != nil {
+		return fmt.Errorf("could not start playwright: %w", err)
+	}
+
+	// Launch browser
+	launchOptions := BrowserTypeLaunchOptions{
+		Headless: Bool(s.config.Headless),
+	}
+
+	if s.config.SlowMo > 0 {
+		launchOptions.SlowMo = Float(s.config.SlowMo)
+	}
+
+	// Log Headless mode setting
+	if s.config.Headless {
+		s.logger.Println("🔒 Launching browser in Headless mode (browser not visible)")
+	} else {
+		s.logger.Println("👁️  Launching browser in VISIBLE mode (browser will appear)")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("initialization aborted: %w", err)
+	}
+
+	chromium := s.pw.GetChromium()
+	s.browser, err = chromium.Launch(launchOptions)
+	if err != nil {
+		return fmt.Errorf("could not launch browser: %w", err)
+	}
 	return nil
 }
 
 // Login performs login to ETC meisai service
 func (s *ETCScraper) Login() error {
+	return s.LoginContext(context.Background())
+}
+
+// LoginContext performs login, tearing down the browser if ctx is cancelled mid-way
+func (s *ETCScraper) LoginContext(ctx context.Context) error {
+	stop := s.watchContext(ctx)
+	defer stop()
+
+	return contextError(ctx, "login", s.login(ctx))
+}
+
+func (s *ETCScraper) login(ctx context.Context) error {
 	if s.page == nil {
 		return fmt.Errorf("scraper not initialized")
 	}
 
-	s.logger.Println("Navigating to https://www.etc-meisai.jp/")
+	// Only the first login may reuse the restored session; re-logins always start over
+	if restored := s.restored; restored != nil {
+		s.restored = nil
+		if s.resumeSession(restored) {
+			return nil
+		}
+	}
 
 	// Navigate to top page
-	_, err := s.page.Goto("https://www.etc-meisai.jp/", PageGotoOptions{
+	if err := s.steps.run(ctx, StepNavigation, func(attempt int) error {
+		return s.gotoTopPage()
+	}); err != nil {
+		return err
+	}
+
+	if err := s.steps.run(ctx, StepLogin, func(attempt int) error {
+		if attempt > 1 {
+			// Start over from the top page after a failed attempt
+			if err := s.gotoTopPage(); err != nil {
+				return err
+			}
+		}
+		return s.submitLogin(ctx)
+	}); err != nil {
+		return err
+	}
+
+	s.saveSession()
+	return nil
+}
+
+// LogoutContext clicks the logout link and forgets the saved session of the account
+func (s *ETCScraper) LogoutContext(ctx context.Context) error {
+	if s.page == nil {
+		return nil
+	}
+	stop := s.watchContext(ctx)
+	defer stop()
+
+	if s.config.Sessions != nil {
+		if err := s.config.Sessions.Delete(s.config.UserID); err != nil {
+			s.logger.Printf("Warning: failed to delete saved session: %v", err)
+		}
+	}
+	if s.count(ElementLogoutLink) == 0 {
+		return nil
+	}
+
+	s.logger.Println("Logging out...")
+	if err := s.locate(ElementLogoutLink).Click(LocatorClickOptions{}); err != nil {
+		return contextError(ctx, "logout", fmt.Errorf("failed to click logout link: %w", err))
+	}
+	s.waitForNavigation(ctx)
+	return nil
+}
+
+// resumeSession reopens the page saved with the session and reports whether it is still logged in
+func (s *ETCScraper) resumeSession(saved *SavedSession) bool {
+	s.logger.Printf("Checking saved session from %s", saved.SavedAt.Format(time.RFC3339))
+
+	_, err := s.page.Goto(saved.URL, PageGotoOptions{
+		WaitUntil: WaitUntilStateNetworkidle,
+	})
+	if err == nil {
+		if s.count(ElementLogoutLink) > 0 && s.count(ElementLoginUserID) == 0 {
+			s.logger.Println("♻️ Reusing saved session, skipping login")
+			return true
+		}
+	}
+
+	s.logger.Println("Saved session is no longer valid, logging in again")
+	if err := s.config.Sessions.Delete(s.config.UserID); err != nil {
+		s.logger.Printf("Warning: failed to delete saved session: %v", err)
+	}
+	return false
+}
+
+// saveSession stores the logged-in browser state so the next run can skip the login
+func (s *ETCScraper) saveSession() {
+	if s.config.Sessions == nil || s.context == nil {
+		return
+	}
+
+	state, err := s.context.StorageState()
+	if err != nil {
+		s.logger.Printf("Warning: could not read browser storage state: %v", err)
+		return
+	}
+	err = s.config.Sessions.Save(&SavedSession{
+		UserID: s.config.UserID,
+		State:  state,
+		URL:    s.page.URL(),
+	})
+	if err != nil {
+		s.logger.Printf("Warning: could not save session: %v", err)
+	}
+}
+
+// gotoTopPage opens the top page of the meisai service
+func (s *ETCScraper) gotoTopPage() error {
+	s.logger.Printf("Navigating to %s", s.config.BaseURL)
+
+	_, err := s.page.Goto(s.config.BaseURL, PageGotoOptions{
 		WaitUntil: WaitUntilStateNetworkidle,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to navigate to top page: %w", err)
 	}
 
+	if s.count(ElementMaintenanceNotice) > 0 {
+		return ErrSiteMaintenance
+	}
+	return nil
+}
+
+// submitLogin opens the login form from the top page and submits the credentials
+func (s *ETCScraper) submitLogin(ctx context.Context) error {
 	// Click login link
 	s.logger.Println("Clicking login link...")
-	loginLinkSelector := "a[href*='funccode=1013000000']"
-	loginLink := s.page.Locator(loginLinkSelector).First()
+	loginLink := s.locate(ElementLoginLink)
 	if err := loginLink.Click(LocatorClickOptions{}); err != nil {
 		return fmt.Errorf("failed to click login link: %w", err)
 	}
 
 	// Wait for login page to load
-	s.waitForNavigation()
-	err = s.page.WaitForLoadState(PageWaitForLoadStateOptions{
+	s.waitForNavigation(ctx)
+	err := s.page.WaitForLoadState(PageWaitForLoadStateOptions{
 		State: LoadStateNetworkidle,
 	})
 	if err != nil {
@@ -194,8 +462,8 @@ func (s *ETCScraper) Login() error {
 
 	// Wait for login form with correct field names
 	s.logger.Println("Waiting for login form...")
-	userIDField := s.page.Locator("input[name='risLoginId']")
-	passwordField := s.page.Locator("input[name='risPassword']")
+	userIDField := s.locate(ElementLoginUserID)
+	passwordField := s.locate(ElementLoginPassword)
 
 	// Fill user ID
 	s.logger.Println("Filling login credentials...")
@@ -203,20 +471,24 @@ func (s *ETCScraper) Login() error {
 		return fmt.Errorf("failed to fill user ID: %w", err)
 	}
 
-	// Fill password
-	if err := passwordField.Fill(s.config.Password); err != nil {
+	// Fill password (looked up only now so it is not kept around)
+	password, err := loginPassword(ctx, s.config)
+	if err != nil {
+		return err
+	}
+	if err := passwordField.Fill(password); err != nil {
 		return fmt.Errorf("failed to fill password: %w", err)
 	}
 
 	// Click login button
 	s.logger.Println("Clicking login button...")
-	loginButton := s.page.Locator("input[type='button'][value='ログイン']")
+	loginButton := s.locate(ElementLoginButton)
 	if err := loginButton.Click(LocatorClickOptions{}); err != nil {
 		return fmt.Errorf("failed to click login button: %w", err)
 	}
 
 	// Wait for navigation after login
-	s.waitForNavigation()
+	s.waitForNavigation(ctx)
 	err = s.page.WaitForLoadState(PageWaitForLoadStateOptions{
 		State: LoadStateNetworkidle,
 	})
@@ -225,47 +497,62 @@ func (s *ETCScraper) Login() error {
 	}
 
 	// Check if login was successful
-	logoutLocator := s.page.Locator("a:has-text('ログアウト')")
-	logoutExists, _ := logoutLocator.Count()
-	if logoutExists > 0 {
+	if s.count(ElementLogoutLink) > 0 {
 		s.logger.Println("Login successful!")
 		return nil
 	}
 
 	// Check for error messages
-	errorLocator := s.page.Locator(".error-message, .alert-danger, .error").First()
+	errorLocator := s.locate(ElementLoginError)
 	errorMsg, _ := errorLocator.TextContent(LocatorTextContentOptions{})
 	if errorMsg != "" {
-		return fmt.Errorf("login failed: %s", errorMsg)
+		return newLoginError(errorMsg)
 	}
 
-	s.logger.Println("Login completed")
-	return nil
+	// Only the logout link proves the login; never report success without it
+	if s.count(ElementLoginUserID) > 0 {
+		return newLoginError("login form was shown again")
+	}
+	return &SelectorError{
+		Element:   ElementLogoutLink,
+		Selectors: s.config.Selectors.Selectors(ElementLogoutLink),
+		Hint:      "neither the logout link nor the login form is shown after login",
+	}
 }
 
 // DownloadMeisai downloads ETC meisai data for specified date range
 func (s *ETCScraper) DownloadMeisai(fromDate, toDate string) (string, error) {
+	return s.DownloadMeisaiContext(context.Background(), fromDate, toDate)
+}
+
+// DownloadMeisaiContext downloads ETC meisai data, aborting the in-flight browser step when ctx is done
+func (s *ETCScraper) DownloadMeisaiContext(ctx context.Context, fromDate, toDate string) (string, error) {
+	stop := s.watchContext(ctx)
+	defer stop()
+
+	path, err := s.downloadMeisai(ctx, fromDate, toDate)
+	if err := contextError(ctx, "download", err); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func (s *ETCScraper) downloadMeisai(ctx context.Context, fromDate, toDate string) (string, error) {
 	if s.page == nil {
 		return "", fmt.Errorf("scraper not initialized")
 	}
 
+	dateRange, err := ParseDateRange(fromDate, toDate)
+	if err != nil {
+		return "", err
+	}
+
 	s.logger.Printf("Downloading meisai from %s to %s", fromDate, toDate)
 
 	// Use existing session folder or create a new one
-	var sessionFolder string
-	if s.config.SessionFolder != "" {
-		// Use existing session folder (for multiple downloads in same session)
-		sessionFolder = s.config.SessionFolder
-		s.logger.Printf("Using existing session folder: %s", sessionFolder)
-	} else {
-		// Create new timestamped subfolder for this download session
-		timestamp := time.Now().Format("20060102_150405")
-		sessionFolder = filepath.Join(s.config.DownloadPath, timestamp)
-		if err := os.MkdirAll(sessionFolder, 0755); err != nil {
-			return "", fmt.Errorf("failed to create session folder: %w", err)
-		}
-		s.logger.Printf("Created new session folder: %s", sessionFolder)
-		s.config.SessionFolder = sessionFolder
+	sessionFolder, err := prepareSessionFolder(s.config, s.logger)
+	if err != nil {
+		return "", err
 	}
 
 	// Update download path to use session folder
@@ -275,14 +562,71 @@ func (s *ETCScraper) DownloadMeisai(fromDate, toDate string) (string, error) {
 		s.config.DownloadPath = originalDownloadPath
 	}()
 
+	// Search with the requested period (re-login happens here if the session expired)
+	if err := s.steps.run(ctx, StepSearch, func(attempt int) error {
+		return s.search(ctx, dateRange)
+	}); err != nil {
+		return "", err
+	}
+
+	// Setup download handler once per page so retried clicks do not register it again
+	s.setupDownloadHandler()
+
+	// Drop a late download left over from a previous period
+	select {
+	case stale := <-s.downloads:
+		s.logger.Printf("⚠️ Discarding late download from previous request: %s", stale)
+	default:
+	}
+
+	// Click CSV download link
+	var csvLink LocatorInterface
+	if err := s.steps.run(ctx, StepCSVLink, func(attempt int) error {
+		link, err := s.clickCSVLink(dateRange)
+		csvLink = link
+		return err
+	}); err != nil {
+		return "", err
+	}
+
+	s.logger.Println("Waiting for CSV download to complete...")
+
+	var path string
+	err = s.steps.run(ctx, StepDownloadSave, func(attempt int) error {
+		if attempt > 1 {
+			s.logger.Println("Clicking CSV download link again...")
+			if err := csvLink.Click(LocatorClickOptions{}); err != nil {
+				return fmt.Errorf("failed to click CSV link: %w", err)
+			}
+		}
+
+		// Wait for download with timeout
+		select {
+		case path = <-s.downloads:
+			s.logger.Printf("Download completed: %s", path)
+			return nil
+		case <-time.After(s.config.DownloadTimeout):
+			return fmt.Errorf("%w after %v", ErrDownloadTimeout, s.config.DownloadTimeout)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// search opens the search form, applies the period and runs the search
+func (s *ETCScraper) search(ctx context.Context, dateRange DateRange) error {
 	// Navigate to search page (検索条件の指定)
 	s.logger.Println("Navigating to search page...")
-	searchPageLink := s.page.Locator("a:has-text('検索条件の指定')").First()
+	searchPageLink := s.locate(ElementSearchPageLink)
 	if err := searchPageLink.Click(LocatorClickOptions{}); err != nil {
 		// If link not found, we might already be on search page
 		s.logger.Println("Search link not found, assuming already on search page")
 	} else {
-		s.waitForNavigation()
+		s.waitForNavigation(ctx)
 		s.page.WaitForLoadState(PageWaitForLoadStateOptions{
 			State: LoadStateNetworkidle,
 		})
@@ -290,7 +634,7 @@ func (s *ETCScraper) DownloadMeisai(fromDate, toDate string) (string, error) {
 
 	// Select "全て" (All) radio button for 走行区分 (sokoKbn)
 	s.logger.Println("Selecting '全て' (All) option for 走行区分...")
-	allRadioButton := s.page.Locator("input[name='sokoKbn'][value='0']").First()
+	allRadioButton := s.locate(ElementRouteAll)
 
 	// Check if already selected
 	isChecked, err := allRadioButton.IsChecked(LocatorIsCheckedOptions{})
@@ -311,60 +655,78 @@ func (s *ETCScraper) DownloadMeisai(fromDate, toDate string) (string, error) {
 
 	// Click "この条件を記憶する" (Save this condition) button to save the search settings
 	s.logger.Println("Clicking 'この条件を記憶する' button to save search settings...")
-	saveButton := s.page.Locator("input[name='focusTarget_Save']").First()
+	saveButton := s.locate(ElementSaveConditionButton)
 	if err := saveButton.Click(LocatorClickOptions{}); err != nil {
 		s.logger.Printf("⚠️ Failed to click 'この条件を記憶する' button: %v", err)
 	} else {
 		s.logger.Println("✅ Search settings saved successfully!")
 		// Wait for save confirmation
-		s.waitForNavigation()
+		s.waitForNavigation(ctx)
 		s.page.WaitForLoadState(PageWaitForLoadStateOptions{
 			State: LoadStateNetworkidle,
 		})
 	}
 
-	// Click search button to execute search with current date range
+	// Fill 利用年月日 selectors with the requested period
+	if err := s.applyDateRange(dateRange); err != nil {
+		return err
+	}
+
+	// Click search button to execute search with the requested date range
 	s.logger.Println("Clicking search button...")
-	searchButton := s.page.Locator("input[name='focusTarget']").First()
+	searchButton := s.locate(ElementSearchButton)
 	if err := searchButton.Click(LocatorClickOptions{}); err != nil {
-		return "", fmt.Errorf("failed to click search button: %w", err)
+		return fmt.Errorf("failed to click search button: %w", err)
 	}
 
 	// Wait for results page to load
-	s.waitForNavigation()
+	s.waitForNavigation(ctx)
 	if err := s.page.WaitForLoadState(PageWaitForLoadStateOptions{
 		State: LoadStateNetworkidle,
 	}); err != nil {
-		return "", fmt.Errorf("failed to wait for search results: %w", err)
+		return fmt.Errorf("failed to wait for search results: %w", err)
 	}
 
-	// Check if there are any results
-	s.logger.Println("Checking for search results...")
-	resultCount, _ := s.page.Locator("input[name='hakkoMeisai']").Count()
-	s.logger.Printf("Found %d result items", resultCount)
+	// The site sends us back to the login form once the session has timed out
+	if s.count(ElementLoginUserID) > 0 {
+		return ErrSessionExpired
+	}
 
-	if resultCount == 0 {
-		s.logger.Println("⚠️ No search results found. CSV link may not be available.")
+	// Verify the result page shows the requested period
+	return s.verifyResultPeriod(dateRange)
+}
+
+// setupDownloadHandler registers the page's download handler the first time it is needed
+func (s *ETCScraper) setupDownloadHandler() {
+	if s.downloads != nil {
+		return
 	}
 
-	// Setup download handler
-	downloadComplete := make(chan string, 1)
+	s.downloads = make(chan string, 1)
 	s.logger.Println("Setting up download handler...")
 	s.page.On("download", func(download Download) {
 		s.logger.Println("📥 Download event triggered!")
-		s.HandleDownload(download, downloadComplete)
+		s.HandleDownload(download, s.downloads)
 	})
+}
+
+// clickCSVLink locates the CSV download link on the result page and clicks it
+func (s *ETCScraper) clickCSVLink(dateRange DateRange) (LocatorInterface, error) {
+	// Check if there are any results
+	s.logger.Println("Checking for search results...")
+	resultCount := s.count(ElementResultItem)
+	s.logger.Printf("Found %d result items", resultCount)
+
+	if resultCount == 0 {
+		s.logger.Println("⚠️ No search results found. CSV link may not be available.")
+	}
 
 	// Click CSV download link
 	s.logger.Println("Clicking CSV download link...")
 
-	// Try multiple selectors for CSV link
+	// Try the pack's selectors for the CSV link in order
 	// Note: onclick funccode varies by account type (1032500000 or other)
-	csvSelectors := []string{
-		"a:has-text('明細ＣＳＶ')",    // Text match (most reliable, ignores spacing)
-		"a[onclick*='goOutput'][onclick*='hakkoMeisai']", // goOutput function call
-		"a[onclick*='1032500000']",  // 明細CSV funccode (pattern 1)
-	}
+	csvSelectors := s.config.Selectors.Selectors(ElementCSVLink)
 
 	var csvLink LocatorInterface
 	var csvLinkCount int
@@ -380,25 +742,81 @@ func (s *ETCScraper) DownloadMeisai(fromDate, toDate string) (string, error) {
 	}
 
 	if csvLinkCount == 0 {
-		return "", fmt.Errorf("CSV download link not found with any selector - possibly no search results or different page structure")
+		if resultCount == 0 {
+			return nil, fmt.Errorf("%w %s: CSV download link not found with any selector", ErrNoResults, dateRange)
+		}
+		return nil, &SelectorError{
+			Element:   "CSV download link",
+			Selectors: csvSelectors,
+			Hint:      fmt.Sprintf("%d result items found, the page structure may have changed", resultCount),
+		}
 	}
 
 	s.logger.Println("CSV link located, attempting click...")
 	if err := csvLink.Click(LocatorClickOptions{}); err != nil {
-		return "", fmt.Errorf("failed to click CSV link: %w", err)
+		return nil, fmt.Errorf("failed to click CSV link: %w", err)
 	}
 	s.logger.Println("CSV link clicked successfully!")
+	return csvLink, nil
+}
 
-	s.logger.Println("Waiting for CSV download to complete...")
+// applyDateRange selects the requested period on the search form's year/month/day selectors
+func (s *ETCScraper) applyDateRange(dateRange DateRange) error {
+	s.logger.Printf("Setting search period: %s", dateRange)
+
+	fields := []struct {
+		element string
+		value   string
+	}{
+		{ElementFromYear, fmt.Sprintf("%04d", dateRange.From.Year())},
+		{ElementFromMonth, fmt.Sprintf("%02d", int(dateRange.From.Month()))},
+		{ElementFromDay, fmt.Sprintf("%02d", dateRange.From.Day())},
+		{ElementToYear, fmt.Sprintf("%04d", dateRange.To.Year())},
+		{ElementToMonth, fmt.Sprintf("%02d", int(dateRange.To.Month()))},
+		{ElementToDay, fmt.Sprintf("%02d", dateRange.To.Day())},
+	}
 
-	// Wait for download with timeout
-	select {
-	case path := <-downloadComplete:
-		s.logger.Printf("Download completed: %s", path)
-		return path, nil
-	case <-time.After(60 * time.Second):
-		return "", fmt.Errorf("download timeout after 60 seconds")
+	for _, field := range fields {
+		selector := s.config.Selectors.Selectors(field.element)[0]
+		selected, err := s.locate(field.element).SelectOption([]string{field.value}, LocatorSelectOptionOptions{})
+		if err != nil {
+			return &DateRangeError{
+				FromDate: dateRange.From.Format("2006-01-02"),
+				ToDate:   dateRange.To.Format("2006-01-02"),
+				Reason:   fmt.Sprintf("site could not select %s on %s: %v", field.value, selector, err),
+			}
+		}
+		if len(selected) == 0 {
+			return &DateRangeError{
+				FromDate: dateRange.From.Format("2006-01-02"),
+				ToDate:   dateRange.To.Format("2006-01-02"),
+				Reason:   fmt.Sprintf("site does not offer %s on %s", field.value, selector),
+			}
+		}
 	}
+
+	s.logger.Println("✅ Search period applied")
+	return nil
+}
+
+// verifyResultPeriod checks that the result page lists the period that was requested
+func (s *ETCScraper) verifyResultPeriod(dateRange DateRange) error {
+	if s.count(ElementResultPeriod) == 0 {
+		s.logger.Println("⚠️ Search period is not shown on result page, skipping verification")
+		return nil
+	}
+
+	text, err := s.locate(ElementResultPeriod).TextContent(LocatorTextContentOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to read search period from result page: %w", err)
+	}
+
+	if err := checkResultPeriod(dateRange, text); err != nil {
+		return err
+	}
+
+	s.logger.Printf("✅ Result page shows requested period: %s", dateRange)
+	return nil
 }
 
 // HandleDownload processes download events (exported for testing)
@@ -441,6 +859,32 @@ func (s *ETCScraper) HandleDownload(download Download, downloadComplete chan<- s
 	}()
 }
 
+// captureEvidence saves a full-page screenshot, the page HTML and the page's messages for a failed step
+func (s *ETCScraper) captureEvidence(step string, err error) string {
+	if s.page == nil {
+		return ""
+	}
+
+	evidence := newEvidence(step, err, s.page.URL())
+	evidence.Dialogs, evidence.Console = s.messages.snapshot()
+
+	screenshot, screenshotErr := s.page.Screenshot(PageScreenshotOptions{FullPage: true})
+	if screenshotErr != nil {
+		s.logger.Printf("Warning: could not take screenshot: %v", screenshotErr)
+	}
+	html, htmlErr := s.page.Content()
+	if htmlErr != nil {
+		s.logger.Printf("Warning: could not read page HTML: %v", htmlErr)
+	}
+
+	dir, writeErr := writeEvidence(s.config, s.logger, evidence, screenshot, html)
+	if writeErr != nil {
+		s.logger.Printf("Warning: could not save failure evidence: %v", writeErr)
+		return ""
+	}
+	return dir
+}
+
 // findElement tries multiple selectors and returns the first match
 func (s *ETCScraper) findElement(selectors []string) LocatorInterface {
 	for _, selector := range selectors {
@@ -454,16 +898,63 @@ func (s *ETCScraper) findElement(selectors []string) LocatorInterface {
 	return nil
 }
 
-// Removed takeScreenshot method - no longer needed
+// locate returns the first selector of element that matches the page.
+// If none matches, the first selector is used so the caller's action reports the failure.
+func (s *ETCScraper) locate(element string) LocatorInterface {
+	selectors := s.config.Selectors.Selectors(element)
+	if len(selectors) > 1 {
+		if locator := s.findElement(selectors); locator != nil {
+			return locator
+		}
+	}
+	return s.page.Locator(selectors[0]).First()
+}
+
+// count returns how often the first matching selector of element occurs on the page
+func (s *ETCScraper) count(element string) int {
+	for _, selector := range s.config.Selectors.Selectors(element) {
+		if count, err := s.page.Locator(selector).Count(); err == nil && count > 0 {
+			return count
+		}
+	}
+	return 0
+}
+
+// ValidateSelectors opens url (or stays on the current page when url is empty) and
+// reports which logical elements of the configured selector pack resolve there
+func (s *ETCScraper) ValidateSelectors(url string) ([]SelectorCheck, error) {
+	if s.page == nil {
+		return nil, fmt.Errorf("scraper not initialized")
+	}
+	if url != "" {
+		if _, err := s.page.Goto(url, PageGotoOptions{WaitUntil: WaitUntilStateNetworkidle}); err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", url, err)
+		}
+	}
+	return ValidateSelectors(s.page, s.config.Selectors), nil
+}
 
-// Close cleans up resources
+// Close cleans up resources. It is safe to call more than once and from another goroutine.
 func (s *ETCScraper) Close() error {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
 	if s.page != nil {
 		s.page.Close()
 	}
 	if s.context != nil {
 		s.context.Close()
 	}
+	if s.lease != nil {
+		// The browser belongs to the pool; only give the slot back
+		s.lease.Release(s.browserCrashed())
+		return nil
+	}
 	if s.browser != nil {
 		s.browser.Close()
 	}
@@ -472,6 +963,20 @@ func (s *ETCScraper) Close() error {
 	}
 	return nil
 }
+
+// browserCrashed reports whether the browser failed while this scraper was using it
+func (s *ETCScraper) browserCrashed() bool {
+	if s.contextFailed {
+		return true
+	}
+	for _, attempt := range s.steps.Attempts() {
+		if errors.Is(attempt.Err, ErrBrowserCrash) {
+			return true
+		}
+	}
+	return false
+}
+
 // ReadAndDeleteFile reads a file and deletes it (extracted for testing)
 func ReadAndDeleteFile(path string) ([]byte, error) {
 	data, err := os.ReadFile(path)
@@ -488,8 +993,13 @@ func ReadAndDeleteFile(path string) ([]byte, error) {
 
 // DownloadMeisaiToBuffer downloads ETC meisai data and returns it as a byte buffer
 func (s *ETCScraper) DownloadMeisaiToBuffer(fromDate, toDate string) ([]byte, error) {
+	return s.DownloadMeisaiToBufferContext(context.Background(), fromDate, toDate)
+}
+
+// DownloadMeisaiToBufferContext is DownloadMeisaiToBuffer with cancellation support
+func (s *ETCScraper) DownloadMeisaiToBufferContext(ctx context.Context, fromDate, toDate string) ([]byte, error) {
 	// Download CSV file
-	csvPath, err := s.DownloadMeisai(fromDate, toDate)
+	csvPath, err := s.DownloadMeisaiContext(ctx, fromDate, toDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download CSV: %w", err)
 	}
@@ -503,11 +1013,46 @@ func (s *ETCScraper) DownloadMeisaiToBuffer(fromDate, toDate string) ([]byte, er
 	return data, nil
 }
 
+// Attempts returns a copy of every step attempt recorded by this scraper
+func (s *ETCScraper) Attempts() []Attempt {
+	return s.steps.Attempts()
+}
+
 // waitForNavigation waits for page navigation (extracted for testing)
-func (s *ETCScraper) waitForNavigation() {
+func (s *ETCScraper) waitForNavigation(ctx context.Context) {
 	if !s.config.TestMode {
-		time.Sleep(3 * time.Second)
+		select {
+		case <-time.After(3 * time.Second):
+		case <-ctx.Done():
+		}
 	} else {
 		s.logger.Printf("TestMode: Skipping 3 second sleep")
 	}
 }
+
+// watchContext closes the page, context and browser as soon as ctx is done so that
+// blocked Playwright calls return promptly. The returned func stops the watcher.
+func (s *ETCScraper) watchContext(ctx context.Context) func() {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.logger.Printf("⚠️ Operation cancelled (%v), closing browser", ctx.Err())
+			s.Close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+// contextError reports the context error instead of the browser error when an operation was aborted
+func contextError(ctx context.Context, operation string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%s aborted: %w", operation, ctxErr)
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+package scraper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxEvidenceMessages caps the console and dialog messages kept for an evidence bundle
+const maxEvidenceMessages = 200
+
+// Files written into every evidence bundle directory
+const (
+	EvidenceManifestFile   = "evidence.json"
+	EvidenceScreenshotFile = "screenshot.png"
+	EvidenceHTMLFile       = "page.html"
+)
+
+// Evidence is the manifest of a failure evidence bundle
+type Evidence struct {
+	AccountID  string    `json:"account_id"`
+	Step       string    `json:"step"`
+	Error      string    `json:"error"`
+	URL        string    `json:"url"`
+	Element    string    `json:"element,omitempty"`
+	Selectors  []string  `json:"selectors,omitempty"`
+	Dialogs    []string  `json:"dialogs,omitempty"`
+	Console    []string  `json:"console,omitempty"`
+	Screenshot string    `json:"screenshot,omitempty"` // File name inside the bundle, empty if not captured
+	HTML       string    `json:"html,omitempty"`       // File name inside the bundle, empty if not captured
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+// EvidencePath returns the evidence bundle directory attached to err, if any
+func EvidencePath(err error) string {
+	var stepErr *StepError
+	if errors.As(err, &stepErr) {
+		return stepErr.Evidence
+	}
+	return ""
+}
+
+// pageLog keeps the most recent dialog and console messages of a page
+type pageLog struct {
+	mu      sync.Mutex
+	dialogs []string
+	console []string
+}
+
+func (l *pageLog) addDialog(message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.dialogs = appendCapped(l.dialogs, message)
+}
+
+func (l *pageLog) addConsole(message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.console = appendCapped(l.console, message)
+}
+
+// snapshot returns copies of the recorded dialog and console messages
+func (l *pageLog) snapshot() ([]string, []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.dialogs...), append([]string(nil), l.console...)
+}
+
+func appendCapped(messages []string, message string) []string {
+	messages = append(messages, message)
+	if len(messages) > maxEvidenceMessages {
+		messages = messages[len(messages)-maxEvidenceMessages:]
+	}
+	return messages
+}
+
+// newEvidence fills in the fields every backend knows about a failed step
+func newEvidence(step string, err error, url string) *Evidence {
+	evidence := &Evidence{
+		Step:       step,
+		Error:      err.Error(),
+		URL:        url,
+		CapturedAt: time.Now(),
+	}
+	var selectorErr *SelectorError
+	if errors.As(err, &selectorErr) {
+		evidence.Element = selectorErr.Element
+		evidence.Selectors = selectorErr.Selectors
+	}
+	return evidence
+}
+
+// writeEvidence writes the bundle into a new <session folder>/evidence/<time>_<account>_<step>_<random>
+// directory and returns it. Accounts share the session folder, so the name is made unique by os.MkdirTemp.
+// screenshot and html are skipped when empty.
+func writeEvidence(config *ScraperConfig, logger *log.Logger, evidence *Evidence, screenshot []byte, html string) (string, error) {
+	sessionFolder, err := prepareSessionFolder(config, logger)
+	if err != nil {
+		return "", err
+	}
+
+	evidence.AccountID = config.UserID
+	parent := filepath.Join(sessionFolder, "evidence")
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return "", fmt.Errorf("failed to create evidence folder: %w", err)
+	}
+	pattern := fmt.Sprintf("%s_%s_%s_*", evidence.CapturedAt.Format("20060102_150405.000"), evidenceNamePart(config.UserID), evidence.Step)
+	dir, err := os.MkdirTemp(parent, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create evidence folder: %w", err)
+	}
+
+	if len(screenshot) > 0 {
+		if err := os.WriteFile(filepath.Join(dir, EvidenceScreenshotFile), screenshot, 0644); err != nil {
+			return "", fmt.Errorf("failed to write screenshot: %w", err)
+		}
+		evidence.Screenshot = EvidenceScreenshotFile
+	}
+	if html != "" {
+		if err := os.WriteFile(filepath.Join(dir, EvidenceHTMLFile), []byte(html), 0644); err != nil {
+			return "", fmt.Errorf("failed to write page HTML: %w", err)
+		}
+		evidence.HTML = EvidenceHTMLFile
+	}
+
+	manifest, err := json.MarshalIndent(evidence, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode evidence: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, EvidenceManifestFile), manifest, 0644); err != nil {
+		return "", fmt.Errorf("failed to write evidence: %w", err)
+	}
+
+	logger.Printf("📁 Saved failure evidence: %s", dir)
+	return dir, nil
+}
+
+// evidenceNamePart makes an account ID safe to use in a directory name
+func evidenceNamePart(accountID string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, accountID)
+}
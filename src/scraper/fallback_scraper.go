@@ -0,0 +1,162 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"sync"
+)
+
+// FallbackScraper runs a primary scraper (normally the HTTP backend) and switches to a
+// second one (normally Playwright) the first time the primary reports ErrUnsupportedSite.
+// The fallback is created lazily, so no browser is started while the primary keeps working.
+type FallbackScraper struct {
+	primary     ContextScraper
+	newFallback func() (ContextScraper, error)
+	logger      *log.Logger
+
+	mu       sync.Mutex
+	fallback ContextScraper
+	loggedIn bool
+}
+
+// NewFallbackScraper creates a scraper that starts with primary and falls back to the scraper
+// returned by newFallback when a page is not supported by primary
+func NewFallbackScraper(primary ContextScraper, newFallback func() (ContextScraper, error), logger *log.Logger) *FallbackScraper {
+	if logger == nil {
+		logger = log.New(os.Stdout, "[SCRAPER] ", log.LstdFlags)
+	}
+	return &FallbackScraper{
+		primary:     primary,
+		newFallback: newFallback,
+		logger:      logger,
+	}
+}
+
+// Initialize initializes the active scraper
+func (f *FallbackScraper) Initialize() error {
+	return f.InitializeContext(context.Background())
+}
+
+// InitializeContext initializes the active scraper
+func (f *FallbackScraper) InitializeContext(ctx context.Context) error {
+	return f.active().InitializeContext(ctx)
+}
+
+// Login logs in with the active scraper
+func (f *FallbackScraper) Login() error {
+	return f.LoginContext(context.Background())
+}
+
+// LoginContext logs in with the active scraper, falling back if the login pages are not supported
+func (f *FallbackScraper) LoginContext(ctx context.Context) error {
+	err := f.call(ctx, false, func(s ContextScraper) error {
+		return s.LoginContext(ctx)
+	})
+	if err == nil {
+		f.mu.Lock()
+		f.loggedIn = true
+		f.mu.Unlock()
+	}
+	return err
+}
+
+// DownloadMeisai downloads with the active scraper
+func (f *FallbackScraper) DownloadMeisai(fromDate, toDate string) (string, error) {
+	return f.DownloadMeisaiContext(context.Background(), fromDate, toDate)
+}
+
+// DownloadMeisaiContext downloads with the active scraper, falling back if the result pages are not supported
+func (f *FallbackScraper) DownloadMeisaiContext(ctx context.Context, fromDate, toDate string) (string, error) {
+	var path string
+	err := f.call(ctx, true, func(s ContextScraper) error {
+		var err error
+		path, err = s.DownloadMeisaiContext(ctx, fromDate, toDate)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// call runs fn on the active scraper and, if the primary cannot handle the site, once more on the fallback
+func (f *FallbackScraper) call(ctx context.Context, relogin bool, fn func(s ContextScraper) error) error {
+	active := f.active()
+	err := fn(active)
+	if err == nil || !errors.Is(err, ErrUnsupportedSite) || active != f.primary {
+		return err
+	}
+
+	f.logger.Printf("⚠️ HTTP backend cannot handle the site (%v), switching to browser", err)
+	fallback, err := f.switchToFallback(ctx)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	loggedIn := f.loggedIn
+	f.mu.Unlock()
+	if relogin && loggedIn {
+		if err := fallback.LoginContext(ctx); err != nil {
+			return err
+		}
+	}
+	return fn(fallback)
+}
+
+// switchToFallback closes the primary scraper and initializes the fallback
+func (f *FallbackScraper) switchToFallback(ctx context.Context) (ContextScraper, error) {
+	if err := f.primary.Close(); err != nil {
+		f.logger.Printf("Warning: failed to close primary scraper: %v", err)
+	}
+
+	fallback, err := f.newFallback()
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.fallback = fallback
+	f.mu.Unlock()
+
+	if err := fallback.InitializeContext(ctx); err != nil {
+		return nil, err
+	}
+	return fallback, nil
+}
+
+// active returns the fallback once it has been created, otherwise the primary scraper
+func (f *FallbackScraper) active() ContextScraper {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.fallback != nil {
+		return f.fallback
+	}
+	return f.primary
+}
+
+// Attempts returns the attempts recorded by both scrapers, primary first
+func (f *FallbackScraper) Attempts() []Attempt {
+	attempts := AttemptsOf(f.primary)
+
+	f.mu.Lock()
+	fallback := f.fallback
+	f.mu.Unlock()
+	if fallback != nil {
+		attempts = append(attempts, AttemptsOf(fallback)...)
+	}
+	return attempts
+}
+
+// LogoutContext logs out of the active scraper
+func (f *FallbackScraper) LogoutContext(ctx context.Context) error {
+	return Logout(ctx, f.active())
+}
+
+// Close closes the active scraper (the primary is closed when switching)
+func (f *FallbackScraper) Close() error {
+	return f.active().Close()
+}
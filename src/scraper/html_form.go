@@ -0,0 +1,172 @@
+package scraper
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// htmlForm is a parsed <form> with the values a browser would submit by default
+type htmlForm struct {
+	action  string
+	method  string
+	values  url.Values
+	options map[string][]string // <select> name -> option values
+	submits map[string]string   // submit button name -> value
+}
+
+// findNodes returns all descendants of n that match
+func findNodes(n *html.Node, match func(*html.Node) bool) []*html.Node {
+	var found []*html.Node
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if match(node) {
+			found = append(found, node)
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return found
+}
+
+// isElement reports whether n is an element with the given tag name
+func isElement(n *html.Node, tag string) bool {
+	return n.Type == html.ElementNode && n.Data == tag
+}
+
+// attr returns the value of n's attribute, or "" if it is not set
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hasAttr reports whether n has the attribute key
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeText returns the text content of n with surrounding space trimmed
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	for _, text := range findNodes(n, func(node *html.Node) bool { return node.Type == html.TextNode }) {
+		b.WriteString(text.Data)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// findLink returns the first <a> whose text or href satisfies match
+func findLink(doc *html.Node, match func(text, href string) bool) *html.Node {
+	for _, link := range findNodes(doc, func(n *html.Node) bool { return isElement(n, "a") }) {
+		if match(nodeText(link), attr(link, "href")) {
+			return link
+		}
+	}
+	return nil
+}
+
+// hasField reports whether the document has an input, select or textarea named name
+func hasField(doc *html.Node, name string) bool {
+	return len(findFields(doc, name)) > 0
+}
+
+// findFields returns the form controls named name
+func findFields(doc *html.Node, name string) []*html.Node {
+	return findNodes(doc, func(n *html.Node) bool {
+		return (isElement(n, "input") || isElement(n, "select") || isElement(n, "textarea")) && attr(n, "name") == name
+	})
+}
+
+// findFormWithField parses the first form that contains a control named field
+func findFormWithField(doc *html.Node, field string) *htmlForm {
+	for _, form := range findNodes(doc, func(n *html.Node) bool { return isElement(n, "form") }) {
+		if hasField(form, field) {
+			return parseForm(form)
+		}
+	}
+	return nil
+}
+
+// parseForm collects the default values of the form's controls
+func parseForm(form *html.Node) *htmlForm {
+	parsed := &htmlForm{
+		action:  attr(form, "action"),
+		method:  strings.ToUpper(attr(form, "method")),
+		values:  url.Values{},
+		options: map[string][]string{},
+		submits: map[string]string{},
+	}
+	if parsed.method == "" {
+		parsed.method = "GET"
+	}
+
+	for _, control := range findNodes(form, func(n *html.Node) bool {
+		return isElement(n, "input") || isElement(n, "select") || isElement(n, "textarea")
+	}) {
+		name := attr(control, "name")
+		if name == "" {
+			continue
+		}
+
+		switch control.Data {
+		case "select":
+			var values []string
+			selected, explicit := "", false
+			for _, option := range findNodes(control, func(n *html.Node) bool { return isElement(n, "option") }) {
+				value := attr(option, "value")
+				if !hasAttr(option, "value") {
+					value = nodeText(option)
+				}
+				values = append(values, value)
+				// Browsers submit the selected option, or the first one if none is selected
+				if (hasAttr(option, "selected") && !explicit) || len(values) == 1 {
+					selected, explicit = value, hasAttr(option, "selected")
+				}
+			}
+			parsed.options[name] = values
+			if len(values) > 0 {
+				parsed.values.Set(name, selected)
+			}
+		case "textarea":
+			parsed.values.Add(name, nodeText(control))
+		default:
+			switch strings.ToLower(attr(control, "type")) {
+			case "submit", "image":
+				parsed.submits[name] = attr(control, "value")
+			case "button", "reset", "file":
+			case "checkbox", "radio":
+				if hasAttr(control, "checked") {
+					value := attr(control, "value")
+					if !hasAttr(control, "value") {
+						value = "on"
+					}
+					parsed.values.Add(name, value)
+				}
+			default:
+				parsed.values.Add(name, attr(control, "value"))
+			}
+		}
+	}
+	return parsed
+}
+
+// hasOption reports whether the <select> named name offers value
+func (f *htmlForm) hasOption(name, value string) bool {
+	for _, option := range f.options[name] {
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,650 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+)
+
+// goOutputPattern extracts the funccode passed to the result page's goOutput() handler
+var goOutputPattern = regexp.MustCompile(`goOutput\(\s*'(\d+)'`)
+
+// Attribute conditions of the selectors the HTTP scraper can follow
+var (
+	selectorNamePattern  = regexp.MustCompile(`\[name=['"]?([^'"\]]+)['"]?\]`)
+	selectorValuePattern = regexp.MustCompile(`\[value=['"]?([^'"\]]*)['"]?\]`)
+	selectorHrefPattern  = regexp.MustCompile(`^a\[href\*=['"]?([^'"\]]+)['"]?\]$`)
+	selectorIDPattern    = regexp.MustCompile(`^#([\w-]+)$`)
+)
+
+// HTTPScraper runs the login → search → CSV flow with net/http and a cookie jar instead of a browser.
+// Pages it cannot handle fail with ErrUnsupportedSite; NewFallbackScraper switches to the
+// Playwright backend in that case.
+//
+// Form controls, the login link and the result period are looked up through the same selector pack
+// as the Playwright backend (see formField, linkHref and elementID). The other links, messages and
+// the CSV link are matched in code instead: their selectors use Playwright-only syntax
+// (text=, :has-text()) that has no static HTML equivalent.
+type HTTPScraper struct {
+	config *ScraperConfig
+	logger *log.Logger
+	client *http.Client
+	page   *httpPage
+	steps  *stepRunner
+}
+
+// httpPage is the page the HTTP scraper is currently on
+type httpPage struct {
+	url  *url.URL
+	body []byte
+	doc  *html.Node
+	// encoding is the charset the page was served in; forms on it are sent back in the same charset
+	encoding encoding.Encoding
+}
+
+// NewHTTPScraper creates a browserless scraper
+func NewHTTPScraper(config *ScraperConfig, logger *log.Logger) (*HTTPScraper, error) {
+	applyConfigDefaults(config)
+
+	if logger == nil {
+		logger = log.New(os.Stdout, "[SCRAPER] ", log.LstdFlags)
+	}
+
+	s := &HTTPScraper{
+		config: config,
+		logger: logger,
+	}
+	s.steps = &stepRunner{config: config, logger: logger, relogin: s.login, capture: s.captureEvidence}
+	return s, nil
+}
+
+// Initialize sets up the HTTP client
+func (s *HTTPScraper) Initialize() error {
+	return s.InitializeContext(context.Background())
+}
+
+// InitializeContext sets up the HTTP client with a fresh cookie jar
+func (s *HTTPScraper) InitializeContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("initialization aborted: %w", err)
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return fmt.Errorf("could not create cookie jar: %w", err)
+	}
+	s.client = &http.Client{
+		Jar:     jar,
+		Timeout: time.Duration(s.config.Timeout) * time.Millisecond,
+	}
+
+	s.logger.Printf("HTTP scraper initialized with download path: %s", s.config.DownloadPath)
+	return nil
+}
+
+// Login performs login to ETC meisai service
+func (s *HTTPScraper) Login() error {
+	return s.LoginContext(context.Background())
+}
+
+// LoginContext performs login, aborting the in-flight request when ctx is done
+func (s *HTTPScraper) LoginContext(ctx context.Context) error {
+	return contextError(ctx, "login", s.login(ctx))
+}
+
+func (s *HTTPScraper) login(ctx context.Context) error {
+	if s.client == nil {
+		return fmt.Errorf("scraper not initialized")
+	}
+
+	if err := s.steps.run(ctx, StepNavigation, func(attempt int) error {
+		return s.gotoTopPage(ctx)
+	}); err != nil {
+		return err
+	}
+
+	return s.steps.run(ctx, StepLogin, func(attempt int) error {
+		if attempt > 1 {
+			// Start over from the top page after a failed attempt
+			if err := s.gotoTopPage(ctx); err != nil {
+				return err
+			}
+		}
+		return s.submitLogin(ctx)
+	})
+}
+
+// gotoTopPage opens the top page of the meisai service
+func (s *HTTPScraper) gotoTopPage(ctx context.Context) error {
+	s.logger.Printf("Navigating to %s", s.config.BaseURL)
+	if err := s.open(ctx, http.MethodGet, s.config.BaseURL, nil); err != nil {
+		return fmt.Errorf("failed to navigate to top page: %w", err)
+	}
+	return nil
+}
+
+// LogoutContext follows the logout link of the current page
+func (s *HTTPScraper) LogoutContext(ctx context.Context) error {
+	if s.page == nil {
+		return nil
+	}
+	logoutLink := findLink(s.page.doc, func(text, href string) bool { return strings.Contains(text, "ログアウト") })
+	if logoutLink == nil {
+		return nil
+	}
+	s.logger.Println("Logging out...")
+	if err := s.open(ctx, http.MethodGet, attr(logoutLink, "href"), nil); err != nil {
+		return fmt.Errorf("failed to log out: %w", err)
+	}
+	return nil
+}
+
+// submitLogin opens the login form from the top page and posts the credentials
+func (s *HTTPScraper) submitLogin(ctx context.Context) error {
+	loginHref, ok := s.linkHref(ElementLoginLink)
+	if !ok {
+		return s.unsupported("login link")
+	}
+	loginLink := findLink(s.page.doc, func(text, href string) bool {
+		return strings.Contains(href, loginHref)
+	})
+	if loginLink == nil {
+		return s.unsupported("login link")
+	}
+	if err := s.open(ctx, http.MethodGet, attr(loginLink, "href"), nil); err != nil {
+		return fmt.Errorf("failed to load login page: %w", err)
+	}
+
+	userIDField, _, hasUserID := s.formField(ElementLoginUserID)
+	passwordField, _, hasPassword := s.formField(ElementLoginPassword)
+	if !hasUserID || !hasPassword {
+		return s.unsupported("login form")
+	}
+	form := findFormWithField(s.page.doc, userIDField)
+	if form == nil || !hasField(s.page.doc, passwordField) {
+		return s.unsupported("login form")
+	}
+	password, err := loginPassword(ctx, s.config)
+	if err != nil {
+		return err
+	}
+	form.values.Set(userIDField, s.config.UserID)
+	form.values.Set(passwordField, password)
+
+	s.logger.Println("Submitting login form...")
+	if err := s.submit(ctx, form, nil); err != nil {
+		return fmt.Errorf("failed to submit login form: %w", err)
+	}
+
+	if findLink(s.page.doc, func(text, href string) bool { return strings.Contains(text, "ログアウト") }) != nil {
+		s.logger.Println("Login successful!")
+		return nil
+	}
+
+	// Check for error messages
+	for _, node := range findNodes(s.page.doc, func(n *html.Node) bool {
+		class := attr(n, "class")
+		return n.Type == html.ElementNode &&
+			(strings.Contains(class, "error-message") || strings.Contains(class, "alert-danger") || strings.Contains(class, "error"))
+	}) {
+		if message := nodeText(node); message != "" {
+			return newLoginError(message)
+		}
+	}
+	if s.onLoginForm() {
+		return newLoginError("login form was shown again")
+	}
+	return s.unsupported("logout link")
+}
+
+// DownloadMeisai downloads ETC meisai data for specified date range
+func (s *HTTPScraper) DownloadMeisai(fromDate, toDate string) (string, error) {
+	return s.DownloadMeisaiContext(context.Background(), fromDate, toDate)
+}
+
+// DownloadMeisaiContext downloads ETC meisai data, aborting the in-flight request when ctx is done
+func (s *HTTPScraper) DownloadMeisaiContext(ctx context.Context, fromDate, toDate string) (string, error) {
+	path, err := s.downloadMeisai(ctx, fromDate, toDate)
+	if err := contextError(ctx, "download", err); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func (s *HTTPScraper) downloadMeisai(ctx context.Context, fromDate, toDate string) (string, error) {
+	if s.client == nil {
+		return "", fmt.Errorf("scraper not initialized")
+	}
+
+	dateRange, err := ParseDateRange(fromDate, toDate)
+	if err != nil {
+		return "", err
+	}
+
+	s.logger.Printf("Downloading meisai from %s to %s", fromDate, toDate)
+
+	sessionFolder, err := prepareSessionFolder(s.config, s.logger)
+	if err != nil {
+		return "", err
+	}
+
+	// Search with the requested period (re-login happens here if the session expired)
+	if err := s.steps.run(ctx, StepSearch, func(attempt int) error {
+		return s.search(ctx, dateRange)
+	}); err != nil {
+		return "", err
+	}
+
+	var target string
+	var values url.Values
+	if err := s.steps.run(ctx, StepCSVLink, func(attempt int) error {
+		target, values, err = s.csvRequest(dateRange)
+		return err
+	}); err != nil {
+		return "", err
+	}
+
+	var path string
+	err = s.steps.run(ctx, StepDownloadSave, func(attempt int) error {
+		if attempt > 1 {
+			// The site keeps the result list in the session, so search again before retrying
+			if err := s.search(ctx, dateRange); err != nil {
+				return err
+			}
+			if target, values, err = s.csvRequest(dateRange); err != nil {
+				return err
+			}
+		}
+		path, err = s.saveCSV(ctx, target, values, sessionFolder)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// search opens the search form, applies the period and posts the search
+func (s *HTTPScraper) search(ctx context.Context, dateRange DateRange) error {
+	// Navigate to search page (検索条件の指定)
+	if link := findLink(s.page.doc, func(text, href string) bool { return strings.Contains(text, "検索条件の指定") }); link != nil {
+		if err := s.open(ctx, http.MethodGet, attr(link, "href"), nil); err != nil {
+			return fmt.Errorf("failed to load search page: %w", err)
+		}
+	}
+
+	routeField, routeAll, ok := s.formField(ElementRouteAll)
+	if !ok {
+		return s.unsupported("search form")
+	}
+	form := findFormWithField(s.page.doc, routeField)
+	if form == nil {
+		if s.onLoginForm() {
+			return ErrSessionExpired
+		}
+		return s.unsupported("search form")
+	}
+
+	// 走行区分: 全て
+	form.values.Set(routeField, routeAll)
+
+	fields := []struct {
+		element string
+		value   string
+	}{
+		{ElementFromYear, fmt.Sprintf("%04d", dateRange.From.Year())},
+		{ElementFromMonth, fmt.Sprintf("%02d", int(dateRange.From.Month()))},
+		{ElementFromDay, fmt.Sprintf("%02d", dateRange.From.Day())},
+		{ElementToYear, fmt.Sprintf("%04d", dateRange.To.Year())},
+		{ElementToMonth, fmt.Sprintf("%02d", int(dateRange.To.Month()))},
+		{ElementToDay, fmt.Sprintf("%02d", dateRange.To.Day())},
+	}
+	for _, field := range fields {
+		name, _, ok := s.formField(field.element)
+		if _, exists := form.options[name]; !ok || !exists {
+			return s.unsupported(field.element)
+		}
+		if !form.hasOption(name, field.value) {
+			return &DateRangeError{
+				FromDate: dateRange.From.Format("2006-01-02"),
+				ToDate:   dateRange.To.Format("2006-01-02"),
+				Reason:   fmt.Sprintf("site does not offer %s on select[name='%s']", field.value, name),
+			}
+		}
+		form.values.Set(name, field.value)
+	}
+
+	searchField, _, ok := s.formField(ElementSearchButton)
+	searchButton, exists := form.submits[searchField]
+	if !ok || !exists {
+		return s.unsupported("search button")
+	}
+
+	s.logger.Printf("Searching %s...", dateRange)
+	if err := s.submit(ctx, form, url.Values{searchField: {searchButton}}); err != nil {
+		return fmt.Errorf("failed to submit search: %w", err)
+	}
+
+	// The site sends us back to the login form once the session has timed out
+	if s.onLoginForm() {
+		return ErrSessionExpired
+	}
+
+	var period []*html.Node
+	if periodID, ok := s.elementID(ElementResultPeriod); ok {
+		period = findNodes(s.page.doc, func(n *html.Node) bool { return attr(n, "id") == periodID })
+	}
+	if len(period) == 0 {
+		s.logger.Println("⚠️ Search period is not shown on result page, skipping verification")
+		return nil
+	}
+	return checkResultPeriod(dateRange, nodeText(period[0]))
+}
+
+// csvRequest builds the request the result page's goOutput() handler would send
+func (s *HTTPScraper) csvRequest(dateRange DateRange) (string, url.Values, error) {
+	links := findNodes(s.page.doc, func(n *html.Node) bool {
+		return isElement(n, "a") && strings.Contains(attr(n, "onclick"), "goOutput")
+	})
+	resultField, _, hasResults := s.formField(ElementResultItem)
+	hasResults = hasResults && hasField(s.page.doc, resultField)
+	if len(links) == 0 {
+		if !hasResults {
+			return "", nil, fmt.Errorf("%w %s: CSV download link not found", ErrNoResults, dateRange)
+		}
+		return "", nil, s.unsupported("CSV download link")
+	}
+
+	match := goOutputPattern.FindStringSubmatch(attr(links[0], "onclick"))
+	if match == nil {
+		return "", nil, s.unsupported("goOutput funccode")
+	}
+
+	// goOutput() posts the result list form to its action with the funccode swapped
+	values := url.Values{}
+	action := s.page.url.String()
+	if hasResults {
+		if form := findFormWithField(s.page.doc, resultField); form != nil {
+			values = form.values
+			if form.action != "" {
+				action = form.action
+			}
+		}
+	}
+
+	target, err := s.resolve(action)
+	if err != nil {
+		return "", nil, err
+	}
+	query := target.Query()
+	query.Set("funccode", match[1])
+	target.RawQuery = query.Encode()
+	return target.String(), values, nil
+}
+
+// saveCSV posts the CSV request and saves the attachment into sessionFolder
+func (s *HTTPScraper) saveCSV(ctx context.Context, target string, values url.Values, sessionFolder string) (string, error) {
+	resp, err := s.do(ctx, http.MethodPost, target, values)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return "", fmt.Errorf("%w: %v", ErrDownloadTimeout, err)
+		}
+		return "", fmt.Errorf("failed to request CSV: %w", err)
+	}
+	defer resp.Body.Close()
+
+	disposition, params, _ := mime.ParseMediaType(resp.Header.Get("Content-Disposition"))
+	if disposition != "attachment" {
+		if err := s.setPage(resp); err != nil {
+			return "", err
+		}
+		if s.onLoginForm() {
+			return "", ErrSessionExpired
+		}
+		return "", s.unsupported("CSV attachment")
+	}
+
+	filename := filepath.Base(params["filename"])
+	if filename == "." || filename == string(filepath.Separator) {
+		filename = "meisai.csv"
+	}
+	downloadPath := filepath.Join(sessionFolder, s.config.UserID+"_"+filename)
+
+	file, err := os.Create(downloadPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to save download: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return "", fmt.Errorf("%w: %v", ErrDownloadTimeout, err)
+		}
+		return "", fmt.Errorf("failed to save download: %w", err)
+	}
+
+	s.logger.Printf("✅ File saved successfully: %s", downloadPath)
+	return downloadPath, nil
+}
+
+// submit sends form the way a browser would, adding extra (e.g. the clicked submit button)
+func (s *HTTPScraper) submit(ctx context.Context, form *htmlForm, extra url.Values) error {
+	values := url.Values{}
+	for name, value := range form.values {
+		values[name] = append([]string(nil), value...)
+	}
+	for name, value := range extra {
+		values[name] = value
+	}
+
+	action := form.action
+	if action == "" {
+		action = s.page.url.String()
+	}
+	return s.open(ctx, form.method, action, values)
+}
+
+// open requests ref (resolved against the current page) and makes the response the current page
+func (s *HTTPScraper) open(ctx context.Context, method, ref string, values url.Values) error {
+	resp, err := s.do(ctx, method, ref, values)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return s.setPage(resp)
+}
+
+// do sends a request for ref, resolved against the current page
+func (s *HTTPScraper) do(ctx context.Context, method, ref string, values url.Values) (*http.Response, error) {
+	target, err := s.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// A browser sends the form in the charset of the page it is on
+	if values, err = s.encodeValues(values); err != nil {
+		return nil, err
+	}
+	var body io.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader(values.Encode())
+	} else if values != nil {
+		query := target.Query()
+		for name, value := range values {
+			query[name] = value
+		}
+		target.RawQuery = query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", s.config.UserAgent)
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return s.client.Do(req)
+}
+
+// encodeValues converts the names and values of a form to the charset of the current page.
+// Characters the charset cannot represent are sent as HTML character references, as browsers do.
+func (s *HTTPScraper) encodeValues(values url.Values) (url.Values, error) {
+	if values == nil || s.page == nil || s.page.encoding == nil {
+		return values, nil
+	}
+	encoder := encoding.HTMLEscapeUnsupported(s.page.encoding.NewEncoder())
+	encoded := make(url.Values, len(values))
+	for name, list := range values {
+		encodedName, err := encoder.String(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode form field %s: %w", name, err)
+		}
+		for _, value := range list {
+			encodedValue, err := encoder.String(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode form field %s: %w", name, err)
+			}
+			encoded[encodedName] = append(encoded[encodedName], encodedValue)
+		}
+	}
+	return encoded, nil
+}
+
+// setPage reads resp as the current page, decoded to UTF-8 (the site serves Shift_JIS)
+func (s *HTTPScraper) setPage(resp *http.Response) error {
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", resp.Request.URL, err)
+	}
+	pageEncoding, _, _ := charset.DetermineEncoding(raw, resp.Header.Get("Content-Type"))
+	body, err := pageEncoding.NewDecoder().Bytes(raw)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", resp.Request.URL, err)
+	}
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", resp.Request.URL, err)
+	}
+	s.page = &httpPage{url: resp.Request.URL, body: body, doc: doc, encoding: pageEncoding}
+
+	if maintenancePattern.Match(body) {
+		return ErrSiteMaintenance
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("server temporarily unavailable (%s) for %s", resp.Status, resp.Request.URL)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status %s for %s", resp.Status, resp.Request.URL)
+	}
+	return nil
+}
+
+// formField returns the control name, and the value if the selector fixes one, from the first of
+// element's selectors in the selector pack that addresses a form control by name
+// (e.g. "input[name='sokoKbn'][value='0']"). ok is false when none of them does.
+func (s *HTTPScraper) formField(element string) (name, value string, ok bool) {
+	for _, selector := range s.config.Selectors.Selectors(element) {
+		match := selectorNamePattern.FindStringSubmatch(selector)
+		if match == nil {
+			continue
+		}
+		if valueMatch := selectorValuePattern.FindStringSubmatch(selector); valueMatch != nil {
+			value = valueMatch[1]
+		}
+		return match[1], value, true
+	}
+	return "", "", false
+}
+
+// linkHref returns the href fragment from the first of element's selectors in the selector pack
+// that matches a link by its href (e.g. "a[href*='funccode=1013000000']")
+func (s *HTTPScraper) linkHref(element string) (string, bool) {
+	for _, selector := range s.config.Selectors.Selectors(element) {
+		if match := selectorHrefPattern.FindStringSubmatch(selector); match != nil {
+			return match[1], true
+		}
+	}
+	return "", false
+}
+
+// elementID returns the id from the first of element's selectors in the selector pack that is a
+// plain id selector (e.g. "#searchPeriod")
+func (s *HTTPScraper) elementID(element string) (string, bool) {
+	for _, selector := range s.config.Selectors.Selectors(element) {
+		if match := selectorIDPattern.FindStringSubmatch(selector); match != nil {
+			return match[1], true
+		}
+	}
+	return "", false
+}
+
+// onLoginForm reports whether the current page shows the login form
+func (s *HTTPScraper) onLoginForm() bool {
+	name, _, ok := s.formField(ElementLoginUserID)
+	return ok && hasField(s.page.doc, name)
+}
+
+// resolve turns ref into an absolute URL relative to the current page
+func (s *HTTPScraper) resolve(ref string) (*url.URL, error) {
+	if s.page == nil {
+		return url.Parse(ref)
+	}
+	return s.page.url.Parse(ref)
+}
+
+// captureEvidence saves the HTML of the current page for a failed step
+func (s *HTTPScraper) captureEvidence(step string, err error) string {
+	if s.page == nil {
+		return ""
+	}
+
+	evidence := newEvidence(step, err, s.page.url.String())
+	dir, writeErr := writeEvidence(s.config, s.logger, evidence, nil, string(s.page.body))
+	if writeErr != nil {
+		s.logger.Printf("Warning: could not save failure evidence: %v", writeErr)
+		return ""
+	}
+	return dir
+}
+
+// unsupported reports a page element the HTTP backend could not find
+func (s *HTTPScraper) unsupported(element string) error {
+	location := ""
+	if s.page != nil {
+		location = " on " + s.page.url.String()
+	}
+	return fmt.Errorf("%w: %s not found%s", ErrUnsupportedSite, element, location)
+}
+
+// Attempts returns a copy of every step attempt recorded by this scraper
+func (s *HTTPScraper) Attempts() []Attempt {
+	return s.steps.Attempts()
+}
+
+// Close releases idle connections
+func (s *HTTPScraper) Close() error {
+	if s.client != nil {
+		s.client.CloseIdleConnections()
+	}
+	return nil
+}
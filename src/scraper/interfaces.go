@@ -1,9 +1,40 @@
 package scraper
 
+import "context"
+
 // ScraperInterface defines the interface for ETC scraping operations
 type ScraperInterface interface {
 	Initialize() error
 	Login() error
 	DownloadMeisai(fromDate, toDate string) (string, error)
 	Close() error
-}
\ No newline at end of file
+}
+
+// ContextScraper extends ScraperInterface with variants that honor cancellation and deadlines
+type ContextScraper interface {
+	ScraperInterface
+	InitializeContext(ctx context.Context) error
+	LoginContext(ctx context.Context) error
+	DownloadMeisaiContext(ctx context.Context, fromDate, toDate string) (string, error)
+}
+
+// BufferScraper is implemented by scrapers that return the downloaded CSV in memory instead of a file path
+type BufferScraper interface {
+	DownloadMeisaiToBufferContext(ctx context.Context, fromDate, toDate string) ([]byte, error)
+}
+
+// LogoutScraper is implemented by scrapers that can end the site session explicitly
+type LogoutScraper interface {
+	LogoutContext(ctx context.Context) error
+}
+
+// Logout ends the site session of s. Scrapers without LogoutScraper are left to Close.
+func Logout(ctx context.Context, s ScraperInterface) error {
+	if adapter, ok := s.(*contextAdapter); ok {
+		s = adapter.ScraperInterface
+	}
+	if ls, ok := s.(LogoutScraper); ok {
+		return ls.LogoutContext(ctx)
+	}
+	return nil
+}
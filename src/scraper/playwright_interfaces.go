@@ -19,6 +19,7 @@ type BrowserNewContextOptions struct {
 	AcceptDownloads *bool
 	Viewport        *Size
 	UserAgent       *string
+	StorageState    []byte // Storage state JSON (cookies, localStorage) restored into the context
 }
 
 // PageGotoOptions represents page navigation options
@@ -33,7 +34,8 @@ type PageWaitForLoadStateOptions struct {
 
 // PageScreenshotOptions represents screenshot options
 type PageScreenshotOptions struct {
-	Path string
+	Path     string // Also write the image to this file when set
+	FullPage bool
 }
 
 // LocatorClickOptions represents click options
@@ -48,6 +50,9 @@ type LocatorCheckOptions struct{}
 // LocatorIsCheckedOptions represents is checked options
 type LocatorIsCheckedOptions struct{}
 
+// LocatorSelectOptionOptions represents select option options
+type LocatorSelectOptionOptions struct{}
+
 // WaitUntilState represents wait until states
 type WaitUntilState string
 
@@ -85,6 +90,7 @@ type BrowserTypeInterface interface {
 // BrowserInterface wraps playwright.Browser for mocking
 type BrowserInterface interface {
 	NewContext(options BrowserNewContextOptions) (BrowserContextInterface, error)
+	IsConnected() bool
 	Close() error
 }
 
@@ -92,6 +98,7 @@ type BrowserInterface interface {
 type BrowserContextInterface interface {
 	NewPage() (PageInterface, error)
 	SetDefaultTimeout(timeout float64)
+	StorageState() ([]byte, error)
 	Close() error
 	On(event string, handler interface{})
 }
@@ -99,9 +106,11 @@ type BrowserContextInterface interface {
 // PageInterface wraps playwright.Page for mocking
 type PageInterface interface {
 	Goto(url string, options PageGotoOptions) (Response, error)
+	URL() string
 	Locator(selector string) LocatorInterface
 	WaitForLoadState(options PageWaitForLoadStateOptions) error
 	Screenshot(options PageScreenshotOptions) ([]byte, error)
+	Content() (string, error)
 	Close() error
 	On(event string, handler interface{})
 	Evaluate(expression string, arg ...interface{}) (interface{}, error)
@@ -116,6 +125,7 @@ type LocatorInterface interface {
 	TextContent(options LocatorTextContentOptions) (string, error)
 	Check(options LocatorCheckOptions) error
 	IsChecked(options LocatorIsCheckedOptions) (bool, error)
+	SelectOption(values []string, options LocatorSelectOptionOptions) ([]string, error)
 }
 
 // Helper functions for creating option structs
@@ -1,6 +1,9 @@
 package scraper
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/playwright-community/playwright-go"
 )
 
@@ -69,6 +72,13 @@ func (r *RealBrowser) NewContext(options BrowserNewContextOptions) (BrowserConte
 	if options.UserAgent != nil {
 		opts.UserAgent = options.UserAgent
 	}
+	if len(options.StorageState) > 0 {
+		var state playwright.StorageState
+		if err := json.Unmarshal(options.StorageState, &state); err != nil {
+			return nil, fmt.Errorf("invalid storage state: %w", err)
+		}
+		opts.StorageState = state.ToOptionalStorageState()
+	}
 
 	ctx, err := r.browser.NewContext(opts)
 	if err != nil {
@@ -77,6 +87,10 @@ func (r *RealBrowser) NewContext(options BrowserNewContextOptions) (BrowserConte
 	return &RealBrowserContext{context: ctx}, nil
 }
 
+func (r *RealBrowser) IsConnected() bool {
+	return r.browser.IsConnected()
+}
+
 func (r *RealBrowser) Close() error {
 	return r.browser.Close()
 }
@@ -98,6 +112,14 @@ func (r *RealBrowserContext) SetDefaultTimeout(timeout float64) {
 	r.context.SetDefaultTimeout(timeout)
 }
 
+func (r *RealBrowserContext) StorageState() ([]byte, error) {
+	state, err := r.context.StorageState()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(state)
+}
+
 func (r *RealBrowserContext) Close() error {
 	return r.context.Close()
 }
@@ -120,6 +142,10 @@ func (r *RealPage) Goto(url string, options PageGotoOptions) (Response, error) {
 	return r.page.Goto(url, opts)
 }
 
+func (r *RealPage) URL() string {
+	return r.page.URL()
+}
+
 func (r *RealPage) Locator(selector string) LocatorInterface {
 	return &RealLocator{locator: r.page.Locator(selector)}
 }
@@ -133,9 +159,17 @@ func (r *RealPage) WaitForLoadState(options PageWaitForLoadStateOptions) error {
 }
 
 func (r *RealPage) Screenshot(options PageScreenshotOptions) ([]byte, error) {
-	return r.page.Screenshot(playwright.PageScreenshotOptions{
-		Path: &options.Path,
-	})
+	opts := playwright.PageScreenshotOptions{
+		FullPage: playwright.Bool(options.FullPage),
+	}
+	if options.Path != "" {
+		opts.Path = &options.Path
+	}
+	return r.page.Screenshot(opts)
+}
+
+func (r *RealPage) Content() (string, error) {
+	return r.page.Content()
 }
 
 func (r *RealPage) Close() error {
@@ -156,6 +190,12 @@ func (r *RealPage) On(event string, handler interface{}) {
 				fn(d)
 			}
 		})
+	} else if event == "console" {
+		r.page.OnConsole(func(m playwright.ConsoleMessage) {
+			if fn, ok := handler.(func(string)); ok {
+				fn(fmt.Sprintf("[%s] %s", m.Type(), m.Text()))
+			}
+		})
 	}
 }
 
@@ -196,6 +236,10 @@ func (r *RealLocator) IsChecked(options LocatorIsCheckedOptions) (bool, error) {
 	return r.locator.IsChecked()
 }
 
+func (r *RealLocator) SelectOption(values []string, options LocatorSelectOptionOptions) ([]string, error) {
+	return r.locator.SelectOption(playwright.SelectOptionValues{Values: &values})
+}
+
 // RealDownload wraps playwright.Download
 type RealDownload struct {
 	download playwright.Download
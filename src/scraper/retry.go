@@ -0,0 +1,267 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Step names recorded in Attempt.Step
+const (
+	StepNavigation   = "navigation"
+	StepLogin        = "login"
+	StepSearch       = "search"
+	StepCSVLink      = "csv_link"
+	StepDownloadSave = "download_save"
+)
+
+// ErrorClass tells the retry engine how to react to a failed step
+type ErrorClass int
+
+const (
+	// ErrorClassPermanent failures are returned immediately (bad credentials, no results, ...)
+	ErrorClassPermanent ErrorClass = iota
+	// ErrorClassTransient failures are retried with backoff (timeouts, network-idle failures, ...)
+	ErrorClassTransient
+	// ErrorClassSessionExpired failures trigger a re-login before the step is retried
+	ErrorClassSessionExpired
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassTransient:
+		return "transient"
+	case ErrorClassSessionExpired:
+		return "session_expired"
+	default:
+		return "permanent"
+	}
+}
+
+// permanentErrors are never retried, even if their message looks like a timeout
+var permanentErrors = []error{
+	ErrInvalidCredentials,
+	ErrAccountLocked,
+	ErrPasswordExpired,
+	ErrSiteMaintenance,
+	ErrNoResults,
+	ErrSelectorDrift,
+	ErrBrowserCrash,
+	ErrUnsupportedSite,
+}
+
+// transientMarkers are substrings of Playwright/network errors that are worth retrying
+var transientMarkers = []string{
+	"timeout",
+	"networkidle",
+	"net::err_",
+	"connection reset",
+	"connection refused",
+	"econnreset",
+	"navigation interrupted",
+	"temporarily unavailable",
+}
+
+// ClassifyError decides whether a step error is transient, permanent or an expired session
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassPermanent
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassPermanent
+	}
+	if errors.Is(err, ErrSessionExpired) {
+		return ErrorClassSessionExpired
+	}
+	if errors.Is(err, ErrDownloadTimeout) {
+		return ErrorClassTransient
+	}
+	for _, permanent := range permanentErrors {
+		if errors.Is(err, permanent) {
+			return ErrorClassPermanent
+		}
+	}
+	var rangeErr *DateRangeError
+	if errors.As(err, &rangeErr) {
+		return ErrorClassPermanent
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, marker := range transientMarkers {
+		if strings.Contains(msg, marker) {
+			return ErrorClassTransient
+		}
+	}
+	return ErrorClassPermanent
+}
+
+// RetryPolicy controls how often and how fast a failed step is retried
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64 // Fraction of the backoff randomized in both directions (0-1)
+}
+
+// Backoff returns the wait before the attempt following the given (1-based) attempt
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(backoff)
+}
+
+// Attempt records a single execution of a scraper step
+type Attempt struct {
+	Step      string
+	Number    int
+	StartedAt time.Time
+	Duration  time.Duration
+	Err       error
+	Class     ErrorClass
+}
+
+// AttemptRecorder is implemented by scrapers that keep a log of step attempts
+type AttemptRecorder interface {
+	Attempts() []Attempt
+}
+
+// AttemptsOf returns the attempts recorded by s, or nil if it does not record them
+func AttemptsOf(s ScraperInterface) []Attempt {
+	if adapter, ok := s.(*contextAdapter); ok {
+		s = adapter.ScraperInterface
+	}
+	if recorder, ok := s.(AttemptRecorder); ok {
+		return recorder.Attempts()
+	}
+	return nil
+}
+
+// RetryCount returns how many of the attempts were retries
+func RetryCount(attempts []Attempt) int {
+	count := 0
+	for _, attempt := range attempts {
+		if attempt.Number > 1 {
+			count++
+		}
+	}
+	return count
+}
+
+// stepRunner retries scraper steps according to the config and records every attempt.
+// relogin is called before retrying a step that failed with an expired session;
+// capture, if set, saves failure evidence once a step has failed for good.
+type stepRunner struct {
+	config  *ScraperConfig
+	logger  *log.Logger
+	relogin func(ctx context.Context) error
+	capture func(step string, err error) string
+
+	mu       sync.Mutex
+	attempts []Attempt
+}
+
+// Attempts returns a copy of the recorded attempts
+func (r *stepRunner) Attempts() []Attempt {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts := make([]Attempt, len(r.attempts))
+	copy(attempts, r.attempts)
+	return attempts
+}
+
+// policy builds the step retry policy from the scraper configuration
+func (r *stepRunner) policy() RetryPolicy {
+	maxAttempts := r.config.RetryCount + 1
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: r.config.RetryBackoff,
+		MaxBackoff:     r.config.RetryMaxBackoff,
+		Multiplier:     2,
+		Jitter:         r.config.RetryJitter,
+	}
+}
+
+// run runs fn until it succeeds, fails permanently or runs out of attempts.
+// An expired session is handled by logging in again before the next attempt.
+// Every attempt first waits for the configured rate limiter.
+func (r *stepRunner) run(ctx context.Context, step string, fn func(attempt int) error) error {
+	policy := r.policy()
+
+	for attempt := 1; ; attempt++ {
+		if err := r.config.Limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		startedAt := time.Now()
+		err := fn(attempt)
+		if ctx.Err() == nil {
+			err = wrapBrowserCrash(err)
+		}
+		class := ClassifyError(err)
+
+		r.mu.Lock()
+		r.attempts = append(r.attempts, Attempt{
+			Step:      step,
+			Number:    attempt,
+			StartedAt: startedAt,
+			Duration:  time.Since(startedAt),
+			Err:       err,
+			Class:     class,
+		})
+		r.mu.Unlock()
+
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		if class == ErrorClassPermanent || attempt >= policy.MaxAttempts {
+			stepErr := &StepError{Step: step, Attempts: attempt, Err: err}
+			if r.capture != nil {
+				stepErr.Evidence = r.capture(step, err)
+			}
+			return stepErr
+		}
+
+		if class == ErrorClassSessionExpired {
+			r.logger.Printf("⚠️ Session expired during %s, logging in again", step)
+			if err := r.relogin(ctx); err != nil {
+				return fmt.Errorf("re-login after expired session failed: %w", err)
+			}
+			continue
+		}
+
+		backoff := policy.Backoff(attempt)
+		r.logger.Printf("⚠️ %s attempt %d/%d failed (%v), retrying in %v", step, attempt, policy.MaxAttempts, err, backoff)
+		if r.config.TestMode {
+			continue
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrSecretNotFound is returned by a SecretProvider that holds no password for the account
+var ErrSecretNotFound = errors.New("no password stored for account")
+
+// SecretProvider supplies account passwords from a credential store.
+// The scrapers ask for the password right before submitting the login form and do not keep it,
+// so it never sits in ScraperConfig or shows up in logs.
+type SecretProvider interface {
+	Password(ctx context.Context, accountID string) (string, error)
+}
+
+// loginPassword returns the password to submit for config.UserID
+func loginPassword(ctx context.Context, config *ScraperConfig) (string, error) {
+	if config.Secrets == nil {
+		return config.Password, nil
+	}
+	password, err := config.Secrets.Password(ctx, config.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up password for account %s: %w", config.UserID, err)
+	}
+	return password, nil
+}
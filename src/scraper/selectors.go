@@ -0,0 +1,141 @@
+package scraper
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Logical page elements looked up through a SelectorPack
+const (
+	ElementMaintenanceNotice   = "maintenance_notice"
+	ElementLoginLink           = "login_link"
+	ElementLoginUserID         = "login_user_id"
+	ElementLoginPassword       = "login_password"
+	ElementLoginButton         = "login_button"
+	ElementLoginError          = "login_error"
+	ElementLogoutLink          = "logout_link"
+	ElementSearchPageLink      = "search_page_link"
+	ElementRouteAll            = "route_all"
+	ElementSaveConditionButton = "save_condition_button"
+	ElementFromYear            = "from_year"
+	ElementFromMonth           = "from_month"
+	ElementFromDay             = "from_day"
+	ElementToYear              = "to_year"
+	ElementToMonth             = "to_month"
+	ElementToDay               = "to_day"
+	ElementSearchButton        = "search_button"
+	ElementResultPeriod        = "result_period"
+	ElementResultItem          = "result_item"
+	ElementCSVLink             = "csv_link"
+)
+
+// Elements lists every logical element the scraper uses, in the order of the flow
+var Elements = []string{
+	ElementMaintenanceNotice,
+	ElementLoginLink, ElementLoginUserID, ElementLoginPassword, ElementLoginButton, ElementLoginError, ElementLogoutLink,
+	ElementSearchPageLink, ElementRouteAll, ElementSaveConditionButton,
+	ElementFromYear, ElementFromMonth, ElementFromDay, ElementToYear, ElementToMonth, ElementToDay,
+	ElementSearchButton, ElementResultPeriod, ElementResultItem, ElementCSVLink,
+}
+
+//go:embed selectors/default.json
+var defaultSelectorPackJSON []byte
+
+// SelectorPack maps each logical element to CSS/Playwright selectors, tried in order
+type SelectorPack struct {
+	Version  string              `json:"version"`
+	Site     string              `json:"site,omitempty"`
+	Elements map[string][]string `json:"elements"`
+}
+
+// DefaultSelectorPack returns the pack built into the binary
+func DefaultSelectorPack() *SelectorPack {
+	var pack SelectorPack
+	if err := json.Unmarshal(defaultSelectorPackJSON, &pack); err != nil {
+		panic(fmt.Sprintf("embedded selector pack is invalid: %v", err))
+	}
+	return &pack
+}
+
+// LoadSelectorPack reads a JSON selector pack from path
+func LoadSelectorPack(path string) (*SelectorPack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read selector pack: %w", err)
+	}
+	return ParseSelectorPack(data)
+}
+
+// ParseSelectorPack decodes and validates a JSON selector pack.
+// Elements the pack does not list keep the selectors of the default pack.
+func ParseSelectorPack(data []byte) (*SelectorPack, error) {
+	var pack SelectorPack
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return nil, fmt.Errorf("invalid selector pack: %w", err)
+	}
+	if pack.Version == "" {
+		return nil, fmt.Errorf("invalid selector pack: version is required")
+	}
+
+	known := make(map[string]bool, len(Elements))
+	for _, element := range Elements {
+		known[element] = true
+	}
+	var names []string
+	for name := range pack.Elements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !known[name] {
+			return nil, fmt.Errorf("invalid selector pack %s: unknown element %q", pack.Version, name)
+		}
+		if len(pack.Elements[name]) == 0 {
+			return nil, fmt.Errorf("invalid selector pack %s: element %q has no selectors", pack.Version, name)
+		}
+	}
+
+	if pack.Elements == nil {
+		pack.Elements = map[string][]string{}
+	}
+	for name, selectors := range DefaultSelectorPack().Elements {
+		if _, exists := pack.Elements[name]; !exists {
+			pack.Elements[name] = selectors
+		}
+	}
+	return &pack, nil
+}
+
+// Selectors returns the ordered selectors for element
+func (p *SelectorPack) Selectors(element string) []string {
+	return p.Elements[element]
+}
+
+// SelectorCheck reports whether a logical element resolved on a page
+type SelectorCheck struct {
+	Element  string
+	Selector string // First selector that matched, empty if none did
+	Count    int
+	Resolved bool
+}
+
+// ValidateSelectors checks every element of pack against page
+func ValidateSelectors(page PageInterface, pack *SelectorPack) []SelectorCheck {
+	checks := make([]SelectorCheck, 0, len(Elements))
+	for _, element := range Elements {
+		check := SelectorCheck{Element: element}
+		for _, selector := range pack.Selectors(element) {
+			if count, err := page.Locator(selector).Count(); err == nil && count > 0 {
+				check.Selector = selector
+				check.Count = count
+				check.Resolved = true
+				break
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
@@ -0,0 +1,121 @@
+package scraper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/cryptoutil"
+)
+
+// DefaultSessionTTL is how long a saved login session is reused when no TTL is configured
+const DefaultSessionTTL = 30 * time.Minute
+
+// SavedSession is the browser state captured after a successful login
+type SavedSession struct {
+	UserID  string          `json:"user_id"`
+	State   json.RawMessage `json:"state"` // Playwright storage state (cookies, localStorage)
+	URL     string          `json:"url"`   // Page shown after login, reopened to check the session is still valid
+	SavedAt time.Time       `json:"saved_at"`
+}
+
+// SessionStore keeps one saved session per account, encrypted with AES-256-GCM.
+// File names are derived from a hash of the user ID so the directory does not reveal accounts.
+type SessionStore struct {
+	dir string
+	key []byte
+	ttl time.Duration
+}
+
+// NewSessionStore creates a store in dir. key must be 32 bytes; ttl <= 0 uses DefaultSessionTTL.
+func NewSessionStore(dir string, key []byte, ttl time.Duration) (*SessionStore, error) {
+	if len(key) != cryptoutil.KeySize {
+		return nil, cryptoutil.ErrInvalidKey
+	}
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+	return &SessionStore{dir: dir, key: key, ttl: ttl}, nil
+}
+
+// TTL returns how long saved sessions are reused
+func (s *SessionStore) TTL() time.Duration {
+	return s.ttl
+}
+
+// Load returns the saved session for userID, or nil if there is none or it has expired
+func (s *SessionStore) Load(userID string) (*SavedSession, error) {
+	sealed, err := os.ReadFile(s.path(userID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved session: %w", err)
+	}
+
+	data, err := cryptoutil.Open(s.key, sealed)
+	if err != nil {
+		s.Delete(userID)
+		return nil, fmt.Errorf("failed to decrypt saved session: %w", err)
+	}
+
+	var session SavedSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		s.Delete(userID)
+		return nil, fmt.Errorf("failed to decode saved session: %w", err)
+	}
+	if session.UserID != userID || time.Since(session.SavedAt) > s.ttl {
+		s.Delete(userID)
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// Save encrypts and stores session, replacing any earlier session of the same account
+func (s *SessionStore) Save(session *SavedSession) error {
+	if session.SavedAt.IsZero() {
+		session.SavedAt = time.Now()
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	sealed, err := cryptoutil.Seal(s.key, data)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt session: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated session behind
+	path := s.path(session.UserID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, sealed, 0600); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the saved session for userID
+func (s *SessionStore) Delete(userID string) error {
+	if err := os.Remove(s.path(userID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *SessionStore) path(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".session")
+}
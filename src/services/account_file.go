@@ -0,0 +1,335 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/models"
+	"gopkg.in/yaml.v3"
+)
+
+// accountFile はアカウントファイル（YAML、またはJSON）の内容
+//
+//	accounts:
+//	  - id: corp1
+//	    password: "p@ss:word,1"
+//	    type: corporate
+//	    display_name: 本社
+//	    tags: [tokyo]
+//	    download_dir: ./downloads/corp1
+//	    scraper:
+//	      timeout: 45s
+//	      headless: false
+type accountFile struct {
+	Accounts []accountFileEntry `yaml:"accounts" json:"accounts"`
+}
+
+// accountFileEntry はアカウントファイルの1件のアカウント
+type accountFileEntry struct {
+	ID          string   `yaml:"id" json:"id"`
+	Password    string   `yaml:"password,omitempty" json:"password,omitempty"`
+	Type        string   `yaml:"type" json:"type"`
+	DisplayName string   `yaml:"display_name,omitempty" json:"display_name,omitempty"` // 省略時はアカウントID
+	Tags        []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Enabled     *bool    `yaml:"enabled,omitempty" json:"enabled,omitempty"` // 省略時は有効
+	DownloadDir string   `yaml:"download_dir,omitempty" json:"download_dir,omitempty"`
+	Scraper     struct {
+		Timeout  string `yaml:"timeout,omitempty" json:"timeout,omitempty"` // Goのduration形式（例: 45s）
+		Headless *bool  `yaml:"headless,omitempty" json:"headless,omitempty"`
+	} `yaml:"scraper,omitempty" json:"scraper,omitzero"`
+}
+
+// FileAccountRegistry はアカウントファイルから読み込んだアカウント
+// 環境変数と異なり、パスワードに ',' や ':' を含められる
+// LoadAccountFile で読み込んだ場合は AddAccount と UpdateAccount の変更をファイルに書き戻す
+type FileAccountRegistry struct {
+	mu       sync.RWMutex
+	path     string // 空の場合は変更を保存できない
+	json     bool   // JSONのファイルから読み込んだ場合はJSONで書き戻す
+	accounts []models.ETCAccount
+}
+
+// LoadAccountFile reads a YAML or JSON account file from path.
+// Changes made through the registry are written back to path in the format it was read in.
+func LoadAccountFile(path string) (*FileAccountRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account file: %w", err)
+	}
+	registry, err := ParseAccountFile(data)
+	if err != nil {
+		return nil, err
+	}
+	registry.path = path
+	return registry, nil
+}
+
+// ParseAccountFile decodes and validates an account file.
+// JSON is accepted as well since it is a subset of YAML.
+func ParseAccountFile(data []byte) (*FileAccountRegistry, error) {
+	var file accountFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid account file: %w", err)
+	}
+
+	// JSONのファイルは必ず '{' で始まる（YAMLのフロースタイルで始まるファイルはJSONとして書き戻す）
+	registry := &FileAccountRegistry{json: bytes.HasPrefix(bytes.TrimSpace(data), []byte("{"))}
+	seen := make(map[string]bool, len(file.Accounts))
+	for i, entry := range file.Accounts {
+		account, err := entry.account()
+		if err != nil {
+			return nil, fmt.Errorf("invalid account file: account %d: %w", i+1, err)
+		}
+		if seen[account.ID] {
+			return nil, fmt.Errorf("invalid account file: duplicate account %q", account.ID)
+		}
+		seen[account.ID] = true
+		registry.accounts = append(registry.accounts, account)
+	}
+	return registry, nil
+}
+
+// account はエントリを検証して ETCAccount に変換する
+func (e accountFileEntry) account() (models.ETCAccount, error) {
+	account := models.ETCAccount{
+		ID:          e.ID,
+		Password:    e.Password,
+		Type:        e.Type,
+		DisplayName: e.DisplayName,
+		Tags:        e.Tags,
+		Enabled:     e.Enabled == nil || *e.Enabled,
+		DownloadDir: e.DownloadDir,
+	}
+	if err := normalizeAccount(&account); err != nil {
+		return models.ETCAccount{}, err
+	}
+	if e.Scraper.Timeout != "" {
+		timeout, err := time.ParseDuration(e.Scraper.Timeout)
+		if err != nil || timeout <= 0 {
+			return models.ETCAccount{}, fmt.Errorf("account %s: invalid scraper timeout %q", account.ID, e.Scraper.Timeout)
+		}
+		account.Scraper.Timeout = timeout
+	}
+	account.Scraper.Headless = e.Scraper.Headless
+	return account, nil
+}
+
+// newAccountFileEntry はアカウントをファイルのエントリに変換する。既定値の項目は省略する
+func newAccountFileEntry(account models.ETCAccount) accountFileEntry {
+	entry := accountFileEntry{
+		ID:          account.ID,
+		Password:    account.Password,
+		Type:        account.Type,
+		Tags:        account.Tags,
+		DownloadDir: account.DownloadDir,
+	}
+	if account.DisplayName != account.ID {
+		entry.DisplayName = account.DisplayName
+	}
+	if !account.Enabled {
+		entry.Enabled = &account.Enabled
+	}
+	if account.Scraper.Timeout > 0 {
+		entry.Scraper.Timeout = account.Scraper.Timeout.String()
+	}
+	entry.Scraper.Headless = account.Scraper.Headless
+	return entry
+}
+
+// normalizeAccount はアカウントの設定を検証し、Username と省略された表示名を補う
+// アカウントファイルと AddAccount、UpdateAccount で共通
+func normalizeAccount(account *models.ETCAccount) error {
+	id := strings.TrimSpace(account.ID)
+	if id == "" {
+		return fmt.Errorf("id is required")
+	}
+	if strings.ContainsAny(id, ":,") {
+		return fmt.Errorf("id %q must not contain ':' or ','", id)
+	}
+	if account.Type != models.AccountTypeCorporate && account.Type != models.AccountTypePersonal {
+		return fmt.Errorf("account %s: type must be %q or %q", id, models.AccountTypeCorporate, models.AccountTypePersonal)
+	}
+	if account.Scraper.Timeout < 0 {
+		return fmt.Errorf("account %s: scraper timeout must be positive", id)
+	}
+
+	account.ID = id
+	account.Username = id
+	if account.DisplayName == "" {
+		account.DisplayName = id
+	}
+	return nil
+}
+
+// find は accountID のアカウントを返す。呼び出し側で r.mu をロックする
+func (r *FileAccountRegistry) find(accountID string) (*models.ETCAccount, error) {
+	for i := range r.accounts {
+		if r.accounts[i].ID == accountID {
+			return &r.accounts[i], nil
+		}
+	}
+	return nil, ErrUnknownAccount
+}
+
+// AccountIDs は有効なすべてのアカウントIDをファイルの順に返す
+func (r *FileAccountRegistry) AccountIDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var accountIDs []string
+	for _, account := range r.accounts {
+		if account.Enabled {
+			accountIDs = append(accountIDs, account.ID)
+		}
+	}
+	return accountIDs
+}
+
+// Account はアカウントの設定を返す（Password は含まない）
+func (r *FileAccountRegistry) Account(accountID string) (models.ETCAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	account, err := r.find(accountID)
+	if err != nil {
+		return models.ETCAccount{}, err
+	}
+	return accountSettingsOf(*account), nil
+}
+
+// Accounts は無効なものを含むすべてのアカウントの設定をファイルの順に返す（Password は含まない）
+func (r *FileAccountRegistry) Accounts() []models.ETCAccount {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := make([]models.ETCAccount, 0, len(r.accounts))
+	for _, account := range r.accounts {
+		accounts = append(accounts, accountSettingsOf(account))
+	}
+	return accounts
+}
+
+// accountSettingsOf はパスワードを除いたアカウントのコピーを返す
+func accountSettingsOf(account models.ETCAccount) models.ETCAccount {
+	account.Password = ""
+	account.Tags = slices.Clone(account.Tags)
+	return account
+}
+
+// Password はアカウントのパスワードを返す
+// パスワードが設定されていないアカウントは ErrInvalidAccountFormat
+func (r *FileAccountRegistry) Password(accountID string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	account, err := r.find(accountID)
+	if err != nil {
+		return "", err
+	}
+	if account.Password == "" {
+		return "", fmt.Errorf("%w: no password configured for %s", ErrInvalidAccountFormat, accountID)
+	}
+	return account.Password, nil
+}
+
+// AddAccount はアカウントを追加してファイルに保存する。同じIDのアカウントがある場合は ErrAccountExists
+func (r *FileAccountRegistry) AddAccount(account models.ETCAccount) error {
+	if err := normalizeAccount(&account); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAccount, err)
+	}
+	account.Tags = slices.Clone(account.Tags)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.find(account.ID); err == nil {
+		return ErrAccountExists
+	}
+	return r.save(append(slices.Clone(r.accounts), account))
+}
+
+// UpdateAccount はアカウントの設定を置き換えてファイルに保存する
+// account.Password が空の場合は保存済みのパスワードを残す
+func (r *FileAccountRegistry) UpdateAccount(account models.ETCAccount) error {
+	if err := normalizeAccount(&account); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAccount, err)
+	}
+	account.Tags = slices.Clone(account.Tags)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, err := r.find(account.ID)
+	if err != nil {
+		return err
+	}
+	if account.Password == "" {
+		account.Password = current.Password
+	}
+
+	accounts := slices.Clone(r.accounts)
+	for i := range accounts {
+		if accounts[i].ID == account.ID {
+			accounts[i] = account
+		}
+	}
+	return r.save(accounts)
+}
+
+// save は accounts をファイルに書き込み、成功した場合にだけメモリ上のアカウントを置き換える
+// ファイルは読み込んだ形式（YAMLまたはJSON）で書き直すため、元のファイルのコメントは残らない。呼び出し側で r.mu をロックする
+func (r *FileAccountRegistry) save(accounts []models.ETCAccount) error {
+	if r.path == "" {
+		return ErrAccountsReadOnly
+	}
+
+	file := accountFile{Accounts: make([]accountFileEntry, 0, len(accounts))}
+	for _, account := range accounts {
+		file.Accounts = append(file.Accounts, newAccountFileEntry(account))
+	}
+	data, err := r.marshal(&file)
+	if err != nil {
+		return err
+	}
+
+	// 書き込み途中のファイルを読まれないよう、一時ファイルに書いてから置き換える
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write account file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write account file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write account file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("failed to write account file: %w", err)
+	}
+
+	r.accounts = accounts
+	return nil
+}
+
+// marshal はファイルを読み込んだ形式に変換する
+func (r *FileAccountRegistry) marshal(file *accountFile) ([]byte, error) {
+	if !r.json {
+		return yaml.Marshal(file)
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
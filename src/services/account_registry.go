@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/credentials"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/models"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+)
+
+// アカウントの指定に関するエラー
+var (
+	ErrUnknownAccount   = errors.New("unknown account")             // 登録されていないアカウントIDが指定された
+	ErrAccountDisabled  = errors.New("account disabled")            // 無効にしたアカウントが指定された
+	ErrAccountExists    = errors.New("account already exists")      // 追加するアカウントIDがすでに登録されている
+	ErrInvalidAccount   = errors.New("invalid account")             // 追加、変更するアカウントの設定が正しくない
+	ErrAccountsReadOnly = errors.New("accounts cannot be modified") // 環境変数のアカウントは変更できない（ETC_ACCOUNTS_FILE が必要）
+)
+
+// AccountRegistry はサーバー側でアカウントの認証情報を保持する
+// クライアントはアカウントIDだけを指定し、パスワードはここから解決する
+type AccountRegistry interface {
+	// AccountIDs は有効なすべてのアカウントIDを登録順に返す
+	AccountIDs() []string
+	// Accounts は無効なものを含むすべてのアカウントの設定を登録順に返す（Password は含まない）
+	Accounts() []models.ETCAccount
+	// Account はアカウントの設定を返す（Password は含まない）。登録されていない場合は ErrUnknownAccount
+	Account(accountID string) (models.ETCAccount, error)
+	// Password はアカウントのパスワードを返す。登録されていない場合は ErrUnknownAccount
+	Password(accountID string) (string, error)
+}
+
+// WritableAccountRegistry はアカウントの追加と変更を保存できる AccountRegistry（FileAccountRegistry が実装）
+type WritableAccountRegistry interface {
+	AccountRegistry
+	// AddAccount はアカウントを追加する。同じIDのアカウントがある場合は ErrAccountExists
+	AddAccount(account models.ETCAccount) error
+	// UpdateAccount はアカウントの設定を置き換える。account.Password が空の場合はパスワードを変えない
+	UpdateAccount(account models.ETCAccount) error
+}
+
+// EnvAccountRegistry は ETC_CORPORATE_ACCOUNTS と ETC_PERSONAL_ACCOUNTS（accountID:password のカンマ区切り）のアカウント
+// 環境変数は呼び出しのたびに読み直す
+type EnvAccountRegistry struct{}
+
+// NewEnvAccountRegistry creates a registry backed by the account environment variables
+func NewEnvAccountRegistry() *EnvAccountRegistry {
+	return &EnvAccountRegistry{}
+}
+
+// envAccount は環境変数の1件のアカウント
+type envAccount struct {
+	userID      string
+	password    string
+	hasPassword bool
+	accountType string
+}
+
+// accounts は法人アカウント、個人アカウントの順に環境変数のアカウントを返す
+func (r *EnvAccountRegistry) accounts() []envAccount {
+	var accounts []envAccount
+	for _, source := range []struct{ name, accountType string }{
+		{"ETC_CORPORATE_ACCOUNTS", models.AccountTypeCorporate},
+		{"ETC_PERSONAL_ACCOUNTS", models.AccountTypePersonal},
+	} {
+		value := os.Getenv(source.name)
+		if value == "" {
+			continue
+		}
+		for _, accountStr := range strings.Split(value, ",") {
+			userID, password, found := strings.Cut(strings.TrimSpace(accountStr), ":")
+			accounts = append(accounts, envAccount{userID: userID, password: password, hasPassword: found, accountType: source.accountType})
+		}
+	}
+	return accounts
+}
+
+// AccountIDs は登録されているすべてのアカウントIDを返す
+func (r *EnvAccountRegistry) AccountIDs() []string {
+	var accountIDs []string
+	for _, account := range r.accounts() {
+		accountIDs = append(accountIDs, account.userID)
+	}
+	return accountIDs
+}
+
+// Account はアカウントの設定を返す。環境変数のアカウントは常に有効で、表示名はアカウントID
+func (r *EnvAccountRegistry) Account(accountID string) (models.ETCAccount, error) {
+	for _, account := range r.accounts() {
+		if account.userID == accountID {
+			return account.settings(), nil
+		}
+	}
+	return models.ETCAccount{}, ErrUnknownAccount
+}
+
+// Accounts はすべてのアカウントの設定を返す
+func (r *EnvAccountRegistry) Accounts() []models.ETCAccount {
+	var accounts []models.ETCAccount
+	for _, account := range r.accounts() {
+		accounts = append(accounts, account.settings())
+	}
+	return accounts
+}
+
+// settings はパスワードを除いたアカウントの設定を返す
+func (a envAccount) settings() models.ETCAccount {
+	return models.ETCAccount{
+		ID:          a.userID,
+		Username:    a.userID,
+		Type:        a.accountType,
+		DisplayName: a.userID,
+		Enabled:     true,
+	}
+}
+
+// Password はアカウントのパスワードを返す
+// パスワードが設定されていないアカウントは ErrInvalidAccountFormat
+func (r *EnvAccountRegistry) Password(accountID string) (string, error) {
+	for _, account := range r.accounts() {
+		if account.userID != accountID {
+			continue
+		}
+		if !account.hasPassword || account.password == "" {
+			return "", fmt.Errorf("%w: no password configured for %s", ErrInvalidAccountFormat, accountID)
+		}
+		return account.password, nil
+	}
+	return "", ErrUnknownAccount
+}
+
+// ResolveAccounts はダウンロードするアカウントを確認する
+// accounts が空の場合は有効なすべてのアカウントIDを返す
+// 登録済みのアカウントはIDのまま返し、パスワードはスクレイパーがログインの直前に取得する
+// クライアントからはパスワードを受け取らないため、accountID:password 形式は ErrInvalidAccountFormat
+// 登録されていない、または無効なアカウントが1件でもあればジョブを始める前に AccountError を返す
+func (s *DownloadService) ResolveAccounts(accounts []string) ([]string, error) {
+	if len(accounts) == 0 {
+		accounts = s.accounts.AccountIDs()
+		if len(accounts) == 0 {
+			return nil, ErrNoAccountsConfigured
+		}
+	}
+
+	resolved := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		if userID, _, found := strings.Cut(account, ":"); found {
+			// エラーにはパスワードを含めない
+			err := fmt.Errorf("%w: specify the account ID only, passwords are not accepted", ErrInvalidAccountFormat)
+			return nil, &AccountError{AccountID: userID, Err: err}
+		}
+
+		settings, err := s.accounts.Account(account)
+		if err != nil {
+			return nil, &AccountError{AccountID: account, Err: err}
+		}
+		if !settings.Enabled {
+			return nil, &AccountError{AccountID: account, Err: ErrAccountDisabled}
+		}
+		resolved = append(resolved, account)
+	}
+	return resolved, nil
+}
+
+// SetSecretProvider は登録済みアカウントのパスワードを provider から取得するようにする（ETC_VAULT_PATH の保管庫の代わり）
+// provider が scraper.ErrSecretNotFound を返したアカウントはアカウントファイルまたは環境変数のパスワードを使う
+func (s *DownloadService) SetSecretProvider(provider scraper.SecretProvider) {
+	s.secrets.mu.Lock()
+	defer s.secrets.mu.Unlock()
+	s.secrets.provider = provider
+}
+
+// accountSecrets は登録済みアカウントのパスワードをログインの直前に返す
+// provider を優先し、保存されていないアカウントは accounts のパスワードを使う
+type accountSecrets struct {
+	mu       sync.RWMutex
+	provider scraper.SecretProvider // nil の場合は accounts のみ
+	accounts AccountRegistry
+}
+
+// Password は accountID のパスワードを返す（scraper.SecretProvider）
+func (a *accountSecrets) Password(ctx context.Context, accountID string) (string, error) {
+	a.mu.RLock()
+	provider := a.provider
+	a.mu.RUnlock()
+
+	if provider != nil {
+		password, err := provider.Password(ctx, accountID)
+		if !errors.Is(err, scraper.ErrSecretNotFound) {
+			return password, err
+		}
+	}
+	return a.accounts.Password(accountID)
+}
+
+// accountRegistryFromEnv は ETC_ACCOUNTS_FILE が設定されている場合にアカウントファイルを読み込む
+// 未設定または読み込めない場合は ETC_CORPORATE_ACCOUNTS と ETC_PERSONAL_ACCOUNTS のアカウント
+func accountRegistryFromEnv(logger *log.Logger) AccountRegistry {
+	path := os.Getenv("ETC_ACCOUNTS_FILE")
+	if path == "" {
+		return NewEnvAccountRegistry()
+	}
+
+	registry, err := LoadAccountFile(path)
+	if err != nil {
+		logWarning(logger, "using accounts from environment variables: %v", err)
+		return NewEnvAccountRegistry()
+	}
+	if logger != nil {
+		logger.Printf("Loaded %d accounts from %s", len(registry.accounts), path)
+	}
+	return registry
+}
+
+// secretProviderFromEnv は ETC_VAULT_PATH が設定されている場合に資格情報の保管庫を開く
+// 未設定または開けない場合は nil（アカウントファイルまたは環境変数のパスワードを使う）
+func secretProviderFromEnv(logger *log.Logger) scraper.SecretProvider {
+	if os.Getenv("ETC_VAULT_PATH") == "" {
+		return nil
+	}
+
+	vault, err := OpenVaultFromEnv()
+	if err != nil {
+		logWarning(logger, "credential vault disabled: %v", err)
+		return nil
+	}
+	if logger != nil {
+		logger.Printf("Using credential vault %s", os.Getenv("ETC_VAULT_PATH"))
+	}
+	return vault
+}
+
+// OpenVaultFromEnv は ETC_VAULT_PATH の資格情報の保管庫を開く
+// 鍵は ETC_VAULT_KEY_FILE（32バイトの鍵をbase64またはhexで保存したファイル）か ETC_VAULT_PASSPHRASE で指定する
+func OpenVaultFromEnv() (*credentials.Vault, error) {
+	path := os.Getenv("ETC_VAULT_PATH")
+	if path == "" {
+		return nil, errors.New("ETC_VAULT_PATH is not set")
+	}
+	if keyFile := os.Getenv("ETC_VAULT_KEY_FILE"); keyFile != "" {
+		return credentials.OpenWithKeyFile(path, keyFile)
+	}
+	if passphrase := os.Getenv("ETC_VAULT_PASSPHRASE"); passphrase != "" {
+		return credentials.OpenWithPassphrase(path, passphrase)
+	}
+	return nil, fmt.Errorf("ETC_VAULT_KEY_FILE or ETC_VAULT_PASSPHRASE is required for %s", path)
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/credentials"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/models"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+)
+
+// ErrVaultRequired はパスワードを保存できる保管庫が設定されていない場合のエラー
+// APIで受け取ったパスワードを平文のアカウントファイルには書き込まない
+var ErrVaultRequired = errors.New("credential vault is required to store passwords")
+
+// SecretStore はパスワードを保存できる scraper.SecretProvider（credentials.Vault が実装）
+// AddAccount と UpdateAccount のパスワードはここにだけ保存する
+type SecretStore interface {
+	scraper.SecretProvider
+	Add(accountID, password string) error
+	Rotate(accountID, password string) error
+	Remove(accountID string) error
+}
+
+// AccountValidation は ValidateAccount の結果
+type AccountValidation struct {
+	AccountID    string
+	Valid        bool
+	ErrorCode    string // ログインできなかった理由（INVALID_CREDENTIALS、ACCOUNT_LOCKED など）
+	ErrorMessage string
+	CheckedAt    time.Time
+}
+
+// ListAccounts は無効なものを含むすべてのアカウントの設定を返す（Password は含まない）
+func (s *DownloadService) ListAccounts() []models.ETCAccount {
+	return s.accounts.Accounts()
+}
+
+// GetAccount はアカウントの設定を返す（Password は含まない）
+func (s *DownloadService) GetAccount(accountID string) (models.ETCAccount, error) {
+	account, err := s.accounts.Account(accountID)
+	if err != nil {
+		return models.ETCAccount{}, &AccountError{AccountID: accountID, Err: err}
+	}
+	return account, nil
+}
+
+// AddAccount はアカウントを追加し、保存した設定を返す
+// password は保管庫（ETC_VAULT_PATH または SetSecretProvider の SecretStore）に保存し、保管庫がない場合は ErrVaultRequired
+func (s *DownloadService) AddAccount(account models.ETCAccount, password string) (models.ETCAccount, error) {
+	registry, err := s.writableAccounts()
+	if err != nil {
+		return models.ETCAccount{}, &AccountError{AccountID: account.ID, Err: err}
+	}
+	if err := normalizeAccount(&account); err != nil {
+		return models.ETCAccount{}, &AccountError{AccountID: account.ID, Err: fmt.Errorf("%w: %v", ErrInvalidAccount, err)}
+	}
+	if _, err := registry.Account(account.ID); err == nil {
+		return models.ETCAccount{}, &AccountError{AccountID: account.ID, Err: ErrAccountExists}
+	}
+	if err := s.checkDownloadDir(account.DownloadDir); err != nil {
+		return models.ETCAccount{}, &AccountError{AccountID: account.ID, Err: err}
+	}
+
+	account.Password = ""
+	undo, err := s.storePassword(account.ID, password)
+	if err != nil {
+		return models.ETCAccount{}, &AccountError{AccountID: account.ID, Err: err}
+	}
+	if err := registry.AddAccount(account); err != nil {
+		undo()
+		return models.ETCAccount{}, &AccountError{AccountID: account.ID, Err: err}
+	}
+	if s.logger != nil {
+		s.logger.Printf("Added account %s", account.ID)
+	}
+	return s.GetAccount(account.ID)
+}
+
+// UpdateAccount はアカウントの設定を置き換え、保存した設定を返す
+// password が空の場合はパスワードを変えない。変更したダウンロード先はダウンロード先のルートの配下に限る
+func (s *DownloadService) UpdateAccount(account models.ETCAccount, password string) (models.ETCAccount, error) {
+	registry, err := s.writableAccounts()
+	if err != nil {
+		return models.ETCAccount{}, &AccountError{AccountID: account.ID, Err: err}
+	}
+	current, err := registry.Account(account.ID)
+	if err != nil {
+		return models.ETCAccount{}, &AccountError{AccountID: account.ID, Err: err}
+	}
+	if err := normalizeAccount(&account); err != nil {
+		return models.ETCAccount{}, &AccountError{AccountID: account.ID, Err: fmt.Errorf("%w: %v", ErrInvalidAccount, err)}
+	}
+	// アカウントファイルで管理者が設定したダウンロード先はそのまま残せる
+	if account.DownloadDir != current.DownloadDir {
+		if err := s.checkDownloadDir(account.DownloadDir); err != nil {
+			return models.ETCAccount{}, &AccountError{AccountID: account.ID, Err: err}
+		}
+	}
+
+	account.Password = ""
+	undo, err := s.storePassword(account.ID, password)
+	if err != nil {
+		return models.ETCAccount{}, &AccountError{AccountID: account.ID, Err: err}
+	}
+	if err := registry.UpdateAccount(account); err != nil {
+		undo()
+		return models.ETCAccount{}, &AccountError{AccountID: account.ID, Err: err}
+	}
+	if s.logger != nil {
+		s.logger.Printf("Updated account %s", account.ID)
+	}
+	return s.GetAccount(account.ID)
+}
+
+// DisableAccount はアカウントをダウンロードの対象から外し、保存したログインセッションを削除する
+// 設定とパスワードは残すため、UpdateAccount で enabled に戻せる
+func (s *DownloadService) DisableAccount(accountID string) (models.ETCAccount, error) {
+	account, err := s.GetAccount(accountID)
+	if err != nil {
+		return models.ETCAccount{}, err
+	}
+	account.Enabled = false
+	if account, err = s.UpdateAccount(account, ""); err != nil {
+		return models.ETCAccount{}, err
+	}
+
+	if s.sessions != nil {
+		if err := s.sessions.Delete(accountID); err != nil {
+			logWarning(s.logger, "failed to delete saved session of account %s: %v", accountID, err)
+		}
+	}
+	return account, nil
+}
+
+// ValidateAccount は登録済みのアカウントでサイトにログインしてログアウトし、認証情報が使えるかを返す（明細はダウンロードしない）
+// password を指定した場合は保存されているパスワードの代わりに使う（変更する前の確認など）。指定したパスワードは保存しない
+// 認証情報によるログインの失敗は Valid が false の結果で返し、サイトの障害などは error を返す
+func (s *DownloadService) ValidateAccount(ctx context.Context, accountID, password string) (*AccountValidation, error) {
+	if accountID == "" || strings.ContainsAny(accountID, ":,") {
+		return nil, &AccountError{AccountID: accountID, Err: fmt.Errorf("%w: invalid account ID %q", ErrInvalidAccount, accountID)}
+	}
+	config, err := s.accountScraperConfig(accountID, "")
+	if err != nil {
+		return nil, err
+	}
+	if password != "" {
+		config.Secrets = candidatePassword{accountID: accountID, password: password}
+	}
+	// 保存したセッションではパスワードを確認できないため、必ずログインする
+	config.Sessions = nil
+
+	// サービス停止時にも中断し、Shutdownが完了を待てるようにする
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stopShutdown := context.AfterFunc(s.ctx, cancel)
+	defer stopShutdown()
+	s.running.Add(1)
+	defer s.running.Done()
+
+	err = s.withScraper(ctx, "", 0, config, func(userID string, etcScraper scraper.ContextScraper) error {
+		if err := scraper.Logout(ctx, etcScraper); err != nil {
+			logWarning(s.logger, "failed to log out account %s: %v", userID, err)
+		}
+		return nil
+	})
+
+	result := &AccountValidation{AccountID: config.UserID, CheckedAt: time.Now()}
+	switch {
+	case err == nil:
+		result.Valid = true
+	case isCredentialError(err):
+		result.ErrorCode = ErrorCode(err)
+		result.ErrorMessage = err.Error()
+	default:
+		return nil, &AccountError{AccountID: config.UserID, Err: err}
+	}
+	if s.logger != nil {
+		s.logger.Printf("Validated account %s: valid=%t %s", result.AccountID, result.Valid, result.ErrorCode)
+	}
+	return result, nil
+}
+
+// candidatePassword は ValidateAccount で確認するパスワードだけを返す scraper.SecretProvider
+type candidatePassword struct {
+	accountID string
+	password  string
+}
+
+func (c candidatePassword) Password(ctx context.Context, accountID string) (string, error) {
+	if accountID != c.accountID {
+		return "", scraper.ErrSecretNotFound
+	}
+	return c.password, nil
+}
+
+// isCredentialError は認証情報が原因でログインできなかったエラーかを返す
+func isCredentialError(err error) bool {
+	return errors.Is(err, scraper.ErrInvalidCredentials) || errors.Is(err, scraper.ErrAccountLocked) ||
+		errors.Is(err, scraper.ErrPasswordExpired) || errors.Is(err, ErrInvalidAccountFormat)
+}
+
+// writableAccounts は変更を保存できるアカウントの登録先を返す
+func (s *DownloadService) writableAccounts() (WritableAccountRegistry, error) {
+	registry, ok := s.accounts.(WritableAccountRegistry)
+	if !ok {
+		return nil, ErrAccountsReadOnly
+	}
+	return registry, nil
+}
+
+// storePassword は password を保管庫に保存し、保管庫を元に戻す関数を返す。password が空の場合は何もしない
+// 保管庫がない場合は ErrVaultRequired（平文のアカウントファイルには保存しない）
+// アカウントの保存に失敗した場合は返した関数を呼び、保管庫だけが変わったままにしない
+func (s *DownloadService) storePassword(accountID, password string) (undo func(), err error) {
+	if password == "" {
+		return func() {}, nil
+	}
+	s.secrets.mu.RLock()
+	store, ok := s.secrets.provider.(SecretStore)
+	s.secrets.mu.RUnlock()
+	if !ok {
+		return nil, ErrVaultRequired
+	}
+
+	err = store.Add(accountID, password)
+	if err == nil {
+		return func() {
+			if err := store.Remove(accountID); err != nil {
+				logWarning(s.logger, "failed to remove password of account %s from the vault: %v", accountID, err)
+			}
+		}, nil
+	}
+	if !errors.Is(err, credentials.ErrExists) {
+		return nil, fmt.Errorf("failed to store password: %w", err)
+	}
+
+	previous, err := store.Password(context.Background(), accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store password: %w", err)
+	}
+	if err := store.Rotate(accountID, password); err != nil {
+		return nil, fmt.Errorf("failed to store password: %w", err)
+	}
+	return func() {
+		if err := store.Rotate(accountID, previous); err != nil {
+			logWarning(s.logger, "failed to restore password of account %s in the vault: %v", accountID, err)
+		}
+	}, nil
+}
+
+// checkDownloadDir は dir がダウンロード先のルートの配下であることを確認する（空はルートを使う）
+func (s *DownloadService) checkDownloadDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	root, err := filepath.Abs(s.downloadRoot)
+	if err != nil {
+		return err
+	}
+	path, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("%w: invalid download_dir %q", ErrInvalidAccount, dir)
+	}
+	if rel, err := filepath.Rel(root, path); err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("%w: download_dir %q must be under %s", ErrInvalidAccount, dir, s.downloadRoot)
+	}
+	return nil
+}
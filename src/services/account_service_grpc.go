@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/models"
+	pb "github.com/yhonda-ohishi/etc_meisai_scraper/src/pb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AccountManager はアカウントの一覧、追加、変更、認証情報の確認を行う（DownloadService が実装）
+type AccountManager interface {
+	ListAccounts() []models.ETCAccount
+	GetAccount(accountID string) (models.ETCAccount, error)
+	AddAccount(account models.ETCAccount, password string) (models.ETCAccount, error)
+	UpdateAccount(account models.ETCAccount, password string) (models.ETCAccount, error)
+	DisableAccount(accountID string) (models.ETCAccount, error)
+	ValidateAccount(ctx context.Context, accountID, password string) (*AccountValidation, error)
+}
+
+// AccountServiceGRPC はアカウントを管理するgRPCサービス
+// パスワードは受け取るだけで、レスポンスには含めない
+type AccountServiceGRPC struct {
+	pb.UnimplementedAccountServiceServer
+	accounts AccountManager
+}
+
+// NewAccountServiceGRPC creates a new gRPC account service on accounts
+func NewAccountServiceGRPC(accounts AccountManager) *AccountServiceGRPC {
+	return &AccountServiceGRPC{accounts: accounts}
+}
+
+// ListAccounts は無効なものを含むすべてのアカウントを返す
+func (s *AccountServiceGRPC) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	accounts := s.accounts.ListAccounts()
+	response := &pb.ListAccountsResponse{Accounts: make([]*pb.Account, 0, len(accounts))}
+	for _, account := range accounts {
+		response.Accounts = append(response.Accounts, accountToProto(account))
+	}
+	return response, nil
+}
+
+// AddAccount はアカウントを追加する
+func (s *AccountServiceGRPC) AddAccount(ctx context.Context, req *pb.AddAccountRequest) (*pb.Account, error) {
+	if req.Account == nil {
+		return nil, ScraperErrorStatus(fmt.Errorf("%w: account is required", ErrInvalidAccount), "")
+	}
+	account, err := s.accounts.AddAccount(accountFromProto(req.Account), req.Password)
+	if err != nil {
+		return nil, ScraperErrorStatus(err, "")
+	}
+	return accountToProto(account), nil
+}
+
+// UpdateAccount は update_mask の項目（省略時はすべての項目）を更新する
+func (s *AccountServiceGRPC) UpdateAccount(ctx context.Context, req *pb.UpdateAccountRequest) (*pb.Account, error) {
+	if req.Account == nil {
+		return nil, ScraperErrorStatus(fmt.Errorf("%w: account is required", ErrInvalidAccount), "")
+	}
+	account, err := s.accounts.GetAccount(req.Account.AccountId)
+	if err != nil {
+		return nil, ScraperErrorStatus(err, "")
+	}
+
+	update := accountFromProto(req.Account)
+	if len(req.UpdateMask.GetPaths()) == 0 {
+		account = update
+	}
+	for _, path := range req.UpdateMask.GetPaths() {
+		switch path {
+		case "display_name":
+			account.DisplayName = update.DisplayName
+		case "type":
+			account.Type = update.Type
+		case "tags":
+			account.Tags = update.Tags
+		case "enabled":
+			account.Enabled = update.Enabled
+		case "download_dir":
+			account.DownloadDir = update.DownloadDir
+		case "timeout":
+			account.Scraper.Timeout = update.Scraper.Timeout
+		case "headless":
+			account.Scraper.Headless = update.Scraper.Headless
+		default:
+			err := &AccountError{AccountID: account.ID, Err: fmt.Errorf("%w: cannot update %q", ErrInvalidAccount, path)}
+			return nil, ScraperErrorStatus(err, "")
+		}
+	}
+
+	account, err = s.accounts.UpdateAccount(account, req.Password)
+	if err != nil {
+		return nil, ScraperErrorStatus(err, "")
+	}
+	return accountToProto(account), nil
+}
+
+// DisableAccount はアカウントを無効にする
+func (s *AccountServiceGRPC) DisableAccount(ctx context.Context, req *pb.DisableAccountRequest) (*pb.Account, error) {
+	account, err := s.accounts.DisableAccount(req.AccountId)
+	if err != nil {
+		return nil, ScraperErrorStatus(err, "")
+	}
+	return accountToProto(account), nil
+}
+
+// ValidateAccount はサイトにログインして認証情報が使えるかを返す
+func (s *AccountServiceGRPC) ValidateAccount(ctx context.Context, req *pb.ValidateAccountRequest) (*pb.ValidateAccountResponse, error) {
+	result, err := s.accounts.ValidateAccount(ctx, req.AccountId, req.GetPassword())
+	if err != nil {
+		return nil, ScraperErrorStatus(err, "")
+	}
+	return &pb.ValidateAccountResponse{
+		AccountId:    result.AccountID,
+		Valid:        result.Valid,
+		ErrorCode:    result.ErrorCode,
+		ErrorMessage: result.ErrorMessage,
+		CheckedAt:    timestamppb.New(result.CheckedAt),
+	}, nil
+}
+
+// accountToProto はアカウントの設定をProtocol Buffersメッセージに変換する（パスワードは含めない）
+func accountToProto(account models.ETCAccount) *pb.Account {
+	enabled := account.Enabled
+	message := &pb.Account{
+		AccountId:   account.ID,
+		DisplayName: account.DisplayName,
+		Type:        account.Type,
+		Tags:        account.Tags,
+		Enabled:     &enabled,
+		DownloadDir: account.DownloadDir,
+		Headless:    account.Scraper.Headless,
+	}
+	if account.Scraper.Timeout > 0 {
+		message.Timeout = durationpb.New(account.Scraper.Timeout)
+	}
+	return message
+}
+
+// accountFromProto はProtocol Buffersメッセージをアカウントの設定に変換する。enabled の省略は有効
+func accountFromProto(message *pb.Account) models.ETCAccount {
+	account := models.ETCAccount{
+		ID:          message.AccountId,
+		DisplayName: message.DisplayName,
+		Type:        message.Type,
+		Tags:        message.Tags,
+		Enabled:     message.Enabled == nil || *message.Enabled,
+		DownloadDir: message.DownloadDir,
+	}
+	if message.Timeout != nil {
+		account.Scraper.Timeout = message.Timeout.AsDuration()
+	}
+	if message.Headless != nil {
+		headless := *message.Headless
+		account.Scraper.Headless = &headless
+	}
+	return account
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// MergeCSVFiles は複数の明細CSVを1ファイルに結合し、前のファイルと重複する行を除去
+// ヘッダーは最初のファイルのものを使用する。文字コード（Shift_JIS）はそのまま維持される
+func MergeCSVFiles(paths []string, destPath string) (int, error) {
+	contents := make([][]byte, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read CSV %s: %w", path, err)
+		}
+		contents = append(contents, data)
+	}
+
+	merged, rowCount := MergeCSV(contents)
+	if err := os.WriteFile(destPath, merged, 0644); err != nil {
+		return 0, fmt.Errorf("failed to write merged CSV: %w", err)
+	}
+
+	return rowCount, nil
+}
+
+// MergeCSV はメモリ上の複数の明細CSVを結合し、重複行を除去した内容とデータ行数を返す
+// 除去するのは前のCSV（重なった期間）に既に出力した行だけで、同じCSVの中で同じ内容の行（同じ料金所の往復など）は残す
+// ヘッダーと文字コードの扱いは MergeCSVFiles と同じ
+func MergeCSV(contents [][]byte) ([]byte, int) {
+	var header []byte
+	var rows [][]byte
+	// emitted は前のCSVまでに出力した各行の数（どれか1つのCSVに含まれていた最大数）
+	emitted := make(map[string]int)
+
+	for _, data := range contents {
+		counts := make(map[string]int)
+		lines := bytes.Split(data, []byte("\n"))
+		first := true
+		for _, line := range lines {
+			line = bytes.TrimRight(line, "\r")
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			if first {
+				first = false
+				if header == nil {
+					header = line
+				}
+				continue
+			}
+			key := string(line)
+			counts[key]++
+			if counts[key] <= emitted[key] {
+				continue
+			}
+			rows = append(rows, line)
+		}
+		for key, count := range counts {
+			emitted[key] = max(emitted[key], count)
+		}
+	}
+
+	var buf bytes.Buffer
+	if header != nil {
+		buf.Write(header)
+		buf.WriteString("\r\n")
+	}
+	for _, row := range rows {
+		buf.Write(row)
+		buf.WriteString("\r\n")
+	}
+
+	return buf.Bytes(), len(rows)
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/parser"
+	pb "github.com/yhonda-ohishi/etc_meisai_scraper/src/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DefaultChunkSize は DownloadStream の1チャンクに含めるCSVの最大バイト数
+const DefaultChunkSize = 1024 * 1024
+
+// BufferDownloader はCSVをファイルに残さずにダウンロードする（DownloadService が実装）
+type BufferDownloader interface {
+	ResolveAccounts(accounts []string) ([]string, error)
+	DownloadBuffer(ctx context.Context, accounts []string, fromDate, toDate string) ([]byte, error)
+}
+
+// DownloadBufferServiceGRPC は明細CSVをバイナリ、ストリーム、Protocol Buffersメッセージで返すgRPCサービス
+type DownloadBufferServiceGRPC struct {
+	pb.UnimplementedDownloadBufferServiceServer
+	downloader BufferDownloader
+	chunkSize  int
+}
+
+// NewDownloadBufferServiceGRPC creates a new gRPC buffer download service on downloader
+func NewDownloadBufferServiceGRPC(downloader BufferDownloader) *DownloadBufferServiceGRPC {
+	return NewDownloadBufferServiceGRPCWithChunkSize(downloader, DefaultChunkSize)
+}
+
+// NewDownloadBufferServiceGRPCWithChunkSize creates a new gRPC buffer download service streaming chunks of chunkSize bytes
+func NewDownloadBufferServiceGRPCWithChunkSize(downloader BufferDownloader, chunkSize int) *DownloadBufferServiceGRPC {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	return &DownloadBufferServiceGRPC{
+		downloader: downloader,
+		chunkSize:  chunkSize,
+	}
+}
+
+// DownloadAsBuffer はCSVの内容をそのまま返す
+// record_count と文字コードは参考情報のため、明細CSVとして解析できない場合も失敗にしない
+func (s *DownloadBufferServiceGRPC) DownloadAsBuffer(ctx context.Context, req *pb.BufferDownloadRequest) (*pb.BufferResponse, error) {
+	data, err := s.download(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.BufferResponse{
+		CsvData:     data,
+		ContentType: "text/csv",
+		SizeBytes:   int64(len(data)),
+	}
+	if parsed, err := parser.Parse(data); err == nil {
+		response.RecordCount = int32(len(parsed.Records))
+		response.ContentType += "; charset=" + parsed.Encoding
+	}
+	return response, nil
+}
+
+// DownloadStream はCSVの内容をチャンクに分けて送信する
+// sequence_number は0から始まり、最後のチャンクは is_last が true（空のCSVでも1チャンク送信）
+// CSVは解析せずにそのまま送る
+func (s *DownloadBufferServiceGRPC) DownloadStream(req *pb.BufferDownloadRequest, stream pb.DownloadBufferService_DownloadStreamServer) error {
+	data, err := s.download(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	sequence := int32(0)
+	for {
+		size := min(s.chunkSize, len(data))
+		chunk := &pb.ChunkResponse{
+			Chunk:          data[:size],
+			IsLast:         size == len(data),
+			SequenceNumber: sequence,
+		}
+		if err := stream.Send(chunk); err != nil {
+			return err
+		}
+		if chunk.IsLast {
+			return nil
+		}
+		data = data[size:]
+		sequence++
+	}
+}
+
+// DownloadAsProto はCSVを解析し、明細をProtocol Buffersメッセージで返す
+// 明細CSVとして解析できない場合は Internal
+func (s *DownloadBufferServiceGRPC) DownloadAsProto(ctx context.Context, req *pb.BufferDownloadRequest) (*pb.ProtoResponse, error) {
+	data, err := s.download(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parser.Parse(data)
+	if err != nil {
+		return nil, ScraperErrorStatus(fmt.Errorf("failed to parse CSV: %w", err), "")
+	}
+
+	response := &pb.ProtoResponse{
+		Records: make([]*pb.ETCRecord, 0, len(parsed.Records)),
+		Metadata: &pb.RecordMetadata{
+			TotalCount:   int32(len(parsed.Records)),
+			DownloadedAt: timestamppb.New(time.Now()),
+			AccountId:    strings.Join(req.Accounts, ","),
+		},
+	}
+	for _, record := range parsed.Records {
+		response.Records = append(response.Records, &pb.ETCRecord{
+			Date:          record.UsageDate.Format("2006-01-02"),
+			Time:          record.UsageDate.Format("15:04"),
+			EntranceIc:    record.EntryIC,
+			ExitIc:        record.ExitIC,
+			Fare:          int32(record.Amount),
+			VehicleNumber: record.VehicleNumber,
+			CardNumber:    record.ETCCardNumber,
+		})
+	}
+
+	return response, nil
+}
+
+// download はリクエストを検証してCSVをダウンロードし、その内容を返す
+func (s *DownloadBufferServiceGRPC) download(ctx context.Context, req *pb.BufferDownloadRequest) ([]byte, error) {
+	if len(req.Accounts) == 0 {
+		return nil, ScraperErrorStatus(ErrAccountsRequired, "")
+	}
+	accounts, err := s.downloader.ResolveAccounts(req.Accounts)
+	if err != nil {
+		return nil, ScraperErrorStatus(err, "")
+	}
+	fromDate, toDate := setDefaultDates(req.FromDate, req.ToDate)
+
+	data, err := s.downloader.DownloadBuffer(ctx, accounts, fromDate, toDate)
+	if err != nil {
+		return nil, ScraperErrorStatus(err, "")
+	}
+	return data, nil
+}
@@ -1,15 +1,21 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/cryptoutil"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/models"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/parser"
 	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
 )
 
@@ -17,27 +23,101 @@ import (
 type DownloadService struct {
 	db             *sql.DB
 	logger         *log.Logger
-	jobs           map[string]*DownloadJob
-	jobMutex       sync.RWMutex
+	jobs           JobStore   // ジョブの保存先（ETC_JOB_STORE_PATH 設定時はファイル）
+	jobMutex       sync.Mutex // ジョブの読み込みから保存までを直列化
 	scraperFactory ScraperFactory
+	accounts       AccountRegistry       // 登録されているアカウントとその設定
+	secrets        *accountSecrets       // 登録済みアカウントのパスワード（ログインの直前に取得する）
+	sessions       *scraper.SessionStore // ログインセッションの保存先（nilなら毎回ログイン）
+	selectors      *scraper.SelectorPack // 明細サイトのセレクタ（nilなら組み込みのパック）
+	browsers       *scraper.BrowserPool  // 全ジョブで共有するブラウザ（アカウントごとに別コンテキスト）
+	limiter        *scraper.RateLimiter  // 全ジョブで共有するサイトへのリクエスト間隔
+	downloadRoot   string                // ダウンロード先のルート。APIで指定するアカウントのダウンロード先はこの配下に限る
+	workers        int                   // 1ジョブで同時に処理するアカウント数
+
+	// ctx はサービス全体のライフタイム。Shutdownでキャンセルされる
+	ctx     context.Context
+	cancel  context.CancelFunc
+	jobRuns map[string]*jobRun
+	running sync.WaitGroup
+}
+
+// jobRun は実行中のジョブを止めるためのハンドル
+type jobRun struct {
+	cancel context.CancelCauseFunc
+	done   chan struct{} // ジョブのゴルーチンが終了すると閉じる
 }
 
+// ジョブ内の同時処理数とサイトへのリクエスト間隔の既定値
+const (
+	DefaultDownloadWorkers = 3
+	DefaultRequestInterval = 500 * time.Millisecond
+)
+
+// DefaultDownloadRoot は ETC_DOWNLOAD_ROOT が未設定の場合のダウンロード先のルート
+const DefaultDownloadRoot = "./downloads"
+
 // DownloadJob はダウンロードジョブの状態
 type DownloadJob struct {
-	ID           string
-	Status       string
-	Progress     int
-	TotalRecords int
-	ErrorMessage string
-	StartedAt    time.Time
-	CompletedAt  *time.Time
+	ID              string            `json:"id"`
+	Status          string            `json:"status"`   // processing, completed, partially_failed, failed, cancelled, interrupted
+	Progress        int               `json:"progress"` // 完了した処理の割合（各アカウントの進捗の平均）
+	Accounts        []AccountResult   `json:"accounts,omitempty"`
+	TotalRecords    int               `json:"total_records"`           // 解析できた明細の件数（各アカウントの合計）
+	RejectedRows    int               `json:"rejected_rows"`           // 検証で除外した行数（各アカウントの合計）
+	BytesDownloaded int64             `json:"bytes_downloaded"`        // ダウンロードしたCSVのバイト数（各アカウントの合計）
+	RetryCount      int               `json:"retry_count"`             // スクレイパーの各ステップで発生したリトライの合計
+	ErrorCode       string            `json:"error_code,omitempty"`    // 最後に失敗した処理のエラーコード（例: INVALID_CREDENTIALS）
+	ErrorDetails    map[string]string `json:"error_details,omitempty"` // 最後に失敗した処理の詳細（step, evidence_path など）
+	ErrorMessage    string            `json:"error_message,omitempty"`
+	StartedAt       time.Time         `json:"started_at"`
+	CompletedAt     *time.Time        `json:"completed_at,omitempty"`
+	CancelledAt     *time.Time        `json:"cancelled_at,omitempty"` // キャンセルが要求された日時
+}
+
+// AccountResult はジョブ内の1アカウントの進捗と結果
+type AccountResult struct {
+	AccountID       string     `json:"account_id"`
+	Status          string     `json:"status"`   // pending, processing, completed, failed, cancelled, interrupted
+	Progress        int        `json:"progress"` // ダウンロード済みの期間ウィンドウの割合（終了したアカウントは100）
+	CSVPath         string     `json:"csv_path,omitempty"`
+	RecordCount     int        `json:"record_count"`     // 解析できた明細の件数
+	RejectedRows    int        `json:"rejected_rows"`    // 検証で除外した行数
+	BytesDownloaded int64      `json:"bytes_downloaded"` // ダウンロードしたCSVのバイト数（期間ウィンドウごとの合計）
+	Attempts        int        `json:"attempts"`         // スクレイパーの各ステップの試行回数の合計（リトライを含む）
+	ErrorCode       string     `json:"error_code,omitempty"`
+	ErrorMessage    string     `json:"error_message,omitempty"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
 }
 
+// Duration はアカウントの処理時間を返す（処理中の場合は開始からの経過時間、未着手の場合は0）
+func (a AccountResult) Duration() time.Duration {
+	switch {
+	case a.StartedAt == nil:
+		return 0
+	case a.CompletedAt == nil:
+		return time.Since(*a.StartedAt)
+	}
+	return a.CompletedAt.Sub(*a.StartedAt)
+}
+
+// ジョブの状態に関するエラー
+var (
+	ErrJobInterrupted     = errors.New("job was interrupted by a service restart") // 処理中にサービスが停止した
+	ErrJobCancelled       = errors.New("job cancelled by request")                 // CancelJob で中断された
+	ErrJobAlreadyFinished = errors.New("job has already finished")                 // 終了済みのジョブはキャンセルできない
+)
+
 // DownloadServiceInterface はダウンロードサービスのインターフェース
 type DownloadServiceInterface interface {
 	GetAllAccountIDs() []string
+	ResolveAccounts(accounts []string) ([]string, error)
 	ProcessAsync(jobID string, accounts []string, fromDate, toDate string)
 	GetJobStatus(jobID string) (*DownloadJob, bool)
+	CancelJob(ctx context.Context, jobID string) (*DownloadJob, error)
+	ListJobs(filter JobFilter) (*JobPage, error)
+	DownloadSync(ctx context.Context, accounts []string, fromDate, toDate string) (*models.DownloadResult, error)
 }
 
 // NewDownloadService creates a new download service
@@ -47,57 +127,142 @@ func NewDownloadService(db *sql.DB, logger *log.Logger) *DownloadService {
 
 // NewDownloadServiceWithFactory creates a new download service with a custom scraper factory
 func NewDownloadServiceWithFactory(db *sql.DB, logger *log.Logger, factory ScraperFactory) *DownloadService {
-	return &DownloadService{
+	return NewDownloadServiceWithStore(db, logger, factory, jobStoreFromEnv(logger))
+}
+
+// NewDownloadServiceWithStore creates a new download service that keeps its jobs in store.
+// Jobs left processing by a previous run are marked interrupted.
+func NewDownloadServiceWithStore(db *sql.DB, logger *log.Logger, factory ScraperFactory, store JobStore) *DownloadService {
+	ctx, cancel := context.WithCancel(context.Background())
+	accounts := accountRegistryFromEnv(logger)
+	s := &DownloadService{
 		db:             db,
 		logger:         logger,
-		jobs:           make(map[string]*DownloadJob),
+		jobs:           store,
 		scraperFactory: factory,
+		accounts:       accounts,
+		secrets:        &accountSecrets{provider: secretProviderFromEnv(logger), accounts: accounts},
+		sessions:       sessionStoreFromEnv(logger),
+		selectors:      selectorPackFromEnv(logger),
+		browsers:       browserPoolFromEnv(logger),
+		limiter:        scraper.NewRateLimiter(durationFromEnv(logger, "ETC_REQUEST_INTERVAL", DefaultRequestInterval)),
+		workers:        positiveIntFromEnv(logger, "ETC_DOWNLOAD_WORKERS", DefaultDownloadWorkers),
+		downloadRoot:   downloadRootFromEnv(),
+		ctx:            ctx,
+		cancel:         cancel,
+		jobRuns:        make(map[string]*jobRun),
 	}
+	s.interruptJobs()
+	return s
 }
 
-// GetAllAccountIDs は設定されているすべてのアカウントIDを取得
-func (s *DownloadService) GetAllAccountIDs() []string {
-	var accountIDs []string
-
-	// 法人アカウント
-	corporateAccounts := os.Getenv("ETC_CORPORATE_ACCOUNTS")
-	if corporateAccounts != "" {
-		for _, accountStr := range strings.Split(corporateAccounts, ",") {
-			parts := strings.Split(accountStr, ":")
-			if len(parts) >= 1 {
-				accountIDs = append(accountIDs, parts[0])
+// interruptJobs は前回の起動時に処理中のまま終わったジョブを interrupted にする
+// 認証情報はジョブに保存しないため再開はせず、クライアントに再実行を任せる
+func (s *DownloadService) interruptJobs() {
+	for _, job := range s.jobs.List() {
+		if job.Status != "processing" {
+			continue
+		}
+		s.updateJob(job.ID, func(job *DownloadJob) {
+			now := time.Now()
+			job.Status = "interrupted"
+			job.ErrorCode = ErrorCode(ErrJobInterrupted)
+			job.ErrorMessage = ErrJobInterrupted.Error()
+			job.CompletedAt = &now
+			for i := range job.Accounts {
+				if account := &job.Accounts[i]; account.Status == "pending" || account.Status == "processing" {
+					account.Status = "interrupted"
+				}
 			}
+		})
+		if s.logger != nil {
+			s.logger.Printf("Marked download job %s as interrupted", job.ID)
 		}
 	}
+}
 
-	// 個人アカウント
-	personalAccounts := os.Getenv("ETC_PERSONAL_ACCOUNTS")
-	if personalAccounts != "" {
-		for _, accountStr := range strings.Split(personalAccounts, ",") {
-			parts := strings.Split(accountStr, ":")
-			if len(parts) >= 1 {
-				accountIDs = append(accountIDs, parts[0])
-			}
-		}
+// Shutdown は実行中のすべてのジョブをキャンセルし、ブラウザが閉じられるまで ctx の期限内で待機
+func (s *DownloadService) Shutdown(ctx context.Context) error {
+	if s.logger != nil {
+		s.logger.Println("Shutting down download service, cancelling running jobs")
+	}
+	s.cancel()
+	// ジョブの終了を待ってから共有ブラウザとジョブの保存先を閉じる（時間切れの場合も閉じる）
+	defer s.jobs.Close()
+	defer s.browsers.Close()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("download jobs did not stop in time: %w", ctx.Err())
 	}
+}
 
-	return accountIDs
+// GetAllAccountIDs は設定されているすべてのアカウントIDを取得（パスワードは含まない）
+func (s *DownloadService) GetAllAccountIDs() []string {
+	return s.accounts.AccountIDs()
 }
 
 // ProcessAsync は非同期でダウンロードを実行
 func (s *DownloadService) ProcessAsync(jobID string, accounts []string, fromDate, toDate string) {
-	s.jobMutex.Lock()
+	s.ProcessAsyncContext(s.ctx, jobID, accounts, fromDate, toDate)
+}
+
+// ProcessAsyncContext は非同期でダウンロードを実行。ctxがキャンセルされるとジョブは cancelled になる
+func (s *DownloadService) ProcessAsyncContext(ctx context.Context, jobID string, accounts []string, fromDate, toDate string) {
 	job := &DownloadJob{
 		ID:        jobID,
 		Status:    "processing",
 		Progress:  0,
+		Accounts:  make([]AccountResult, len(accounts)),
 		StartedAt: time.Now(),
 	}
-	s.jobs[jobID] = job
+	for i, account := range accounts {
+		job.Accounts[i] = AccountResult{AccountID: account, Status: "pending"}
+	}
+
+	// サイトで検索可能な月単位のウィンドウに分割
+	windows, err := PlanDateRanges(fromDate, toDate)
+	if err != nil {
+		s.jobMutex.Lock()
+		s.saveJob(job)
+		s.jobMutex.Unlock()
+		if s.logger != nil {
+			s.logger.Printf("Rejected download job %s: %v", jobID, err)
+		}
+		s.recordJobError(jobID, "", err)
+		s.updateJobStatus(jobID, "failed", 0, err.Error())
+		return
+	}
+
+	// サービス停止時と CancelJob でもキャンセルされるようにジョブ用のコンテキストを作成
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	stopShutdown := context.AfterFunc(s.ctx, func() { cancel(context.Canceled) })
+	run := &jobRun{cancel: cancel, done: make(chan struct{})}
+	s.jobMutex.Lock()
+	s.saveJob(job)
+	s.jobRuns[jobID] = run
 	s.jobMutex.Unlock()
 
-	// ダウンロード処理をシミュレート
+	// アカウントのダウンロードはバックグラウンドで実行し、ジョブの状態で進捗を返す
+	s.running.Add(1)
 	go func() {
+		defer s.running.Done()
+		defer func() {
+			stopShutdown()
+			cancel(nil)
+			s.jobMutex.Lock()
+			delete(s.jobRuns, jobID)
+			s.jobMutex.Unlock()
+			close(run.done)
+		}()
 		defer func() {
 			if r := recover(); r != nil {
 				if s.logger != nil {
@@ -108,143 +273,648 @@ func (s *DownloadService) ProcessAsync(jobID string, accounts []string, fromDate
 		}()
 
 		if s.logger != nil {
-			s.logger.Printf("Starting download job %s for %d accounts from %s to %s",
-				jobID, len(accounts), fromDate, toDate)
+			s.logger.Printf("Starting download job %s for %d accounts from %s to %s (%d windows)",
+				jobID, len(accounts), fromDate, toDate, len(windows))
 		}
 
 		// Create a shared session folder for all accounts in this job
-		sessionFolder := fmt.Sprintf("./downloads/%s", time.Now().Format("20060102_150405"))
+		sessionFolder, err := s.newSessionFolder()
+		if err != nil {
+			s.recordJobError(jobID, "", err)
+			s.updateJobStatus(jobID, "failed", 0, err.Error())
+			return
+		}
 
-		// 各アカウントを処理
-		totalAccounts := len(accounts)
-		for i, account := range accounts {
-			// 進捗更新
-			progress := int(float64(i+1) / float64(totalAccounts) * 100)
-			s.updateJobProgress(jobID, progress)
+		// 各アカウントを最大 s.workers 並列で処理
+		s.runAccounts(jobCtx, len(accounts), func(i int) {
+			s.processAccount(jobCtx, jobID, i, accounts[i], windows, sessionFolder)
+		})
 
-			// 実際のダウンロード処理（セッションフォルダを渡す）
-			if err := s.downloadAccountData(account, fromDate, toDate, sessionFolder); err != nil {
-				if s.logger != nil {
-					s.logger.Printf("Error downloading data for account %s: %v", account, err)
+		// キャンセル（ダウンロード済みのファイルは残す）
+		if jobCtx.Err() != nil {
+			err := context.Cause(jobCtx)
+			s.updateJob(jobID, func(job *DownloadJob) {
+				now := time.Now()
+				job.Status = "cancelled"
+				job.ErrorMessage = fmt.Sprintf("Job cancelled: %v", err)
+				job.CompletedAt = &now
+				if job.CancelledAt == nil {
+					job.CancelledAt = &now
+				}
+				for i := range job.Accounts {
+					if account := &job.Accounts[i]; account.Status == "pending" {
+						account.Status = "cancelled"
+					}
 				}
-				// エラーがあってもほかのアカウントの処理は続ける
+			})
+			if s.logger != nil {
+				s.logger.Printf("Cancelled download job %s: %v", jobID, err)
 			}
-
-			// レート制限のため少し待機
-			time.Sleep(time.Second)
+			return
 		}
 
-		// 完了
-		now := time.Now()
-		s.jobMutex.Lock()
-		if job, exists := s.jobs[jobID]; exists {
-			job.Status = "completed"
+		// 完了（アカウントの結果からジョブ全体のステータスを決める）
+		var status string
+		s.updateJob(jobID, func(job *DownloadJob) {
+			now := time.Now()
+			job.Status = accountsOutcome(job.Accounts)
 			job.Progress = 100
 			job.CompletedAt = &now
+			status = job.Status
+		})
+
+		if s.logger != nil {
+			s.logger.Printf("Finished download job %s: %s", jobID, status)
 		}
-		s.jobMutex.Unlock()
+	}()
+}
+
+// CancelJob は実行中のジョブをキャンセルし、ジョブが止まるまで ctx の期限内で待って状態を返す
+// 未着手のアカウントは処理せず、処理中のブラウザ操作は中断する。ダウンロード済みのファイルは残す
+// キャンセル済みのジョブはそのまま返し、その他の終了済みのジョブは ErrJobAlreadyFinished を返す
+func (s *DownloadService) CancelJob(ctx context.Context, jobID string) (*DownloadJob, error) {
+	var run *jobRun
+	s.updateJob(jobID, func(job *DownloadJob) {
+		if run = s.jobRuns[jobID]; run != nil && job.CancelledAt == nil {
+			now := time.Now()
+			job.CancelledAt = &now
+		}
+	})
+
+	if run == nil {
+		job, exists := s.jobs.Get(jobID)
+		switch {
+		case !exists:
+			return nil, ErrJobNotFound
+		case job.Status == "cancelled":
+			return job, nil
+		default:
+			return nil, fmt.Errorf("%w (status: %s)", ErrJobAlreadyFinished, job.Status)
+		}
+	}
+
+	if s.logger != nil {
+		s.logger.Printf("Cancelling download job %s", jobID)
+	}
+	run.cancel(ErrJobCancelled)
+
+	select {
+	case <-run.done:
+	case <-ctx.Done():
+		// 停止を待たずに現在の状態を返す（ジョブは後から cancelled になる）
+	}
+	job, _ := s.jobs.Get(jobID)
+	return job, nil
+}
+
+// DownloadSync は指定アカウントの明細をctxの期限内でダウンロードし、CSVを解析して返す
+// アカウントごとの失敗は DownloadResult.Error にまとめ、期間が不正な場合とダウンロード先を作成できない場合のみ error を返す
+func (s *DownloadService) DownloadSync(ctx context.Context, accounts []string, fromDate, toDate string) (*models.DownloadResult, error) {
+	windows, err := PlanDateRanges(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// サービス停止時にも中断し、Shutdownが完了を待てるようにする
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stopShutdown := context.AfterFunc(s.ctx, cancel)
+	defer stopShutdown()
+	s.running.Add(1)
+	defer s.running.Done()
+
+	sessionFolder, err := s.newSessionFolder()
+	if err != nil {
+		return nil, err
+	}
+
+	type outcome struct {
+		started bool
+		csvPath string
+		records []models.ETCMeisaiRecord
+		err     error
+	}
+	outcomes := make([]outcome, len(accounts))
+	s.runAccounts(ctx, len(accounts), func(i int) {
+		csvPath, err := s.downloadAccount(ctx, "", i, accounts[i], windows, sessionFolder)
+		var records []models.ETCMeisaiRecord
+		if err == nil {
+			records, _, err = s.parseAccountCSV(accounts[i], csvPath)
+		}
+		outcomes[i] = outcome{started: true, csvPath: csvPath, records: records, err: err}
+	})
+
+	result := &models.DownloadResult{Success: true, Records: []models.ETCMeisaiRecord{}}
+	var csvPaths, failures []string
+	for i, outcome := range outcomes {
+		userID := accounts[i]
+		if !outcome.started {
+			outcome.err = fmt.Errorf("not started: %w", ctx.Err())
+		}
+		if outcome.err != nil {
+			if s.logger != nil {
+				s.logger.Printf("Sync download failed for account %s: %v", userID, outcome.err)
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", userID, outcome.err))
+			continue
+		}
+		csvPaths = append(csvPaths, outcome.csvPath)
+		result.Records = append(result.Records, outcome.records...)
+	}
+
+	switch len(csvPaths) {
+	case 0:
+	case 1:
+		result.CSVPath = csvPaths[0]
+	default:
+		// 複数アカウントのCSVは1ファイルにまとめて返す
+		result.CSVPath = filepath.Join(filepath.Dir(csvPaths[0]), fmt.Sprintf("meisai_%s_%s.csv",
+			windows[0].From.Format("20060102"), windows[len(windows)-1].To.Format("20060102")))
+		if _, err := MergeCSVFiles(csvPaths, result.CSVPath); err != nil {
+			result.CSVPath = ""
+			failures = append(failures, err.Error())
+		}
+	}
+
+	result.RecordCount = len(result.Records)
+	if len(failures) > 0 {
+		result.Success = false
+		result.Error = strings.Join(failures, "; ")
+	}
+	return result, nil
+}
+
+// DownloadBuffer は指定アカウントの明細をctxの期限内でダウンロードし、CSVの内容をファイルに残さずに返す
+// 1アカウント・1ウィンドウの場合はサイトのCSVをそのまま返し、複数の場合は重複行を除いて結合する
+// 1アカウントでも失敗した場合は残りを中断して error を返す
+func (s *DownloadService) DownloadBuffer(ctx context.Context, accounts []string, fromDate, toDate string) ([]byte, error) {
+	windows, err := PlanDateRanges(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// サービス停止時にも中断し、Shutdownが完了を待てるようにする
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stopShutdown := context.AfterFunc(s.ctx, cancel)
+	defer stopShutdown()
+	s.running.Add(1)
+	defer s.running.Done()
+
+	sessionFolder, err := s.newSessionFolder()
+	if err != nil {
+		return nil, err
+	}
+	// CSVはフォルダに残らないため、失敗時の証跡がなければフォルダも削除する
+	defer os.Remove(sessionFolder)
+
+	contents := make([][][]byte, len(accounts))
+	var failure error
+	var failOnce sync.Once
+	s.runAccounts(ctx, len(accounts), func(i int) {
+		parts, err := s.downloadAccountBuffer(ctx, accounts[i], windows, sessionFolder)
+		if err != nil {
+			failOnce.Do(func() {
+				failure = &AccountError{AccountID: accounts[i], Err: err}
+				cancel() // 結果を返せないため残りのアカウントは処理しない
+			})
+			return
+		}
+		contents[i] = parts
+	})
+	if failure != nil {
+		return nil, failure
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var parts [][]byte
+	for _, accountParts := range contents {
+		parts = append(parts, accountParts...)
+	}
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	merged, _ := MergeCSV(parts)
+	return merged, nil
+}
+
+// downloadAccountBuffer は単一アカウントの各期間ウィンドウのCSVをメモリ上にダウンロード
+// パニックはこのアカウントの失敗として返す
+func (s *DownloadService) downloadAccountBuffer(ctx context.Context, account string, windows []scraper.DateRange, sessionFolder string) (parts [][]byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("internal error: %v", r)
+		}
+	}()
+
+	err = s.withAccountScraper(ctx, "", 0, account, sessionFolder, func(userID string, etcScraper scraper.ContextScraper) error {
+		for _, window := range windows {
+			from := window.From.Format("2006-01-02")
+			to := window.To.Format("2006-01-02")
+
+			data, err := scraper.DownloadToBuffer(ctx, etcScraper, from, to)
+			if err != nil {
+				return fmt.Errorf("download failed for account %s (%s - %s): %w", userID, from, to, err)
+			}
+			parts = append(parts, data)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parts, nil
+}
+
+// parseAccountCSV はダウンロードしたCSVを解析し、アカウントとダウンロード日時を設定したレコードと除外した行数を返す
+// 解析できない行はログに記録してスキップする
+func (s *DownloadService) parseAccountCSV(userID, csvPath string) ([]models.ETCMeisaiRecord, int, error) {
+	parsed, err := parser.ParseFile(csvPath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse CSV for account %s: %w", userID, err)
+	}
+	for _, lineErr := range parsed.Errors {
+		logWarning(s.logger, "skipped row of %s: %v", csvPath, lineErr)
+	}
+
+	now := time.Now()
+	for i := range parsed.Records {
+		parsed.Records[i].AccountID = userID
+		parsed.Records[i].DownloadedAt = now
+	}
+	return parsed.Records, len(parsed.Errors), nil
+}
+
+// runAccounts は n 件のアカウントを最大 s.workers 並列で処理する
+// ctx が終了すると未着手のアカウントは処理しない
+func (s *DownloadService) runAccounts(ctx context.Context, n int, process func(i int)) {
+	queue := make(chan int)
+	var workers sync.WaitGroup
+	for w := 0; w < min(s.workers, n); w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := range queue {
+				if ctx.Err() != nil {
+					continue
+				}
+				process(i)
+			}
+		}()
+	}
 
+enqueue:
+	for i := 0; i < n; i++ {
+		select {
+		case queue <- i:
+		case <-ctx.Done():
+			break enqueue
+		}
+	}
+	close(queue)
+	workers.Wait()
+}
+
+// newSessionFolder はダウンロード先のルートにこの実行のフォルダ（作成時刻_一意な接尾辞）を作成する
+// 同じ秒に開始したジョブがフォルダを共有しないよう os.MkdirTemp で作成する
+func (s *DownloadService) newSessionFolder() (string, error) {
+	if err := os.MkdirAll(s.downloadRoot, 0755); err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+	folder, err := os.MkdirTemp(s.downloadRoot, time.Now().Format("20060102_150405_")+"*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create session folder: %w", err)
+	}
+	return folder, nil
+}
+
+// processAccount はジョブ内の index 番目のアカウントをダウンロードし、結果をジョブに記録
+func (s *DownloadService) processAccount(ctx context.Context, jobID string, index int, account string, windows []scraper.DateRange, sessionFolder string) {
+	startedAt := time.Now()
+	s.updateAccount(jobID, index, func(result *AccountResult) {
+		result.Status = "processing"
+		result.StartedAt = &startedAt
+	})
+
+	csvPath, err := s.downloadAccount(ctx, jobID, index, account, windows, sessionFolder)
+	var records []models.ETCMeisaiRecord
+	rejected := 0
+	if err == nil {
+		// 解析できなくてもダウンロード自体は成功として扱う
+		var parseErr error
+		if records, rejected, parseErr = s.parseAccountCSV(account, csvPath); parseErr != nil {
+			logWarning(s.logger, "failed to count records of account %s: %v", account, parseErr)
+		}
+	}
+	if err != nil {
 		if s.logger != nil {
-			s.logger.Printf("Completed download job %s", jobID)
+			s.logger.Printf("Error downloading data for account %s: %v", account, err)
+		}
+		s.recordJobError(jobID, account, err)
+	}
+
+	s.updateAccount(jobID, index, func(result *AccountResult) {
+		completedAt := time.Now()
+		result.Progress = 100
+		result.CompletedAt = &completedAt
+		switch {
+		case err == nil:
+			result.Status = "completed"
+			result.CSVPath = csvPath
+			result.RecordCount = len(records)
+			result.RejectedRows = rejected
+		case ctx.Err() != nil:
+			result.Status = "cancelled"
+			result.ErrorCode = ErrorCode(err)
+			result.ErrorMessage = err.Error()
+		default:
+			result.Status = "failed"
+			result.ErrorCode = ErrorCode(err)
+			result.ErrorMessage = err.Error()
+		}
+	})
+}
+
+// downloadAccount は downloadAccountData を実行し、パニックはこのアカウントの失敗として返す
+func (s *DownloadService) downloadAccount(ctx context.Context, jobID string, index int, account string, windows []scraper.DateRange, sessionFolder string) (csvPath string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("internal error: %v", r)
 		}
 	}()
+	return s.downloadAccountData(ctx, jobID, index, account, windows, sessionFolder)
+}
+
+// downloadAccountData は単一アカウントのデータを期間ウィンドウごとにダウンロードし、1つのCSVにまとめる
+// jobID が空の場合（同期ダウンロード）はジョブの状態を更新しない
+func (s *DownloadService) downloadAccountData(ctx context.Context, jobID string, index int, accountID string, windows []scraper.DateRange, sessionFolder string) (string, error) {
+	var csvPath string
+	err := s.withAccountScraper(ctx, jobID, index, accountID, sessionFolder, func(userID string, etcScraper scraper.ContextScraper) error {
+		var err error
+		csvPath, err = s.downloadWindows(ctx, jobID, index, userID, etcScraper, windows)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return csvPath, nil
 }
 
-// downloadAccountData は単一アカウントのデータをダウンロード
-func (s *DownloadService) downloadAccountData(accountID, fromDate, toDate, sessionFolder string) error {
-	// アカウント情報の解析（accountID:password形式）
-	parts := strings.Split(accountID, ":")
-	if len(parts) < 2 {
-		return fmt.Errorf("invalid account format: %s (expected accountID:password)", accountID)
+// withAccountScraper は登録済みのアカウントのスクレイパーを作成してログインし、fn を実行する
+// スクレイパーは fn の終了後に閉じる。jobID が空でなければ試行回数をジョブの index 番目のアカウントに反映する
+func (s *DownloadService) withAccountScraper(ctx context.Context, jobID string, index int, accountID string, sessionFolder string, fn func(userID string, etcScraper scraper.ContextScraper) error) error {
+	config, err := s.accountScraperConfig(accountID, sessionFolder)
+	if err != nil {
+		return err
 	}
+	return s.withScraper(ctx, jobID, index, config, fn)
+}
 
-	userID := parts[0]
-	password := parts[1]
+// accountScraperConfig は登録済みのアカウントのスクレイパーの設定を作成する
+// パスワードはスクレイパーがログインの直前に s.secrets から取得する
+func (s *DownloadService) accountScraperConfig(accountID string, sessionFolder string) (*scraper.ScraperConfig, error) {
+	account, err := s.accounts.Account(accountID)
+	if err != nil {
+		return nil, &AccountError{AccountID: accountID, Err: err}
+	}
 
 	// スクレイパーの設定
 	config := &scraper.ScraperConfig{
-		UserID:        userID,
-		Password:      password,
-		DownloadPath:  "./downloads",
+		UserID:        accountID,
+		BaseURL:       os.Getenv("ETC_BASE_URL"),        // 未設定なら本番サイト
+		Backend:       os.Getenv("ETC_SCRAPER_BACKEND"), // 未設定ならPlaywright
+		DownloadPath:  s.downloadRoot,
 		SessionFolder: sessionFolder, // Use shared session folder
 		Headless:      getHeadlessMode(),
 		Timeout:       30000,
 		RetryCount:    3,
+		RetryBackoff:  scraper.DefaultRetryBackoff,
+		RetryJitter:   scraper.DefaultRetryJitter,
+		Sessions:      s.sessions,
+		Selectors:     s.selectors,
+		Browsers:      s.browsers,
+		Limiter:       s.limiter,
+		Secrets:       s.secrets,
 	}
+	applyAccountSettings(config, account)
+	return config, nil
+}
+
+// withScraper は config のスクレイパーを作成してログインし、fn を実行する
+// スクレイパーは fn の終了後に閉じる。jobID が空でなければ試行回数をジョブの index 番目のアカウントに反映する
+func (s *DownloadService) withScraper(ctx context.Context, jobID string, index int, config *scraper.ScraperConfig, fn func(userID string, etcScraper scraper.ContextScraper) error) error {
+	userID := config.UserID
 
 	// スクレイパー作成
-	etcScraper, err := s.scraperFactory.CreateScraper(config, s.logger)
+	etcScraper, err := createContextScraper(ctx, s.scraperFactory, config, s.logger)
 	if err != nil {
 		return fmt.Errorf("failed to create scraper: %w", err)
 	}
 	defer etcScraper.Close()
+	defer func() {
+		// 成否にかかわらず試行回数をジョブに反映
+		s.addAttempts(jobID, index, scraper.AttemptsOf(etcScraper))
+	}()
 
 	// Playwright初期化
-	if err := etcScraper.Initialize(); err != nil {
+	if err := etcScraper.InitializeContext(ctx); err != nil {
 		return fmt.Errorf("failed to initialize scraper: %w", err)
 	}
 
 	// ログイン
-	if err := etcScraper.Login(); err != nil {
+	if err := etcScraper.LoginContext(ctx); err != nil {
 		return fmt.Errorf("login failed for account %s: %w", userID, err)
 	}
 
-	// データダウンロード
-	csvPath, err := etcScraper.DownloadMeisai(fromDate, toDate)
-	if err != nil {
-		return fmt.Errorf("download failed for account %s: %w", userID, err)
+	return fn(userID, etcScraper)
+}
+
+// applyAccountSettings はアカウントごとのダウンロード先とスクレイパーの設定を config に反映する
+func applyAccountSettings(config *scraper.ScraperConfig, account models.ETCAccount) {
+	if account.DownloadDir != "" {
+		// ジョブ内の他のアカウントと同じタイムスタンプのフォルダをアカウントのダウンロード先に作る
+		config.DownloadPath = account.DownloadDir
+		if config.SessionFolder != "" {
+			config.SessionFolder = filepath.Join(account.DownloadDir, filepath.Base(config.SessionFolder))
+		}
+	}
+	if account.Scraper.Timeout > 0 {
+		config.Timeout = float64(account.Scraper.Timeout.Milliseconds())
+	}
+	if headless := account.Scraper.Headless; headless != nil && *headless != config.Headless {
+		// 共有ブラウザは ETC_HEADLESS の設定で起動しているため、このアカウントだけ別のブラウザを起動する
+		config.Headless = *headless
+		config.Browsers = nil
+	}
+}
+
+// downloadWindows はログイン済みの etcScraper で各期間ウィンドウのCSVをダウンロードし、1つのCSVにまとめる
+func (s *DownloadService) downloadWindows(ctx context.Context, jobID string, index int, userID string, etcScraper scraper.ContextScraper, windows []scraper.DateRange) (string, error) {
+	// 同じログインセッションで各ウィンドウをダウンロード
+	var windowPaths []string
+	for i, window := range windows {
+		from := window.From.Format("2006-01-02")
+		to := window.To.Format("2006-01-02")
+
+		csvPath, err := etcScraper.DownloadMeisaiContext(ctx, from, to)
+		if err != nil {
+			return "", fmt.Errorf("download failed for account %s (%s - %s): %w", userID, from, to, err)
+		}
+		var size int64
+		if info, err := os.Stat(csvPath); err == nil {
+			size = info.Size()
+		}
+
+		if len(windows) > 1 {
+			// 同名ファイルで上書きされないようウィンドウごとにリネーム
+			partPath := filepath.Join(filepath.Dir(csvPath),
+				fmt.Sprintf("%s_%s_%s_part.csv", userID, window.From.Format("20060102"), window.To.Format("20060102")))
+			if err := os.Rename(csvPath, partPath); err != nil {
+				return "", fmt.Errorf("failed to rename CSV for account %s: %w", userID, err)
+			}
+			csvPath = partPath
+		}
+		windowPaths = append(windowPaths, csvPath)
+
+		s.updateAccount(jobID, index, func(result *AccountResult) {
+			result.Progress = (i + 1) * 100 / (len(windows) + 1) // 結合処理の分を残す
+			result.BytesDownloaded += size
+		})
+	}
+
+	csvPath := windowPaths[0]
+	if len(windowPaths) > 1 {
+		// ウィンドウごとのCSVを結合して重複を除去
+		first, last := windows[0], windows[len(windows)-1]
+		csvPath = filepath.Join(filepath.Dir(windowPaths[0]),
+			fmt.Sprintf("%s_%s_%s.csv", userID, first.From.Format("20060102"), last.To.Format("20060102")))
+		rowCount, err := MergeCSVFiles(windowPaths, csvPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to merge CSV for account %s: %w", userID, err)
+		}
+		for _, partPath := range windowPaths {
+			os.Remove(partPath)
+		}
+		if s.logger != nil {
+			s.logger.Printf("Merged %d windows for account %s into %s (%d rows)", len(windowPaths), userID, csvPath, rowCount)
+		}
 	}
 
 	if s.logger != nil {
 		s.logger.Printf("Successfully downloaded data for account %s: %s", userID, csvPath)
 	}
 
-	// TODO: CSVファイルをパースしてDBに保存
+	// 明細の解析と件数の集計は呼び出し元が parseAccountCSV で行う
+	return csvPath, nil
+}
+
+// updateAccount はジョブ内の index 番目のアカウントの結果を更新し、ジョブ全体の進捗と件数を再計算
+func (s *DownloadService) updateAccount(jobID string, index int, update func(result *AccountResult)) {
+	s.updateJob(jobID, func(job *DownloadJob) {
+		if index >= len(job.Accounts) {
+			return
+		}
+		update(&job.Accounts[index])
+
+		progress := 0
+		job.TotalRecords, job.RejectedRows, job.BytesDownloaded = 0, 0, 0
+		for _, account := range job.Accounts {
+			progress += account.Progress
+			job.TotalRecords += account.RecordCount
+			job.RejectedRows += account.RejectedRows
+			job.BytesDownloaded += account.BytesDownloaded
+		}
+		job.Progress = progress / len(job.Accounts)
+	})
+}
 
-	return nil
+// recordJobError はジョブに失敗したアカウントのエラーを記録
+func (s *DownloadService) recordJobError(jobID, accountID string, err error) {
+	s.updateJob(jobID, func(job *DownloadJob) {
+		job.ErrorCode = ErrorCode(err)
+		job.ErrorDetails = ErrorDetails(err, accountID)
+		job.ErrorMessage = err.Error()
+	})
 }
 
-// updateJobProgress はジョブの進捗を更新
-func (s *DownloadService) updateJobProgress(jobID string, progress int) {
+// updateJob はジョブを読み込んで update で変更し、保存する。ジョブがなければ何もしない
+func (s *DownloadService) updateJob(jobID string, update func(job *DownloadJob)) {
+	if jobID == "" {
+		return
+	}
+
 	s.jobMutex.Lock()
 	defer s.jobMutex.Unlock()
 
-	if job, exists := s.jobs[jobID]; exists {
-		job.Progress = progress
+	job, exists := s.jobs.Get(jobID)
+	if !exists {
+		return
 	}
+	update(job)
+	s.saveJob(job)
+}
+
+// saveJob はジョブを保存する。保存に失敗してもダウンロードは続けるため警告のみ記録
+// s.jobMutex を保持して呼び出すこと
+func (s *DownloadService) saveJob(job *DownloadJob) {
+	if err := s.jobs.Save(job); err != nil {
+		logWarning(s.logger, "failed to save job %s: %v", job.ID, err)
+	}
+}
+
+// addAttempts はアカウントの試行回数とジョブのリトライ回数を加算
+func (s *DownloadService) addAttempts(jobID string, index int, attempts []scraper.Attempt) {
+	if len(attempts) == 0 {
+		return
+	}
+
+	s.updateJob(jobID, func(job *DownloadJob) {
+		job.RetryCount += scraper.RetryCount(attempts)
+		if index < len(job.Accounts) {
+			job.Accounts[index].Attempts += len(attempts)
+		}
+	})
+}
+
+// accountsOutcome はアカウントの結果からジョブ全体のステータスを返す
+// すべて成功した場合は completed、一部が失敗した場合は partially_failed、すべて失敗した場合は failed
+func accountsOutcome(accounts []AccountResult) string {
+	completed := 0
+	for _, account := range accounts {
+		if account.Status == "completed" {
+			completed++
+		}
+	}
+	switch {
+	case completed == len(accounts):
+		return "completed"
+	case completed > 0:
+		return "partially_failed"
+	}
+	return "failed"
 }
 
 // updateJobStatus はジョブのステータスを更新
 func (s *DownloadService) updateJobStatus(jobID string, status string, progress int, errorMsg string) {
-	s.jobMutex.Lock()
-	defer s.jobMutex.Unlock()
-
-	if job, exists := s.jobs[jobID]; exists {
+	s.updateJob(jobID, func(job *DownloadJob) {
 		job.Status = status
 		job.Progress = progress
 		if errorMsg != "" {
 			job.ErrorMessage = errorMsg
 		}
-		if status == "completed" || status == "failed" {
+		if status == "completed" || status == "failed" || status == "cancelled" {
 			now := time.Now()
 			job.CompletedAt = &now
 		}
-	}
+	})
 }
 
 // GetJobStatus はジョブのステータスを取得
 func (s *DownloadService) GetJobStatus(jobID string) (*DownloadJob, bool) {
-	s.jobMutex.RLock()
-	defer s.jobMutex.RUnlock()
-
-	job, exists := s.jobs[jobID]
-	if !exists {
-		return nil, false
-	}
-
-	// コピーを返す
-	jobCopy := *job
-	return &jobCopy, true
+	// ストアはコピーを返す
+	return s.jobs.Get(jobID)
 }
 
 // GetHeadlessMode は環境変数からHeadlessモードの設定を取得
@@ -273,6 +943,131 @@ func GetHeadlessMode() bool {
 	return headless
 }
 
+// sessionStoreFromEnv は ETC_SESSION_KEY が設定されている場合にログインセッションの保存先を作成
+// ETC_SESSION_DIR（既定: ./sessions）に暗号化して保存し、ETC_SESSION_TTL の間だけ再利用する
+func sessionStoreFromEnv(logger *log.Logger) *scraper.SessionStore {
+	encodedKey := os.Getenv("ETC_SESSION_KEY")
+	if encodedKey == "" {
+		return nil
+	}
+
+	key, err := cryptoutil.ParseKey(encodedKey)
+	if err != nil {
+		logWarning(logger, "ETC_SESSION_KEY is invalid, session reuse disabled: %v", err)
+		return nil
+	}
+
+	ttl := scraper.DefaultSessionTTL
+	if value := os.Getenv("ETC_SESSION_TTL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			logWarning(logger, "ETC_SESSION_TTL %q is invalid, using %v", value, ttl)
+		} else {
+			ttl = parsed
+		}
+	}
+
+	dir := os.Getenv("ETC_SESSION_DIR")
+	if dir == "" {
+		dir = "./sessions"
+	}
+
+	store, err := scraper.NewSessionStore(dir, key, ttl)
+	if err != nil {
+		logWarning(logger, "session reuse disabled: %v", err)
+		return nil
+	}
+	return store
+}
+
+// jobStoreFromEnv は ETC_JOB_STORE_PATH が設定されている場合にファイルのジョブストアを開く
+// 未設定または開けない場合はメモリ上のストア（再起動でジョブが失われる）
+func jobStoreFromEnv(logger *log.Logger) JobStore {
+	path := os.Getenv("ETC_JOB_STORE_PATH")
+	if path == "" {
+		return NewMemoryJobStore()
+	}
+
+	store, err := OpenFileJobStore(path, logger)
+	if err != nil {
+		logWarning(logger, "job history will not survive restarts: %v", err)
+		return NewMemoryJobStore()
+	}
+	return store
+}
+
+// selectorPackFromEnv は ETC_SELECTOR_PACK に指定されたセレクタパックを読み込む
+// 未設定または読み込みに失敗した場合は nil（組み込みのパックを使用）
+func selectorPackFromEnv(logger *log.Logger) *scraper.SelectorPack {
+	path := os.Getenv("ETC_SELECTOR_PACK")
+	if path == "" {
+		return nil
+	}
+
+	pack, err := scraper.LoadSelectorPack(path)
+	if err != nil {
+		logWarning(logger, "using built-in selector pack: %v", err)
+		return nil
+	}
+	if logger != nil {
+		logger.Printf("Using selector pack %s (%s)", pack.Version, path)
+	}
+	return pack
+}
+
+// browserPoolFromEnv は全アカウントで共有するブラウザプールを作成（ブラウザは最初のダウンロード時に起動）
+// ETC_BROWSER_MAX_CONTEXTS で同時に開くコンテキスト数、ETC_BROWSER_MAX_USES でブラウザを入れ替えるまでの利用回数を指定
+func browserPoolFromEnv(logger *log.Logger) *scraper.BrowserPool {
+	config := scraper.BrowserPoolConfig{
+		Headless:    GetHeadlessMode(),
+		MaxContexts: positiveIntFromEnv(logger, "ETC_BROWSER_MAX_CONTEXTS", scraper.DefaultPoolMaxContexts),
+		MaxUses:     positiveIntFromEnv(logger, "ETC_BROWSER_MAX_USES", scraper.DefaultPoolMaxUses),
+	}
+	return scraper.NewBrowserPool(&scraper.DefaultPlaywrightFactory{}, config, logger)
+}
+
+// positiveIntFromEnv は環境変数 name を正の整数として読み込む。未設定または不正な値なら defaultValue
+func positiveIntFromEnv(logger *log.Logger, name string, defaultValue int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		logWarning(logger, "%s %q is invalid, using %d", name, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+// downloadRootFromEnv は ETC_DOWNLOAD_ROOT（未設定なら DefaultDownloadRoot）を返す
+func downloadRootFromEnv() string {
+	if root := os.Getenv("ETC_DOWNLOAD_ROOT"); root != "" {
+		return root
+	}
+	return DefaultDownloadRoot
+}
+
+// durationFromEnv は環境変数 name をGoのduration形式（例: 500ms）で読み込む。未設定または不正な値なら defaultValue
+func durationFromEnv(logger *log.Logger, name string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		logWarning(logger, "%s %q is invalid, using %v", name, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+func logWarning(logger *log.Logger, format string, args ...interface{}) {
+	if logger != nil {
+		logger.Printf("Warning: "+format, args...)
+	}
+}
+
 // getHeadlessMode は後方互換性のため維持（非推奨）
 func getHeadlessMode() bool {
 	return GetHeadlessMode()
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/models"
 	pb "github.com/yhonda-ohishi/etc_meisai_scraper/src/pb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -19,33 +20,60 @@ type DownloadServiceGRPC struct {
 
 // NewDownloadServiceGRPC creates a new gRPC download service
 func NewDownloadServiceGRPC(db *sql.DB, logger *log.Logger) *DownloadServiceGRPC {
+	return NewDownloadServiceGRPCWithService(NewDownloadService(db, logger))
+}
+
+// NewDownloadServiceGRPCWithService creates a new gRPC download service on an existing download service,
+// so that it can share browsers and jobs with other gRPC services
+func NewDownloadServiceGRPCWithService(downloadService DownloadServiceInterface) *DownloadServiceGRPC {
 	return &DownloadServiceGRPC{
-		downloadService: NewDownloadService(db, logger),
+		downloadService: downloadService,
 	}
 }
 
 // NewDownloadServiceGRPCWithMock creates a new gRPC download service with a custom download service
 func NewDownloadServiceGRPCWithMock(downloadService DownloadServiceInterface) *DownloadServiceGRPC {
-	return &DownloadServiceGRPC{
-		downloadService: downloadService,
+	return NewDownloadServiceGRPCWithService(downloadService)
+}
+
+// Shutdown は実行中のダウンロードジョブを停止
+func (s *DownloadServiceGRPC) Shutdown(ctx context.Context) error {
+	if svc, ok := s.downloadService.(interface {
+		Shutdown(ctx context.Context) error
+	}); ok {
+		return svc.Shutdown(ctx)
 	}
+	return nil
 }
 
 // DownloadSync は同期ダウンロードを実行
+// リクエストの期限内でダウンロードと解析を行い、アカウントごとの失敗は error フィールドで返す
 func (s *DownloadServiceGRPC) DownloadSync(ctx context.Context, req *pb.DownloadRequest) (*pb.DownloadResponse, error) {
 	// パラメータのデフォルト値設定
-	fromDate, toDate := s.setDefaultDates(req.FromDate, req.ToDate)
+	fromDate, toDate := setDefaultDates(req.FromDate, req.ToDate)
 
-	// TODO: 実際のダウンロード処理を実装
-	// ここで fromDate と toDate を使用してダウンロード処理を行う
-	_ = fromDate
-	_ = toDate
+	if len(req.Accounts) == 0 {
+		return nil, ScraperErrorStatus(ErrAccountsRequired, "")
+	}
+	accounts, err := s.downloadService.ResolveAccounts(req.Accounts)
+	if err != nil {
+		return nil, ScraperErrorStatus(err, "")
+	}
+
+	result, err := s.downloadService.DownloadSync(ctx, accounts, fromDate, toDate)
+	if err != nil {
+		return nil, ScraperErrorStatus(err, "")
+	}
 
 	response := &pb.DownloadResponse{
-		Success:     true,
-		RecordCount: 0,
-		CsvPath:     "",
-		Records:     []*pb.ETCMeisaiRecord{},
+		Success:     result.Success,
+		RecordCount: int32(result.RecordCount),
+		CsvPath:     result.CSVPath,
+		Records:     make([]*pb.ETCMeisaiRecord, 0, len(result.Records)),
+		Error:       result.Error,
+	}
+	for _, record := range result.Records {
+		response.Records = append(response.Records, meisaiRecordToProto(record))
 	}
 
 	return response, nil
@@ -54,19 +82,17 @@ func (s *DownloadServiceGRPC) DownloadSync(ctx context.Context, req *pb.Download
 // DownloadAsync は非同期でダウンロードを開始
 func (s *DownloadServiceGRPC) DownloadAsync(ctx context.Context, req *pb.DownloadRequest) (*pb.DownloadJobResponse, error) {
 	// パラメータのデフォルト値設定
-	fromDate, toDate := s.setDefaultDates(req.FromDate, req.ToDate)
+	fromDate, toDate := setDefaultDates(req.FromDate, req.ToDate)
+
+	// 期間がサイトで検索可能か事前に検証
+	if _, err := PlanDateRanges(fromDate, toDate); err != nil {
+		return nil, ScraperErrorStatus(err, "")
+	}
 
-	accounts := req.Accounts
-	if len(accounts) == 0 {
-		// デフォルトで全アカウントを使用
-		accounts = s.downloadService.GetAllAccountIDs()
-		if len(accounts) == 0 {
-			return &pb.DownloadJobResponse{
-				JobId:   "",
-				Status:  "failed",
-				Message: "No accounts configured",
-			}, nil
-		}
+	// アカウントIDをサーバー側の認証情報で解決（省略時は全アカウント）
+	accounts, err := s.downloadService.ResolveAccounts(req.Accounts)
+	if err != nil {
+		return nil, ScraperErrorStatus(err, "")
 	}
 
 	// ジョブIDを生成
@@ -86,23 +112,138 @@ func (s *DownloadServiceGRPC) DownloadAsync(ctx context.Context, req *pb.Downloa
 func (s *DownloadServiceGRPC) GetJobStatus(ctx context.Context, req *pb.GetJobStatusRequest) (*pb.JobStatus, error) {
 	job, exists := s.downloadService.GetJobStatus(req.JobId)
 	if !exists {
-		return nil, nil
+		return nil, JobErrorStatus(ErrJobNotFound, req.JobId)
+	}
+
+	return jobStatusToProto(job), nil
+}
+
+// ListJobs は条件に一致するジョブの概要を開始日時順に返す
+func (s *DownloadServiceGRPC) ListJobs(ctx context.Context, req *pb.ListJobsRequest) (*pb.ListJobsResponse, error) {
+	ascending, err := ParseJobOrder(req.OrderBy)
+	if err != nil {
+		return nil, ScraperErrorStatus(err, "")
+	}
+	filter := JobFilter{
+		Statuses:  req.Statuses,
+		AccountID: req.AccountId,
+		PageSize:  int(req.PageSize),
+		PageToken: req.PageToken,
+		Ascending: ascending,
+	}
+	if req.StartedAfter != nil {
+		filter.StartedAfter = req.StartedAfter.AsTime()
+	}
+	if req.StartedBefore != nil {
+		filter.StartedBefore = req.StartedBefore.AsTime()
+	}
+
+	page, err := s.downloadService.ListJobs(filter)
+	if err != nil {
+		return nil, ScraperErrorStatus(err, "")
+	}
+
+	response := &pb.ListJobsResponse{
+		Jobs:          make([]*pb.JobSummary, 0, len(page.Jobs)),
+		NextPageToken: page.NextPageToken,
+	}
+	for _, job := range page.Jobs {
+		response.Jobs = append(response.Jobs, jobSummaryToProto(job))
+	}
+	return response, nil
+}
+
+// jobSummaryToProto はジョブの概要とアカウントごとの結果をgRPCのメッセージに変換
+func jobSummaryToProto(job *DownloadJob) *pb.JobSummary {
+	summary := &pb.JobSummary{
+		JobId:               job.ID,
+		Status:              job.Status,
+		Progress:            int32(job.Progress),
+		TotalRecords:        int32(job.TotalRecords),
+		ErrorCode:           job.ErrorCode,
+		StartedAt:           timestamppb.New(job.StartedAt),
+		AccountStatusCounts: make(map[string]int32),
+		Accounts:            make([]*pb.AccountResult, 0, len(job.Accounts)),
+		RejectedRows:        int32(job.RejectedRows),
+		BytesDownloaded:     job.BytesDownloaded,
+	}
+	if job.CompletedAt != nil {
+		summary.CompletedAt = timestamppb.New(*job.CompletedAt)
+	}
+	if job.CancelledAt != nil {
+		summary.CancelledAt = timestamppb.New(*job.CancelledAt)
+	}
+	for status, count := range job.AccountStatusCounts() {
+		summary.AccountStatusCounts[status] = int32(count)
+	}
+	for _, account := range job.Accounts {
+		summary.Accounts = append(summary.Accounts, accountResultToProto(account))
+	}
+	return summary
+}
+
+// accountResultToProto はアカウントの結果をgRPCのメッセージに変換
+func accountResultToProto(account AccountResult) *pb.AccountResult {
+	result := &pb.AccountResult{
+		AccountId:       account.AccountID,
+		Status:          account.Status,
+		Progress:        int32(account.Progress),
+		ErrorCode:       account.ErrorCode,
+		ErrorMessage:    account.ErrorMessage,
+		CsvPath:         account.CSVPath,
+		RecordCount:     int32(account.RecordCount),
+		Attempts:        int32(account.Attempts),
+		DurationMs:      account.Duration().Milliseconds(),
+		RejectedRows:    int32(account.RejectedRows),
+		BytesDownloaded: account.BytesDownloaded,
+	}
+	if account.StartedAt != nil {
+		result.StartedAt = timestamppb.New(*account.StartedAt)
+	}
+	if account.CompletedAt != nil {
+		result.CompletedAt = timestamppb.New(*account.CompletedAt)
+	}
+	return result
+}
+
+// CancelJob は実行中のジョブをキャンセルし、キャンセル後のステータスを返す
+func (s *DownloadServiceGRPC) CancelJob(ctx context.Context, req *pb.CancelJobRequest) (*pb.JobStatus, error) {
+	job, err := s.downloadService.CancelJob(ctx, req.JobId)
+	if err != nil {
+		return nil, JobErrorStatus(err, req.JobId)
 	}
 
+	return jobStatusToProto(job), nil
+}
+
+// jobStatusToProto はジョブの状態をgRPCのメッセージに変換
+func jobStatusToProto(job *DownloadJob) *pb.JobStatus {
 	status := &pb.JobStatus{
-		JobId:        job.ID,
-		Status:       job.Status,
-		Progress:     int32(job.Progress),
-		TotalRecords: int32(job.TotalRecords),
-		ErrorMessage: job.ErrorMessage,
-		StartedAt:    timestamppb.New(job.StartedAt),
+		JobId:           job.ID,
+		Status:          job.Status,
+		Progress:        int32(job.Progress),
+		TotalRecords:    int32(job.TotalRecords),
+		ErrorMessage:    job.ErrorMessage,
+		StartedAt:       timestamppb.New(job.StartedAt),
+		RetryCount:      int32(job.RetryCount),
+		ErrorCode:       job.ErrorCode,
+		ErrorDetails:    job.ErrorDetails,
+		Accounts:        make([]*pb.AccountResult, 0, len(job.Accounts)),
+		RejectedRows:    int32(job.RejectedRows),
+		BytesDownloaded: job.BytesDownloaded,
 	}
 
 	if job.CompletedAt != nil {
 		status.CompletedAt = timestamppb.New(*job.CompletedAt)
 	}
+	if job.CancelledAt != nil {
+		status.CancelledAt = timestamppb.New(*job.CancelledAt)
+	}
+	for _, account := range job.Accounts {
+		status.Accounts = append(status.Accounts, accountResultToProto(account))
+	}
 
-	return status, nil
+	return status
 }
 
 // GetAllAccountIDs は設定されている全アカウントIDを取得
@@ -113,8 +254,31 @@ func (s *DownloadServiceGRPC) GetAllAccountIDs(ctx context.Context, req *pb.GetA
 	}, nil
 }
 
-// setDefaultDates はデフォルトの日付を設定
-func (s *DownloadServiceGRPC) setDefaultDates(fromDate, toDate string) (string, string) {
+// meisaiRecordToProto は明細レコードをgRPCのメッセージに変換
+func meisaiRecordToProto(record models.ETCMeisaiRecord) *pb.ETCMeisaiRecord {
+	message := &pb.ETCMeisaiRecord{
+		Id:            record.ID,
+		AccountId:     record.AccountID,
+		UsageDate:     timestamppb.New(record.UsageDate),
+		EntryIc:       record.EntryIC,
+		ExitIc:        record.ExitIC,
+		VehicleNumber: record.VehicleNumber,
+		EtcCardNumber: record.ETCCardNumber,
+		Amount:        int32(record.Amount),
+		CsvFileName:   record.CSVFileName,
+		DownloadedAt:  timestamppb.New(record.DownloadedAt),
+	}
+	if !record.CreatedAt.IsZero() {
+		message.CreatedAt = timestamppb.New(record.CreatedAt)
+	}
+	if !record.UpdatedAt.IsZero() {
+		message.UpdatedAt = timestamppb.New(record.UpdatedAt)
+	}
+	return message
+}
+
+// setDefaultDates はデフォルトの日付を設定（終了日は今日、開始日は1か月前）
+func setDefaultDates(fromDate, toDate string) (string, string) {
 	now := time.Now()
 	if toDate == "" {
 		toDate = now.Format("2006-01-02")
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// scraperErrorDomain は ErrorInfo.Domain に設定するエラーの発生元
+const scraperErrorDomain = "etc-meisai.jp"
+
+// リクエストの検証やジョブの参照で返すエラー
+var (
+	ErrJobNotFound          = errors.New("job not found")
+	ErrAccountsRequired     = errors.New("at least one account is required")
+	ErrInvalidAccountFormat = errors.New("invalid account format")
+	ErrNoAccountsConfigured = errors.New("no accounts configured")
+)
+
+// AccountError は特定のアカウントの処理で発生したエラー
+// gRPCステータスに変換すると ErrorInfo.Metadata に account_id が設定される
+type AccountError struct {
+	AccountID string
+	Err       error
+}
+
+func (e *AccountError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *AccountError) Unwrap() error {
+	return e.Err
+}
+
+// ScraperErrorStatus はスクレイパーのエラーを対応するgRPCステータスに変換し、ErrorInfoを付与
+func ScraperErrorStatus(err error, accountID string) error {
+	if err == nil {
+		return nil
+	}
+	return errorStatus(err, ErrorDetails(err, accountID))
+}
+
+// JobErrorStatus はジョブに関するエラーをgRPCステータスに変換し、ErrorInfo.Metadata に job_id を付与
+func JobErrorStatus(err error, jobID string) error {
+	if err == nil {
+		return nil
+	}
+	details := ErrorDetails(err, "")
+	details["job_id"] = jobID
+	return errorStatus(err, details)
+}
+
+// errorStatus は err に対応するgRPCステータスに details を含む ErrorInfo を付与
+func errorStatus(err error, details map[string]string) error {
+	if _, ok := status.FromError(err); ok {
+		// すでにgRPCステータスの場合はそのまま返す
+		return err
+	}
+
+	st := status.New(scraperErrorCode(err), err.Error())
+	detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   ErrorCode(err),
+		Domain:   scraperErrorDomain,
+		Metadata: details,
+	})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// ErrorDetails はエラーの詳細（アカウント、失敗したステップ、証跡の保存先など）を返す
+// gRPCのErrorInfo.Metadataとジョブのエラー詳細で共通
+func ErrorDetails(err error, accountID string) map[string]string {
+	details := map[string]string{}
+	var accountErr *AccountError
+	if accountID == "" && errors.As(err, &accountErr) {
+		accountID = accountErr.AccountID
+	}
+	if accountID != "" {
+		details["account_id"] = accountID
+	}
+	var stepErr *scraper.StepError
+	if errors.As(err, &stepErr) {
+		details["step"] = stepErr.Step
+		details["attempts"] = strconv.Itoa(stepErr.Attempts)
+		if stepErr.Evidence != "" {
+			details["evidence_path"] = stepErr.Evidence
+		}
+	}
+	var loginErr *scraper.LoginError
+	if errors.As(err, &loginErr) {
+		details["site_message"] = loginErr.Message
+	}
+	var selectorErr *scraper.SelectorError
+	if errors.As(err, &selectorErr) {
+		details["element"] = selectorErr.Element
+		details["selectors"] = strings.Join(selectorErr.Selectors, ", ")
+	}
+	return details
+}
+
+// ErrorCode はジョブやAPIレスポンスで返すエラーコードを返す
+func ErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrRangeOutsideRetention):
+		return "RANGE_OUTSIDE_RETENTION"
+	case errors.Is(err, ErrJobNotFound):
+		return "JOB_NOT_FOUND"
+	case errors.Is(err, ErrAccountsRequired):
+		return "ACCOUNTS_REQUIRED"
+	case errors.Is(err, ErrInvalidAccountFormat):
+		return "INVALID_ACCOUNT_FORMAT"
+	case errors.Is(err, ErrUnknownAccount):
+		return "UNKNOWN_ACCOUNT"
+	case errors.Is(err, ErrAccountDisabled):
+		return "ACCOUNT_DISABLED"
+	case errors.Is(err, ErrAccountExists):
+		return "ACCOUNT_EXISTS"
+	case errors.Is(err, ErrInvalidAccount):
+		return "INVALID_ACCOUNT"
+	case errors.Is(err, ErrAccountsReadOnly):
+		return "ACCOUNTS_READ_ONLY"
+	case errors.Is(err, ErrVaultRequired):
+		return "CREDENTIAL_VAULT_REQUIRED"
+	case errors.Is(err, ErrNoAccountsConfigured):
+		return "NO_ACCOUNTS_CONFIGURED"
+	case errors.Is(err, ErrJobInterrupted):
+		return "JOB_INTERRUPTED"
+	case errors.Is(err, ErrJobCancelled):
+		return "JOB_CANCELLED"
+	case errors.Is(err, ErrJobAlreadyFinished):
+		return "JOB_ALREADY_FINISHED"
+	case errors.Is(err, ErrInvalidJobFilter):
+		return "INVALID_JOB_FILTER"
+	case errors.Is(err, ErrInvalidPageToken):
+		return "INVALID_PAGE_TOKEN"
+	}
+	return scraper.ErrorCode(err)
+}
+
+// scraperErrorCode はスクレイパーのエラー種別に対応するgRPCコードを返す
+func scraperErrorCode(err error) codes.Code {
+	var rangeErr *scraper.DateRangeError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, ErrJobCancelled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, scraper.ErrDownloadTimeout):
+		return codes.DeadlineExceeded
+	case errors.Is(err, scraper.ErrInvalidCredentials), errors.Is(err, scraper.ErrSessionExpired):
+		return codes.Unauthenticated
+	case errors.Is(err, scraper.ErrAccountLocked):
+		return codes.PermissionDenied
+	case errors.Is(err, scraper.ErrPasswordExpired), errors.Is(err, ErrNoAccountsConfigured),
+		errors.Is(err, ErrJobAlreadyFinished), errors.Is(err, ErrAccountDisabled), errors.Is(err, ErrAccountsReadOnly),
+		errors.Is(err, ErrVaultRequired):
+		return codes.FailedPrecondition
+	case errors.Is(err, ErrAccountExists):
+		return codes.AlreadyExists
+	case errors.Is(err, scraper.ErrSiteMaintenance), errors.Is(err, scraper.ErrBrowserCrash):
+		return codes.Unavailable
+	case errors.Is(err, scraper.ErrNoResults), errors.Is(err, ErrJobNotFound), errors.Is(err, ErrUnknownAccount):
+		return codes.NotFound
+	case errors.As(err, &rangeErr), errors.Is(err, ErrRangeOutsideRetention),
+		errors.Is(err, ErrAccountsRequired), errors.Is(err, ErrInvalidAccountFormat), errors.Is(err, ErrInvalidAccount),
+		errors.Is(err, ErrInvalidJobFilter), errors.Is(err, ErrInvalidPageToken):
+		return codes.InvalidArgument
+	case scraper.ClassifyError(err) == scraper.ErrorClassTransient:
+		// net::ERR_* のナビゲーションエラーやHTTPバックエンドの5xxなど、再試行で回復しうるサイトやネットワークの障害
+		return codes.Unavailable
+	default:
+		// ErrSelectorDrift を含む想定外のエラー
+		return codes.Internal
+	}
+}
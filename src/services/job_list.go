@@ -0,0 +1,174 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListJobs のページサイズ
+const (
+	DefaultJobPageSize = 50
+	MaxJobPageSize     = 500
+)
+
+// ListJobs のリクエストが不正な場合のエラー
+var (
+	ErrInvalidJobFilter = errors.New("invalid job filter")
+	ErrInvalidPageToken = errors.New("invalid page token")
+)
+
+// JobFilter は ListJobs の絞り込み条件とページング
+// ゼロ値の条件は絞り込まない。既定の並び順は開始日時の新しい順
+type JobFilter struct {
+	Statuses      []string  // いずれかのステータスに一致するジョブ
+	StartedAfter  time.Time // この日時以降に開始したジョブ
+	StartedBefore time.Time // この日時より前に開始したジョブ
+	AccountID     string    // このアカウントを含むジョブ
+	PageSize      int       // 0の場合は DefaultJobPageSize、最大 MaxJobPageSize
+	PageToken     string    // 前のページの JobPage.NextPageToken
+	Ascending     bool      // 開始日時の古い順に並べる
+}
+
+// JobPage は ListJobs の1ページ分の結果
+type JobPage struct {
+	Jobs          []*DownloadJob
+	NextPageToken string // 次のページがない場合は空
+}
+
+// ParseJobOrder は order_by（"started_at" / "started_at asc" / "started_at desc"）を解析し、古い順かどうかを返す
+// 空の場合は新しい順
+func ParseJobOrder(orderBy string) (bool, error) {
+	switch strings.Join(strings.Fields(strings.ToLower(orderBy)), " ") {
+	case "", "started_at desc":
+		return false, nil
+	case "started_at", "started_at asc":
+		return true, nil
+	}
+	return false, fmt.Errorf("%w: unsupported order_by %q (use \"started_at\" or \"started_at desc\")", ErrInvalidJobFilter, orderBy)
+}
+
+// ListJobs は条件に一致するジョブを開始日時順に1ページ分返す
+// ページトークンは最後に返したジョブの位置を表すため、ページの間にジョブが追加されても重複や欠落はない
+func (s *DownloadService) ListJobs(filter JobFilter) (*JobPage, error) {
+	pageSize := filter.PageSize
+	switch {
+	case pageSize < 0:
+		return nil, fmt.Errorf("%w: page_size must not be negative", ErrInvalidJobFilter)
+	case pageSize == 0:
+		pageSize = DefaultJobPageSize
+	case pageSize > MaxJobPageSize:
+		pageSize = MaxJobPageSize
+	}
+	if !filter.StartedAfter.IsZero() && !filter.StartedBefore.IsZero() && !filter.StartedAfter.Before(filter.StartedBefore) {
+		return nil, fmt.Errorf("%w: started_after must be before started_before", ErrInvalidJobFilter)
+	}
+
+	var cursor *jobCursor
+	if filter.PageToken != "" {
+		parsed, err := parseJobCursor(filter.PageToken)
+		if err != nil {
+			return nil, err
+		}
+		cursor = parsed
+	}
+
+	// ストアは古い順に返すので、新しい順の場合は逆にたどる
+	jobs := s.jobs.List()
+	if !filter.Ascending {
+		slices.Reverse(jobs)
+	}
+
+	page := &JobPage{Jobs: []*DownloadJob{}}
+	for _, job := range jobs {
+		if cursor != nil && !cursor.before(job, filter.Ascending) {
+			continue
+		}
+		if !filter.matches(job) {
+			continue
+		}
+		if len(page.Jobs) == pageSize {
+			page.NextPageToken = newJobCursor(page.Jobs[pageSize-1]).String()
+			break
+		}
+		page.Jobs = append(page.Jobs, job)
+	}
+	return page, nil
+}
+
+// matches はジョブが絞り込み条件に一致するかを返す
+func (f JobFilter) matches(job *DownloadJob) bool {
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, job.Status) {
+		return false
+	}
+	if !f.StartedAfter.IsZero() && job.StartedAt.Before(f.StartedAfter) {
+		return false
+	}
+	if !f.StartedBefore.IsZero() && !job.StartedAt.Before(f.StartedBefore) {
+		return false
+	}
+	if f.AccountID != "" && !slices.ContainsFunc(job.Accounts, func(account AccountResult) bool {
+		return account.AccountID == f.AccountID
+	}) {
+		return false
+	}
+	return true
+}
+
+// AccountStatusCounts はアカウントの結果をステータスごとに数える（例: completed: 2, failed: 1）
+func (job *DownloadJob) AccountStatusCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, account := range job.Accounts {
+		counts[account.Status]++
+	}
+	return counts
+}
+
+// jobCursor はページトークンが指す直前のページの最後のジョブ
+type jobCursor struct {
+	startedAt time.Time
+	jobID     string
+}
+
+func newJobCursor(job *DownloadJob) jobCursor {
+	return jobCursor{startedAt: job.StartedAt, jobID: job.ID}
+}
+
+// String はカーソルをページトークンに変換する
+func (c jobCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.startedAt.UnixNano(), 10) + ":" + c.jobID))
+}
+
+// parseJobCursor はページトークンを解析する
+func parseJobCursor(token string) (*jobCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	nanos, jobID, found := strings.Cut(string(data), ":")
+	if !found || jobID == "" {
+		return nil, ErrInvalidPageToken
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	return &jobCursor{startedAt: time.Unix(0, unixNano), jobID: jobID}, nil
+}
+
+// before はカーソルが並び順でジョブより前にある（ジョブが次のページに含まれる）かを返す
+// 開始日時が同じジョブはストアと同じくIDの順に並べる
+func (c jobCursor) before(job *DownloadJob, ascending bool) bool {
+	cmp := c.startedAt.Compare(job.StartedAt)
+	if cmp == 0 {
+		cmp = strings.Compare(c.jobID, job.ID)
+	}
+	if ascending {
+		return cmp < 0
+	}
+	return cmp > 0
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ErrJobStoreClosed は閉じたジョブストアに保存しようとした場合のエラー
+var ErrJobStoreClosed = errors.New("job store closed")
+
+// JobStore はダウンロードジョブの保存先
+// Get と List は保存されているジョブのコピーを返し、Save は job のコピーを保存する
+type JobStore interface {
+	Get(jobID string) (*DownloadJob, bool)
+	List() []*DownloadJob
+	Save(job *DownloadJob) error
+	Close() error
+}
+
+// MemoryJobStore はプロセス内のみでジョブを保持するストア（再起動で失われる）
+type MemoryJobStore struct {
+	mu   sync.RWMutex
+	jobs map[string]*DownloadJob
+}
+
+// NewMemoryJobStore creates an empty in-memory job store
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{jobs: make(map[string]*DownloadJob)}
+}
+
+// Get はジョブのコピーを返す
+func (m *MemoryJobStore) Get(jobID string) (*DownloadJob, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	job, exists := m.jobs[jobID]
+	if !exists {
+		return nil, false
+	}
+	return copyJob(job), true
+}
+
+// List はすべてのジョブのコピーを開始日時の古い順に返す
+func (m *MemoryJobStore) List() []*DownloadJob {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jobs := make([]*DownloadJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, copyJob(job))
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].StartedAt.Equal(jobs[j].StartedAt) {
+			return jobs[i].ID < jobs[j].ID
+		}
+		return jobs[i].StartedAt.Before(jobs[j].StartedAt)
+	})
+	return jobs
+}
+
+// Save はジョブのコピーを保存する
+func (m *MemoryJobStore) Save(job *DownloadJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.jobs[job.ID] = copyJob(job)
+	return nil
+}
+
+// Close は何もしない
+func (m *MemoryJobStore) Close() error {
+	return nil
+}
+
+// fileJobStoreCompactRatio は追記した行がジョブ数のこの倍数を超えた時点でログを圧縮する
+const fileJobStoreCompactRatio = 10
+
+// FileJobStore はジョブの更新をJSON Linesの追記ログに書き込むストア
+// 開いた時点で各ジョブの最新状態だけにログを圧縮し、以降は Save ごとに1行追記する
+// 追記した行が増えすぎた場合も圧縮する。最後の行が途中で切れていても（書き込み中のクラッシュ）読み飛ばす
+type FileJobStore struct {
+	path   string
+	logger *log.Logger
+
+	mu    sync.Mutex
+	jobs  *MemoryJobStore
+	file  *os.File
+	lines int // ログファイルの行数
+}
+
+// OpenFileJobStore opens or creates the job log at path and loads every job in it
+func OpenFileJobStore(path string, logger *log.Logger) (*FileJobStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create job store directory: %w", err)
+	}
+
+	store := &FileJobStore{path: path, logger: logger, jobs: NewMemoryJobStore()}
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+	if err := store.compact(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// load はログを先頭から読み、ジョブごとに最後の行を採用する
+func (f *FileJobStore) load() error {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read job store: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var job DownloadJob
+		if err := json.Unmarshal(scanner.Bytes(), &job); err != nil || job.ID == "" {
+			logWarning(f.logger, "skipped unreadable line %d of job store %s: %v", line, f.path, err)
+			continue
+		}
+		f.jobs.Save(&job)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read job store: %w", err)
+	}
+	return nil
+}
+
+// compact はログを各ジョブの最新状態だけに書き換え、追記用に開き直す
+func (f *FileJobStore) compact() error {
+	var buf bytes.Buffer
+	jobs := f.jobs.List()
+	for _, job := range jobs {
+		line, err := json.Marshal(job)
+		if err != nil {
+			return fmt.Errorf("failed to encode job %s: %w", job.ID, err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	// 一時ファイルに書いてから置き換え、圧縮中のクラッシュでログを失わないようにする
+	tmpPath := f.path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write job store: %w", err)
+	}
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	if err := os.Rename(tmpPath, f.path); err != nil {
+		return fmt.Errorf("failed to replace job store: %w", err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open job store: %w", err)
+	}
+	f.file = file
+	f.lines = len(jobs)
+	return nil
+}
+
+// Get はジョブのコピーを返す
+func (f *FileJobStore) Get(jobID string) (*DownloadJob, bool) {
+	return f.jobs.Get(jobID)
+}
+
+// List はすべてのジョブのコピーを開始日時の古い順に返す
+func (f *FileJobStore) List() []*DownloadJob {
+	return f.jobs.List()
+}
+
+// Save はジョブの現在の状態をログに追記する
+func (f *FileJobStore) Save(job *DownloadJob) error {
+	line, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job %s: %w", job.ID, err)
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return ErrJobStoreClosed
+	}
+	f.jobs.Save(job)
+	if _, err := f.file.Write(line); err != nil {
+		return fmt.Errorf("failed to append to job store: %w", err)
+	}
+	f.lines++
+
+	if f.lines > fileJobStoreCompactRatio*max(len(f.jobs.jobs), 1) {
+		return f.compact()
+	}
+	return nil
+}
+
+// Close はログファイルを閉じる。以降の Save は ErrJobStoreClosed を返す
+func (f *FileJobStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// copyJob はジョブのディープコピーを返す
+func copyJob(job *DownloadJob) *DownloadJob {
+	jobCopy := *job
+	jobCopy.Accounts = append([]AccountResult(nil), job.Accounts...)
+	if job.ErrorDetails != nil {
+		jobCopy.ErrorDetails = make(map[string]string, len(job.ErrorDetails))
+		for key, value := range job.ErrorDetails {
+			jobCopy.ErrorDetails[key] = value
+		}
+	}
+	if job.CompletedAt != nil {
+		completedAt := *job.CompletedAt
+		jobCopy.CompletedAt = &completedAt
+	}
+	if job.CancelledAt != nil {
+		cancelledAt := *job.CancelledAt
+		jobCopy.CancelledAt = &cancelledAt
+	}
+	return &jobCopy
+}
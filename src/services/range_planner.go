@@ -0,0 +1,54 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+)
+
+// SiteRetentionMonths は明細サイトで検索できる過去の月数
+const SiteRetentionMonths = 15
+
+// ErrRangeOutsideRetention はサイトの保持期間より古い期間が要求された場合のエラー
+var ErrRangeOutsideRetention = errors.New("requested range is older than the site's retention window")
+
+// PlanDateRanges は指定期間をサイトで検索可能な月単位のウィンドウに分割
+func PlanDateRanges(fromDate, toDate string) ([]scraper.DateRange, error) {
+	return PlanDateRangesAt(fromDate, toDate, time.Now())
+}
+
+// PlanDateRangesAt は基準時刻を指定して期間を分割（テスト用）
+func PlanDateRangesAt(fromDate, toDate string, now time.Time) ([]scraper.DateRange, error) {
+	dateRange, err := scraper.ParseDateRange(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	oldest := OldestSearchableDate(now)
+	if dateRange.From.Before(oldest) {
+		return nil, fmt.Errorf("%w: from date %s is before %s (site keeps %d months)",
+			ErrRangeOutsideRetention, dateRange.From.Format("2006-01-02"), oldest.Format("2006-01-02"), SiteRetentionMonths)
+	}
+
+	// 暦月ごとに分割（サイトは1回の検索で1か月分まで）
+	var windows []scraper.DateRange
+	start := dateRange.From
+	for !start.After(dateRange.To) {
+		monthEnd := time.Date(start.Year(), start.Month()+1, 0, 0, 0, 0, 0, start.Location())
+		end := monthEnd
+		if end.After(dateRange.To) {
+			end = dateRange.To
+		}
+		windows = append(windows, scraper.DateRange{From: start, To: end})
+		start = monthEnd.AddDate(0, 0, 1)
+	}
+
+	return windows, nil
+}
+
+// OldestSearchableDate はサイトで検索できる最も古い日付（保持期間の開始月の1日）
+func OldestSearchableDate(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month()-SiteRetentionMonths, 1, 0, 0, 0, 0, time.Local)
+}
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"context"
+	"fmt"
 	"log"
 
 	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
@@ -11,15 +13,63 @@ type ScraperFactory interface {
 	CreateScraper(config *scraper.ScraperConfig, logger *log.Logger) (scraper.ScraperInterface, error)
 }
 
-// DefaultScraperFactory creates real ETCScraper instances
+// ContextScraperFactory is implemented by factories that create cancellation-aware scrapers
+type ContextScraperFactory interface {
+	ScraperFactory
+	CreateContextScraper(ctx context.Context, config *scraper.ScraperConfig, logger *log.Logger) (scraper.ContextScraper, error)
+}
+
+// DefaultScraperFactory creates real scrapers for the backend selected by config.Backend
 type DefaultScraperFactory struct{}
 
-// CreateScraper creates a new ETCScraper instance
+// CreateScraper creates a new scraper for config.Backend
 func (f *DefaultScraperFactory) CreateScraper(config *scraper.ScraperConfig, logger *log.Logger) (scraper.ScraperInterface, error) {
-	return scraper.NewETCScraper(config, logger)
+	return newScraper(config, logger)
+}
+
+// CreateContextScraper creates a new scraper for config.Backend unless ctx is already done
+func (f *DefaultScraperFactory) CreateContextScraper(ctx context.Context, config *scraper.ScraperConfig, logger *log.Logger) (scraper.ContextScraper, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return newScraper(config, logger)
+}
+
+// newScraper creates the scraper for config.Backend.
+// The HTTP backend falls back to Playwright when the site shows pages it cannot handle.
+func newScraper(config *scraper.ScraperConfig, logger *log.Logger) (scraper.ContextScraper, error) {
+	switch config.Backend {
+	case "", scraper.BackendPlaywright:
+		return scraper.NewETCScraper(config, logger)
+	case scraper.BackendHTTP:
+		primary, err := scraper.NewHTTPScraper(config, logger)
+		if err != nil {
+			return nil, err
+		}
+		return scraper.NewFallbackScraper(primary, func() (scraper.ContextScraper, error) {
+			fallbackConfig := *config
+			fallbackConfig.Backend = scraper.BackendPlaywright
+			return scraper.NewETCScraper(&fallbackConfig, logger)
+		}, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown scraper backend %q", config.Backend)
+	}
 }
 
 // NewDefaultScraperFactory creates a new default scraper factory
 func NewDefaultScraperFactory() ScraperFactory {
 	return &DefaultScraperFactory{}
-}
\ No newline at end of file
+}
+
+// createContextScraper creates a scraper through factory, adapting it to ContextScraper when needed
+func createContextScraper(ctx context.Context, factory ScraperFactory, config *scraper.ScraperConfig, logger *log.Logger) (scraper.ContextScraper, error) {
+	if f, ok := factory.(ContextScraperFactory); ok {
+		return f.CreateContextScraper(ctx, config, logger)
+	}
+
+	s, err := factory.CreateScraper(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	return scraper.AsContextScraper(s), nil
+}
@@ -0,0 +1,139 @@
+//go:build e2e
+
+// End-to-end tests that drive a real Chromium through ETCScraper against the local fake site.
+// Browsers must be installed beforehand (go run github.com/playwright-community/playwright-go/cmd/playwright install chromium).
+//
+//	go test -tags e2e ./tests/e2e/...
+package e2e_test
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/internal/fakesite"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// lastMonth returns the first and last day of the previous month
+func lastMonth() (time.Time, time.Time) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.Local)
+	return from, from.AddDate(0, 1, -1)
+}
+
+func newSiteScraper(t *testing.T, site *fakesite.Site, userID, password string) *scraper.ETCScraper {
+	t.Helper()
+
+	config := &scraper.ScraperConfig{
+		UserID:          userID,
+		Password:        password,
+		BaseURL:         site.URL + "/",
+		DownloadPath:    t.TempDir(),
+		Headless:        true,
+		Timeout:         10000,
+		RetryCount:      1,
+		RetryBackoff:    100 * time.Millisecond,
+		DownloadTimeout: 10 * time.Second,
+		TestMode:        true,
+	}
+	logger := log.New(os.Stdout, "[E2E] ", log.LstdFlags)
+
+	s, err := scraper.NewETCScraper(config, logger)
+	if err != nil {
+		t.Fatalf("Failed to create scraper: %v", err)
+	}
+	if err := s.Initialize(); err != nil {
+		t.Skipf("Playwright browser is not available: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestETCScraper_FakeSite_Download(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+
+	from, to := lastMonth()
+	usedAt := from.Add(10*24*time.Hour + 8*time.Hour)
+	site.AddAccount("e2e-user", "e2e-pass", fakesite.Record{
+		EntryAt: usedAt.Add(-40 * time.Minute), ExitAt: usedAt,
+		EntryIC: "東京", ExitIC: "横浜", BaseFare: 1320, Fare: 1320,
+		VehicleClass: "普通車", VehicleNumber: "品川 300 あ 12-34", CardNumber: "1234567890123456",
+	})
+
+	s := newSiteScraper(t, site, "e2e-user", "e2e-pass")
+	if err := s.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	// The session drops before the search; the scraper must log in again
+	site.ExpireSessions()
+
+	path, err := s.DownloadMeisai(from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		t.Fatalf("DownloadMeisai failed: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Downloaded file not readable: %v", err)
+	}
+	decoded, _ := japanese.ShiftJIS.NewDecoder().Bytes(raw)
+	if !strings.Contains(string(decoded), "東京,横浜,1320") {
+		t.Errorf("Downloaded CSV does not contain the record: %q", decoded)
+	}
+
+	expired := false
+	for _, attempt := range s.Attempts() {
+		if attempt.Class == scraper.ErrorClassSessionExpired {
+			expired = true
+		}
+	}
+	if !expired {
+		t.Error("Expected the expired session to be recorded")
+	}
+}
+
+func TestETCScraper_FakeSite_InvalidCredentials(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+	site.AddAccount("e2e-user", "e2e-pass")
+
+	s := newSiteScraper(t, site, "e2e-user", "wrong")
+	if err := s.Login(); !errors.Is(err, scraper.ErrInvalidCredentials) {
+		t.Fatalf("Expected ErrInvalidCredentials, got %v", err)
+	}
+}
+
+func TestETCScraper_FakeSite_NoResults(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+	site.AddAccount("e2e-user", "e2e-pass")
+
+	s := newSiteScraper(t, site, "e2e-user", "e2e-pass")
+	if err := s.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	from, to := lastMonth()
+	if _, err := s.DownloadMeisai(from.Format("2006-01-02"), to.Format("2006-01-02")); !errors.Is(err, scraper.ErrNoResults) {
+		t.Fatalf("Expected ErrNoResults, got %v", err)
+	}
+}
+
+func TestETCScraper_FakeSite_Maintenance(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+	site.AddAccount("e2e-user", "e2e-pass")
+	site.SetMaintenance(true)
+
+	s := newSiteScraper(t, site, "e2e-user", "e2e-pass")
+	if err := s.Login(); !errors.Is(err, scraper.ErrSiteMaintenance) {
+		t.Fatalf("Expected ErrSiteMaintenance, got %v", err)
+	}
+}
@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"os"
+
 	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
 )
 
@@ -72,6 +74,7 @@ type MockBrowser struct {
 	CloseFunc func() error
 	NewContextError error
 	CloseError error
+	Disconnected bool // Reported by IsConnected
 }
 
 func (m *MockBrowser) NewContext(options scraper.BrowserNewContextOptions) (scraper.BrowserContextInterface, error) {
@@ -84,6 +87,10 @@ func (m *MockBrowser) NewContext(options scraper.BrowserNewContextOptions) (scra
 	return &MockBrowserContext{}, nil
 }
 
+func (m *MockBrowser) IsConnected() bool {
+	return !m.Disconnected
+}
+
 func (m *MockBrowser) Close() error {
 	if m.CloseFunc != nil {
 		return m.CloseFunc()
@@ -99,6 +106,8 @@ type MockBrowserContext struct {
 	NewPageError error
 	CloseError error
 	TimeoutSet float64
+	State []byte // Returned by StorageState
+	StateError error
 }
 
 func (m *MockBrowserContext) NewPage() (scraper.PageInterface, error) {
@@ -118,6 +127,10 @@ func (m *MockBrowserContext) SetDefaultTimeout(timeout float64) {
 	}
 }
 
+func (m *MockBrowserContext) StorageState() ([]byte, error) {
+	return m.State, m.StateError
+}
+
 func (m *MockBrowserContext) Close() error {
 	if m.CloseFunc != nil {
 		return m.CloseFunc()
@@ -125,6 +138,8 @@ func (m *MockBrowserContext) Close() error {
 	return m.CloseError
 }
 
+func (m *MockBrowserContext) On(event string, handler interface{}) {}
+
 // MockPage implements scraper.PageInterface
 type MockPage struct {
 	GotoFunc func(url string, options scraper.PageGotoOptions) (scraper.Response, error)
@@ -133,11 +148,17 @@ type MockPage struct {
 	ScreenshotFunc func(options scraper.PageScreenshotOptions) ([]byte, error)
 	CloseFunc func() error
 	OnFunc func(event string, handler interface{})
+	EvaluateFunc func(expression string, arg ...interface{}) (interface{}, error)
+	ContentFunc func() (string, error)
 
 	GotoError error
 	WaitError error
 	ScreenshotError error
 	CloseError error
+	EvaluateError error
+	URLValue string // Returned by URL, updated by Goto
+	ContentValue string
+	ContentError error
 	Locators map[string]*MockLocator
 	DownloadHandler interface{}
 }
@@ -149,6 +170,7 @@ func NewMockPage() *MockPage {
 }
 
 func (m *MockPage) Goto(url string, options scraper.PageGotoOptions) (scraper.Response, error) {
+	m.URLValue = url
 	if m.GotoFunc != nil {
 		return m.GotoFunc(url, options)
 	}
@@ -158,6 +180,10 @@ func (m *MockPage) Goto(url string, options scraper.PageGotoOptions) (scraper.Re
 	return nil, nil
 }
 
+func (m *MockPage) URL() string {
+	return m.URLValue
+}
+
 func (m *MockPage) Locator(selector string) scraper.LocatorInterface {
 	if m.LocatorFunc != nil {
 		return m.LocatorFunc(selector)
@@ -186,6 +212,13 @@ func (m *MockPage) Screenshot(options scraper.PageScreenshotOptions) ([]byte, er
 	return []byte("mock screenshot"), nil
 }
 
+func (m *MockPage) Content() (string, error) {
+	if m.ContentFunc != nil {
+		return m.ContentFunc()
+	}
+	return m.ContentValue, m.ContentError
+}
+
 func (m *MockPage) Close() error {
 	if m.CloseFunc != nil {
 		return m.CloseFunc()
@@ -202,6 +235,13 @@ func (m *MockPage) On(event string, handler interface{}) {
 	}
 }
 
+func (m *MockPage) Evaluate(expression string, arg ...interface{}) (interface{}, error) {
+	if m.EvaluateFunc != nil {
+		return m.EvaluateFunc(expression, arg...)
+	}
+	return nil, m.EvaluateError
+}
+
 // MockLocator implements scraper.LocatorInterface
 type MockLocator struct {
 	CountFunc func() (int, error)
@@ -209,6 +249,9 @@ type MockLocator struct {
 	FillFunc func(value string) error
 	ClickFunc func(options scraper.LocatorClickOptions) error
 	TextContentFunc func(options scraper.LocatorTextContentOptions) (string, error)
+	CheckFunc func(options scraper.LocatorCheckOptions) error
+	IsCheckedFunc func(options scraper.LocatorIsCheckedOptions) (bool, error)
+	SelectOptionFunc func(values []string, options scraper.LocatorSelectOptionOptions) ([]string, error)
 
 	CountValue int
 	CountError error
@@ -216,6 +259,11 @@ type MockLocator struct {
 	ClickError error
 	TextValue string
 	TextError error
+	CheckError error
+	CheckedValue bool
+	IsCheckedError error
+	SelectOptionError error
+	SelectedValues []string
 }
 
 func (m *MockLocator) Count() (int, error) {
@@ -259,10 +307,43 @@ func (m *MockLocator) TextContent(options scraper.LocatorTextContentOptions) (st
 	return m.TextValue, nil
 }
 
+func (m *MockLocator) Check(options scraper.LocatorCheckOptions) error {
+	if m.CheckFunc != nil {
+		return m.CheckFunc(options)
+	}
+	if m.CheckError != nil {
+		return m.CheckError
+	}
+	m.CheckedValue = true
+	return nil
+}
+
+func (m *MockLocator) IsChecked(options scraper.LocatorIsCheckedOptions) (bool, error) {
+	if m.IsCheckedFunc != nil {
+		return m.IsCheckedFunc(options)
+	}
+	if m.IsCheckedError != nil {
+		return false, m.IsCheckedError
+	}
+	return m.CheckedValue, nil
+}
+
+func (m *MockLocator) SelectOption(values []string, options scraper.LocatorSelectOptionOptions) ([]string, error) {
+	if m.SelectOptionFunc != nil {
+		return m.SelectOptionFunc(values, options)
+	}
+	if m.SelectOptionError != nil {
+		return nil, m.SelectOptionError
+	}
+	m.SelectedValues = values
+	return values, nil
+}
+
 // MockDownload simulates a scraper.Download
 type MockDownload struct {
 	SuggestedName string
 	SaveError error
+	Content []byte // Written to the save path when set
 }
 
 func (m *MockDownload) SuggestedFilename() string {
@@ -270,7 +351,13 @@ func (m *MockDownload) SuggestedFilename() string {
 }
 
 func (m *MockDownload) SaveAs(path string) error {
-	return m.SaveError
+	if m.SaveError != nil {
+		return m.SaveError
+	}
+	if m.Content != nil {
+		return os.WriteFile(path, m.Content, 0644)
+	}
+	return nil
 }
 
 // SetDownloadHandler sets up mock download handler for testing
@@ -0,0 +1,169 @@
+package credentials_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/credentials"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/cryptoutil"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+)
+
+var testKey = bytes.Repeat([]byte{0x42}, cryptoutil.KeySize)
+
+// Vault implements scraper.SecretProvider
+var _ scraper.SecretProvider = (*credentials.Vault)(nil)
+
+func TestVault_AddRotateRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	vault, err := credentials.Open(path, testKey)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	if err := vault.Add("corp1", "p@ss:word,1"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := vault.Add("personal1", "secret"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := vault.Add("corp1", "again"); !errors.Is(err, credentials.ErrExists) {
+		t.Errorf("Expected ErrExists, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Vault file not written: %v", err)
+	}
+	if bytes.Contains(data, []byte("p@ss")) || bytes.Contains(data, []byte("corp1")) {
+		t.Errorf("Vault file contains plaintext: %s", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm()&0077 != 0 {
+		t.Errorf("Vault file must not be readable by others, got %v", info.Mode().Perm())
+	}
+
+	// Another process opening the same file sees the passwords
+	reopened, err := credentials.Open(path, testKey)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if password, err := reopened.Password(context.Background(), "corp1"); err != nil || password != "p@ss:word,1" {
+		t.Errorf("Password(corp1) = %q, %v", password, err)
+	}
+
+	if err := reopened.Rotate("corp1", "rotated"); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	if password, _ := vault.Password(context.Background(), "corp1"); password != "rotated" {
+		t.Errorf("Expected the rotated password, got %q", password)
+	}
+	if err := vault.Rotate("missing", "x"); !errors.Is(err, credentials.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if err := vault.Remove("personal1"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	_, err = vault.Password(context.Background(), "personal1")
+	if !errors.Is(err, credentials.ErrNotFound) || !errors.Is(err, scraper.ErrSecretNotFound) {
+		t.Errorf("Expected ErrNotFound wrapping scraper.ErrSecretNotFound, got %v", err)
+	}
+	if ids, _ := vault.AccountIDs(); fmt.Sprint(ids) != "[corp1]" {
+		t.Errorf("Unexpected account IDs %v", ids)
+	}
+}
+
+func TestVault_InvalidInput(t *testing.T) {
+	vault, err := credentials.Open(filepath.Join(t.TempDir(), "vault.json"), testKey)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	for _, accountID := range []string{"", "a:b", "a,b"} {
+		if err := vault.Add(accountID, "pass"); err == nil {
+			t.Errorf("Expected an error for account ID %q", accountID)
+		}
+	}
+	if err := vault.Add("corp1", ""); err == nil {
+		t.Error("Expected an error for an empty password")
+	}
+	if _, err := credentials.Open(filepath.Join(t.TempDir(), "vault.json"), []byte("short")); !errors.Is(err, cryptoutil.ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestVault_WrongKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	vault, _ := credentials.Open(path, testKey)
+	if err := vault.Add("corp1", "pass"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if _, err := credentials.Open(path, bytes.Repeat([]byte{0x43}, cryptoutil.KeySize)); !errors.Is(err, credentials.ErrWrongKey) {
+		t.Errorf("Expected ErrWrongKey, got %v", err)
+	}
+	if _, err := credentials.OpenWithPassphrase(path, "passphrase"); err == nil {
+		t.Error("Expected a key-file vault to reject a passphrase")
+	}
+}
+
+func TestVault_KeyFile(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "vault.key")
+	if err := os.WriteFile(keyPath, []byte(base64.StdEncoding.EncodeToString(testKey)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	vault, err := credentials.OpenWithKeyFile(filepath.Join(dir, "vault.json"), keyPath)
+	if err != nil {
+		t.Fatalf("OpenWithKeyFile failed: %v", err)
+	}
+	if err := vault.Add("corp1", "pass"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	sameKey, err := credentials.Open(filepath.Join(dir, "vault.json"), testKey)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if password, _ := sameKey.Password(context.Background(), "corp1"); password != "pass" {
+		t.Errorf("Expected pass, got %q", password)
+	}
+
+	if _, err := credentials.OpenWithKeyFile(filepath.Join(dir, "vault.json"), filepath.Join(dir, "missing.key")); err == nil {
+		t.Error("Expected an error for a missing key file")
+	}
+}
+
+func TestVault_Passphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	vault, err := credentials.OpenWithPassphrase(path, "correct horse")
+	if err != nil {
+		t.Fatalf("OpenWithPassphrase failed: %v", err)
+	}
+	if err := vault.Add("corp1", "pass"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	reopened, err := credentials.OpenWithPassphrase(path, "correct horse")
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if password, _ := reopened.Password(context.Background(), "corp1"); password != "pass" {
+		t.Errorf("Expected pass, got %q", password)
+	}
+
+	if _, err := credentials.OpenWithPassphrase(path, "wrong"); !errors.Is(err, credentials.ErrWrongKey) {
+		t.Errorf("Expected ErrWrongKey, got %v", err)
+	}
+	if _, err := credentials.Open(path, testKey); err == nil {
+		t.Error("Expected a passphrase vault to reject a key")
+	}
+	if _, err := credentials.OpenWithPassphrase(path, ""); err == nil {
+		t.Error("Expected an error for an empty passphrase")
+	}
+}
@@ -0,0 +1,91 @@
+package cryptoutil_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/cryptoutil"
+)
+
+var testKey = bytes.Repeat([]byte{0x42}, cryptoutil.KeySize)
+
+func TestSealOpen(t *testing.T) {
+	plaintext := []byte(`{"cookies":[{"name":"JSESSIONID","value":"secret"}]}`)
+
+	sealed, err := cryptoutil.Seal(testKey, plaintext)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if bytes.Contains(sealed, []byte("secret")) {
+		t.Fatal("Sealed data contains the plaintext")
+	}
+
+	opened, err := cryptoutil.Open(testKey, sealed)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("Expected %q, got %q", plaintext, opened)
+	}
+
+	again, _ := cryptoutil.Seal(testKey, plaintext)
+	if bytes.Equal(again, sealed) {
+		t.Error("Expected a fresh nonce for every Seal")
+	}
+}
+
+func TestOpen_Rejects(t *testing.T) {
+	sealed, _ := cryptoutil.Seal(testKey, []byte("state"))
+
+	otherKey := bytes.Repeat([]byte{0x24}, cryptoutil.KeySize)
+	if _, err := cryptoutil.Open(otherKey, sealed); !errors.Is(err, cryptoutil.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for wrong key, got %v", err)
+	}
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 0xff
+	if _, err := cryptoutil.Open(testKey, tampered); !errors.Is(err, cryptoutil.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for modified data, got %v", err)
+	}
+
+	if _, err := cryptoutil.Open(testKey, []byte("short")); !errors.Is(err, cryptoutil.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for truncated data, got %v", err)
+	}
+
+	if _, err := cryptoutil.Seal([]byte("too short"), []byte("state")); !errors.Is(err, cryptoutil.ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		valid   bool
+	}{
+		{"base64", base64.StdEncoding.EncodeToString(testKey), true},
+		{"base64 url", base64.RawURLEncoding.EncodeToString(testKey), true},
+		{"hex", hex.EncodeToString(testKey), true},
+		{"surrounding whitespace", " " + hex.EncodeToString(testKey) + "\n", true},
+		{"too short", base64.StdEncoding.EncodeToString(testKey[:16]), false},
+		{"not encoded", "my-session-password", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := cryptoutil.ParseKey(tt.encoded)
+			if !tt.valid {
+				if !errors.Is(err, cryptoutil.ErrInvalidKey) {
+					t.Errorf("Expected ErrInvalidKey, got %v", err)
+				}
+				return
+			}
+			if err != nil || !bytes.Equal(key, testKey) {
+				t.Errorf("Expected the test key, got %x, %v", key, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package fakesite_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/internal/fakesite"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func newClient(t *testing.T) *http.Client {
+	t.Helper()
+	jar, _ := cookiejar.New(nil)
+	return &http.Client{Jar: jar}
+}
+
+func get(t *testing.T, client *http.Client, target string) (int, string) {
+	t.Helper()
+	resp, err := client.Get(target)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", target, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func post(t *testing.T, client *http.Client, target string, form url.Values) string {
+	t.Helper()
+	resp, err := client.PostForm(target, form)
+	if err != nil {
+		t.Fatalf("POST %s failed: %v", target, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func searchForm(from, to time.Time, button string) url.Values {
+	return url.Values{
+		"sokoKbn":  {"0"},
+		"fromYYYY": {from.Format("2006")}, "fromMM": {from.Format("01")}, "fromDD": {from.Format("02")},
+		"toYYYY": {to.Format("2006")}, "toMM": {to.Format("01")}, "toDD": {to.Format("02")},
+		button: {"x"},
+	}
+}
+
+func TestFakeSite_LoginSearchDownload(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+
+	usedAt := time.Date(2025, 1, 15, 9, 30, 0, 0, time.Local)
+	site.AddAccount("user1", "secret", fakesite.Record{
+		EntryAt: usedAt.Add(-time.Hour), ExitAt: usedAt,
+		EntryIC: "東京", ExitIC: "横浜", BaseFare: 1320, Discount: 0, Fare: 1320,
+		VehicleClass: "普通車", VehicleNumber: "品川 300 あ 12-34", CardNumber: "1234567890123456",
+	})
+
+	client := newClient(t)
+	loginURL := site.URL + "/etc/R?funccode=" + fakesite.FuncLogin
+	searchURL := site.URL + "/etc/R?funccode=" + fakesite.FuncSearch
+
+	_, top := get(t, client, site.URL+"/")
+	if !strings.Contains(top, "funccode="+fakesite.FuncLogin) {
+		t.Fatal("Top page should link to the login form")
+	}
+
+	page := post(t, client, loginURL, url.Values{"risLoginId": {"user1"}, "risPassword": {"wrong"}})
+	if !strings.Contains(page, fakesite.MessageInvalidCredentials) || !strings.Contains(page, `class="error-message"`) {
+		t.Fatal("Wrong password should show the error message")
+	}
+
+	page = post(t, client, loginURL, url.Values{"risLoginId": {"user1"}, "risPassword": {"secret"}})
+	for _, want := range []string{"ログアウト", "検索条件の指定", `name="sokoKbn"`, `name="fromYYYY"`, `name="focusTarget_Save"`} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Search page should contain %s", want)
+		}
+	}
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2025, 1, 31, 0, 0, 0, 0, time.Local)
+	page = post(t, client, searchURL, searchForm(from, to, "focusTarget"))
+	if !strings.Contains(page, `<span id="searchPeriod">2025年01月01日 ～ 2025年01月31日</span>`) {
+		t.Error("Result page should show the searched period")
+	}
+	if strings.Count(page, `name="hakkoMeisai"`) != 1 || !strings.Contains(page, "goOutput(") {
+		t.Error("Result page should list one record with the CSV link")
+	}
+
+	resp, err := client.Get(site.URL + "/etc/R?funccode=" + fakesite.FuncCSV)
+	if err != nil {
+		t.Fatalf("CSV download failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if !strings.HasPrefix(resp.Header.Get("Content-Disposition"), "attachment") {
+		t.Errorf("CSV should be served as an attachment, got %q", resp.Header.Get("Content-Disposition"))
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(raw)
+	if err != nil {
+		t.Fatalf("CSV should be Shift_JIS: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(decoded), "\r\n"), "\r\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "利用年月日（自）") {
+		t.Fatalf("Unexpected CSV: %q", decoded)
+	}
+	if !strings.HasPrefix(lines[1], "25/01/15,08:30,25/01/15,09:30,東京,横浜,1320,0,1320") {
+		t.Errorf("Unexpected CSV row: %q", lines[1])
+	}
+	if site.Downloads() != 1 {
+		t.Errorf("Expected 1 download, got %d", site.Downloads())
+	}
+}
+
+func TestFakeSite_EmptyResultHasNoCSVLink(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+	site.AddAccount("user1", "secret")
+
+	client := newClient(t)
+	post(t, client, site.URL+"/etc/R?funccode="+fakesite.FuncLogin, url.Values{"risLoginId": {"user1"}, "risPassword": {"secret"}})
+
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+	page := post(t, client, site.URL+"/etc/R?funccode="+fakesite.FuncSearch, searchForm(day, day, "focusTarget"))
+	if strings.Contains(page, "hakkoMeisai") || strings.Contains(page, "明細ＣＳＶ") {
+		t.Error("Empty result should not offer a CSV link")
+	}
+}
+
+func TestFakeSite_AccountStates(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+	site.AddAccount("locked", "secret").Locked = true
+	site.AddAccount("expired", "secret").PasswordExpired = true
+
+	loginURL := site.URL + "/etc/R?funccode=" + fakesite.FuncLogin
+	if page := post(t, newClient(t), loginURL, url.Values{"risLoginId": {"locked"}, "risPassword": {"secret"}}); !strings.Contains(page, fakesite.MessageAccountLocked) {
+		t.Error("Locked account should show the lock message")
+	}
+	if page := post(t, newClient(t), loginURL, url.Values{"risLoginId": {"expired"}, "risPassword": {"secret"}}); !strings.Contains(page, fakesite.MessagePasswordExpired) {
+		t.Error("Expired password should show the expiry message")
+	}
+}
+
+func TestFakeSite_ExpiredSessionAndMaintenance(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+	site.AddAccount("user1", "secret")
+
+	client := newClient(t)
+	post(t, client, site.URL+"/etc/R?funccode="+fakesite.FuncLogin, url.Values{"risLoginId": {"user1"}, "risPassword": {"secret"}})
+
+	site.ExpireSessions()
+	if _, page := get(t, client, site.URL+"/etc/R?funccode="+fakesite.FuncSearch); !strings.Contains(page, `name="risLoginId"`) {
+		t.Error("Expired session should fall back to the login form")
+	}
+
+	site.SetMaintenance(true)
+	code, page := get(t, client, site.URL+"/")
+	if code != http.StatusServiceUnavailable || !strings.Contains(page, "システムメンテナンス中") {
+		t.Errorf("Expected maintenance page, got %d", code)
+	}
+}
+
+func TestFakeSite_ShiftJIS(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+	site.SetShiftJIS(true)
+	site.AddAccount("user1", "秘密")
+
+	client := newClient(t)
+	resp, err := client.Get(site.URL + "/etc/R?funccode=" + fakesite.FuncLogin)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/html; charset=Shift_JIS" {
+		t.Errorf("Expected a Shift_JIS page, got %s", contentType)
+	}
+	if page, _ := japanese.ShiftJIS.NewDecoder().String(string(raw)); !strings.Contains(page, "ログインID") || !strings.Contains(page, `charset="Shift_JIS"`) {
+		t.Errorf("Login page does not decode from Shift_JIS: %q", page)
+	}
+
+	// The posted password is read as Shift_JIS, so the UTF-8 bytes of the same text are rejected
+	loginURL := site.URL + "/etc/R?funccode=" + fakesite.FuncLogin
+	page := post(t, client, loginURL, url.Values{"risLoginId": {"user1"}, "risPassword": {"秘密"}})
+	if page, _ = japanese.ShiftJIS.NewDecoder().String(page); !strings.Contains(page, fakesite.MessageInvalidCredentials) {
+		t.Error("A UTF-8 post should not match the password")
+	}
+	password, _ := japanese.ShiftJIS.NewEncoder().String("秘密")
+	page = post(t, client, loginURL, url.Values{"risLoginId": {"user1"}, "risPassword": {password}})
+	if page, _ = japanese.ShiftJIS.NewDecoder().String(page); !strings.Contains(page, "ログアウト") {
+		t.Error("A Shift_JIS post should log in")
+	}
+}
@@ -2,14 +2,17 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/yhonda-ohishi/etc_meisai_scraper/src/handlers"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/models"
 	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
 )
 
@@ -31,6 +34,24 @@ func (m *CompleteMockDownloadService) GetAllAccountIDs() []string {
 	return m.accountIDs
 }
 
+func (m *CompleteMockDownloadService) ResolveAccounts(accounts []string) ([]string, error) {
+	if len(accounts) == 0 {
+		if len(m.accountIDs) == 0 {
+			return nil, services.ErrNoAccountsConfigured
+		}
+		return m.accountIDs, nil
+	}
+	for _, account := range accounts {
+		if userID, _, found := strings.Cut(account, ":"); found {
+			return nil, &services.AccountError{AccountID: userID, Err: services.ErrInvalidAccountFormat}
+		}
+		if !slices.Contains(m.accountIDs, account) {
+			return nil, &services.AccountError{AccountID: account, Err: services.ErrUnknownAccount}
+		}
+	}
+	return accounts, nil
+}
+
 func (m *CompleteMockDownloadService) ProcessAsync(jobID string, accounts []string, fromDate, toDate string) {
 	if m.processAsyncFunc != nil {
 		m.processAsyncFunc(jobID, accounts, fromDate, toDate)
@@ -46,6 +67,33 @@ func (m *CompleteMockDownloadService) ProcessAsync(jobID string, accounts []stri
 	}
 }
 
+func (m *CompleteMockDownloadService) DownloadSync(ctx context.Context, accounts []string, fromDate, toDate string) (*models.DownloadResult, error) {
+	if _, err := services.PlanDateRanges(fromDate, toDate); err != nil {
+		return nil, err
+	}
+	return &models.DownloadResult{Success: true, Records: []models.ETCMeisaiRecord{}}, nil
+}
+
+func (m *CompleteMockDownloadService) ListJobs(filter services.JobFilter) (*services.JobPage, error) {
+	page := &services.JobPage{}
+	for _, job := range m.jobs {
+		page.Jobs = append(page.Jobs, job)
+	}
+	return page, nil
+}
+
+func (m *CompleteMockDownloadService) CancelJob(ctx context.Context, jobID string) (*services.DownloadJob, error) {
+	job, exists := m.jobs[jobID]
+	if !exists {
+		return nil, services.ErrJobNotFound
+	}
+	now := time.Now()
+	job.Status = "cancelled"
+	job.CompletedAt = &now
+	job.CancelledAt = &now
+	return job, nil
+}
+
 func (m *CompleteMockDownloadService) GetJobStatus(jobID string) (*services.DownloadJob, bool) {
 	job, exists := m.jobs[jobID]
 	if !exists {
@@ -88,8 +136,8 @@ func TestDownloadHandler_DownloadSync_AllCases(t *testing.T) {
 			name: "valid request with dates",
 			reqBody: handlers.DownloadRequest{
 				Accounts: []string{"test1"},
-				FromDate: "2024-01-01",
-				ToDate:   "2024-01-31",
+				FromDate: testFromDate,
+				ToDate:   testToDate,
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, resp map[string]interface{}) {
@@ -116,8 +164,8 @@ func TestDownloadHandler_DownloadSync_AllCases(t *testing.T) {
 			name: "request without accounts",
 			reqBody: handlers.DownloadRequest{
 				Accounts: []string{},
-				FromDate: "2024-01-01",
-				ToDate:   "2024-01-31",
+				FromDate: testFromDate,
+				ToDate:   testToDate,
 			},
 			expectedStatus: http.StatusBadRequest,
 			checkResponse: func(t *testing.T, resp map[string]interface{}) {
@@ -126,6 +174,34 @@ func TestDownloadHandler_DownloadSync_AllCases(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "unknown account",
+			reqBody: handlers.DownloadRequest{
+				Accounts: []string{"test1", "missing"},
+				FromDate: testFromDate,
+				ToDate:   testToDate,
+			},
+			expectedStatus: http.StatusNotFound,
+			checkResponse: func(t *testing.T, resp map[string]interface{}) {
+				if resp["error"] != "Account missing not found" {
+					t.Errorf("Expected the unknown account in the error, got %v", resp["error"])
+				}
+			},
+		},
+		{
+			name: "account with password",
+			reqBody: handlers.DownloadRequest{
+				Accounts: []string{"test1:secret"},
+				FromDate: testFromDate,
+				ToDate:   testToDate,
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, resp map[string]interface{}) {
+				if message, _ := resp["error"].(string); message == "" || strings.Contains(message, "secret") {
+					t.Errorf("Expected an error without the password, got %v", resp["error"])
+				}
+			},
+		},
 		{
 			name:           "invalid JSON",
 			reqBody:        "invalid json",
@@ -141,7 +217,7 @@ func TestDownloadHandler_DownloadSync_AllCases(t *testing.T) {
 			reqBody: handlers.DownloadRequest{
 				Accounts: []string{"test1"},
 				FromDate: "",
-				ToDate:   "2024-01-31",
+				ToDate:   testToDate,
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -149,7 +225,7 @@ func TestDownloadHandler_DownloadSync_AllCases(t *testing.T) {
 			name: "only to date missing",
 			reqBody: handlers.DownloadRequest{
 				Accounts: []string{"test1"},
-				FromDate: "2024-01-01",
+				FromDate: testFromDate,
 				ToDate:   "",
 			},
 			expectedStatus: http.StatusOK,
@@ -199,8 +275,8 @@ func TestDownloadHandler_DownloadAsync_AllCases(t *testing.T) {
 			name: "with specific accounts",
 			reqBody: handlers.DownloadRequest{
 				Accounts: []string{"test1", "test2"},
-				FromDate: "2024-01-01",
-				ToDate:   "2024-01-31",
+				FromDate: testFromDate,
+				ToDate:   testToDate,
 			},
 			mockAccounts:   []string{"test1", "test2"},
 			expectedStatus: http.StatusAccepted,
@@ -234,6 +310,19 @@ func TestDownloadHandler_DownloadAsync_AllCases(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "unknown account",
+			reqBody: handlers.DownloadRequest{
+				Accounts: []string{"test1", "test3"},
+			},
+			mockAccounts:   []string{"test1", "test2"},
+			expectedStatus: http.StatusNotFound,
+			checkResponse: func(t *testing.T, resp map[string]interface{}) {
+				if resp["error"] != "Account test3 not found" {
+					t.Errorf("Expected the unknown account in the error, got %v", resp["error"])
+				}
+			},
+		},
 		{
 			name:           "invalid JSON",
 			reqBody:        "{invalid}",
@@ -244,8 +333,8 @@ func TestDownloadHandler_DownloadAsync_AllCases(t *testing.T) {
 			name: "with mode parameter",
 			reqBody: handlers.DownloadRequest{
 				Accounts: []string{"test1"},
-				FromDate: "2024-01-01",
-				ToDate:   "2024-01-31",
+				FromDate: testFromDate,
+				ToDate:   testToDate,
 				Mode:     "fast",
 			},
 			mockAccounts:   []string{"test1"},
@@ -426,8 +515,8 @@ func TestDownloadRequest_Structure(t *testing.T) {
 	// Test that DownloadRequest properly marshals/unmarshals
 	req := handlers.DownloadRequest{
 		Accounts: []string{"acc1", "acc2"},
-		FromDate: "2024-01-01",
-		ToDate:   "2024-01-31",
+		FromDate: testFromDate,
+		ToDate:   testToDate,
 		Mode:     "fast",
 	}
 
@@ -448,10 +537,10 @@ func TestDownloadRequest_Structure(t *testing.T) {
 	if len(decoded.Accounts) != 2 {
 		t.Error("Accounts not properly decoded")
 	}
-	if decoded.FromDate != "2024-01-01" {
+	if decoded.FromDate != testFromDate {
 		t.Error("FromDate not properly decoded")
 	}
-	if decoded.ToDate != "2024-01-31" {
+	if decoded.ToDate != testToDate {
 		t.Error("ToDate not properly decoded")
 	}
 	if decoded.Mode != "fast" {
@@ -2,18 +2,34 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/yhonda-ohishi/etc_meisai_scraper/src/handlers"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/models"
 	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
 )
 
+// testFromDate/testToDate は明細サイトの保持期間内に収まる前月1日〜末日
+var testFromDate, testToDate = func() (string, string) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 1, -1)
+	return from.Format("2006-01-02"), to.Format("2006-01-02")
+}()
+
 // MockDownloadService implements DownloadServiceInterface for testing
 type MockDownloadService struct {
 	accountIDs []string
+	syncResult *models.DownloadResult
+	listFilter services.JobFilter
+	resolved   []string
 }
 
 func (m *MockDownloadService) GetAllAccountIDs() []string {
@@ -24,6 +40,60 @@ func (m *MockDownloadService) ProcessAsync(jobID string, accounts []string, from
 	// Mock implementation
 }
 
+func (m *MockDownloadService) DownloadSync(ctx context.Context, accounts []string, fromDate, toDate string) (*models.DownloadResult, error) {
+	if m.syncResult != nil {
+		return m.syncResult, nil
+	}
+	return &models.DownloadResult{Success: true, Records: []models.ETCMeisaiRecord{}}, nil
+}
+
+func (m *MockDownloadService) ResolveAccounts(accounts []string) ([]string, error) {
+	if len(accounts) == 0 {
+		if len(m.accountIDs) == 0 {
+			return nil, services.ErrNoAccountsConfigured
+		}
+		accounts = m.accountIDs
+	}
+	resolved := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		if account == "unknown" {
+			return nil, &services.AccountError{AccountID: account, Err: services.ErrUnknownAccount}
+		}
+		resolved = append(resolved, account)
+	}
+	m.resolved = resolved
+	return resolved, nil
+}
+
+func (m *MockDownloadService) CancelJob(ctx context.Context, jobID string) (*services.DownloadJob, error) {
+	switch jobID {
+	case "test-job-123":
+		now := time.Now()
+		return &services.DownloadJob{ID: jobID, Status: "cancelled", Progress: 50, CompletedAt: &now, CancelledAt: &now}, nil
+	case "finished-job":
+		return nil, fmt.Errorf("%w (status: completed)", services.ErrJobAlreadyFinished)
+	}
+	return nil, services.ErrJobNotFound
+}
+
+func (m *MockDownloadService) ListJobs(filter services.JobFilter) (*services.JobPage, error) {
+	if filter.PageToken == "bad-token" {
+		return nil, services.ErrInvalidPageToken
+	}
+	m.listFilter = filter
+	return &services.JobPage{
+		Jobs: []*services.DownloadJob{{
+			ID:     "test-job-123",
+			Status: "completed",
+			Accounts: []services.AccountResult{
+				{AccountID: "acc1", Status: "completed", Progress: 100},
+				{AccountID: "acc2", Status: "failed", Progress: 100, ErrorCode: "INVALID_CREDENTIALS"},
+			},
+		}},
+		NextPageToken: "next-token",
+	}, nil
+}
+
 func (m *MockDownloadService) GetJobStatus(jobID string) (*services.DownloadJob, bool) {
 	if jobID == "test-job-123" {
 		return &services.DownloadJob{
@@ -45,8 +115,8 @@ func TestDownloadHandler_DownloadSync(t *testing.T) {
 	// Create request
 	reqBody := handlers.DownloadRequest{
 		Accounts: []string{"test1"},
-		FromDate: "2024-01-01",
-		ToDate:   "2024-01-31",
+		FromDate: testFromDate,
+		ToDate:   testToDate,
 	}
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest("POST", "/api/download/sync", bytes.NewReader(body))
@@ -71,6 +141,42 @@ func TestDownloadHandler_DownloadSync(t *testing.T) {
 	}
 }
 
+func TestDownloadHandler_DownloadSync_AccountFailure(t *testing.T) {
+	mockService := &MockDownloadService{
+		syncResult: &models.DownloadResult{
+			Success:     false,
+			RecordCount: 1,
+			CSVPath:     "downloads/test1_meisai.csv",
+			Records:     []models.ETCMeisaiRecord{{AccountID: "test1", ExitIC: "横浜", Amount: 1320}},
+			Error:       "test2: login failed",
+		},
+	}
+	handler := handlers.NewDownloadHandler(mockService)
+
+	body, _ := json.Marshal(handlers.DownloadRequest{
+		Accounts: []string{"test1", "test2"},
+		FromDate: testFromDate,
+		ToDate:   testToDate,
+	})
+	req := httptest.NewRequest("POST", "/api/download/sync", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.DownloadSync(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response models.DownloadResult
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Success || response.Error != "test2: login failed" {
+		t.Errorf("Expected the account error, got %+v", response)
+	}
+	if response.RecordCount != 1 || len(response.Records) != 1 || response.Records[0].ExitIC != "横浜" {
+		t.Errorf("Expected the downloaded records, got %+v", response.Records)
+	}
+}
+
 func TestDownloadHandler_DownloadAsync(t *testing.T) {
 	// Setup
 	mockService := &MockDownloadService{
@@ -81,8 +187,8 @@ func TestDownloadHandler_DownloadAsync(t *testing.T) {
 	// Create request with empty accounts (should use all accounts)
 	reqBody := handlers.DownloadRequest{
 		Accounts: []string{},
-		FromDate: "2024-01-01",
-		ToDate:   "2024-01-31",
+		FromDate: testFromDate,
+		ToDate:   testToDate,
 	}
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest("POST", "/api/download/async", bytes.NewReader(body))
@@ -109,6 +215,78 @@ func TestDownloadHandler_DownloadAsync(t *testing.T) {
 	if response["status"] != "pending" {
 		t.Errorf("Expected status 'pending', got %s", response["status"])
 	}
+	if fmt.Sprint(mockService.resolved) != "[test1 test2]" {
+		t.Errorf("Expected all accounts to be resolved, got %v", mockService.resolved)
+	}
+}
+
+func TestDownloadHandler_UnresolvedAccounts(t *testing.T) {
+	tests := []struct {
+		name       string
+		accountIDs []string
+		accounts   []string
+		expected   int
+		message    string
+	}{
+		{"unknown account", []string{"test1"}, []string{"test1", "unknown"}, http.StatusNotFound, "Account unknown not found"},
+		{"no accounts configured", nil, nil, http.StatusBadRequest, "No accounts configured"},
+	}
+
+	for _, tt := range tests {
+		for _, endpoint := range []string{"sync", "async"} {
+			t.Run(tt.name+" "+endpoint, func(t *testing.T) {
+				if endpoint == "sync" && tt.accounts == nil {
+					t.Skip("sync requires accounts")
+				}
+				handler := handlers.NewDownloadHandler(&MockDownloadService{accountIDs: tt.accountIDs})
+				body, _ := json.Marshal(handlers.DownloadRequest{Accounts: tt.accounts, FromDate: testFromDate, ToDate: testToDate})
+				req := httptest.NewRequest("POST", "/api/download/"+endpoint, bytes.NewReader(body))
+				w := httptest.NewRecorder()
+
+				if endpoint == "sync" {
+					handler.DownloadSync(w, req)
+				} else {
+					handler.DownloadAsync(w, req)
+				}
+
+				if w.Code != tt.expected {
+					t.Fatalf("Expected status %d, got %d", tt.expected, w.Code)
+				}
+				var response map[string]string
+				json.Unmarshal(w.Body.Bytes(), &response)
+				if response["error"] != tt.message {
+					t.Errorf("Expected error %q, got %q", tt.message, response["error"])
+				}
+			})
+		}
+	}
+}
+
+func TestDownloadHandler_DownloadAsync_RangeOutsideRetention(t *testing.T) {
+	mockService := &MockDownloadService{
+		accountIDs: []string{"test1"},
+	}
+	handler := handlers.NewDownloadHandler(mockService)
+
+	reqBody := handlers.DownloadRequest{
+		FromDate: "2000-01-01",
+		ToDate:   "2000-01-31",
+	}
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest("POST", "/api/download/async", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.DownloadAsync(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response map[string]string
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if !strings.Contains(response["error"], "retention window") {
+		t.Errorf("Expected retention error, got %q", response["error"])
+	}
 }
 
 func TestDownloadHandler_GetDownloadStatus(t *testing.T) {
@@ -140,4 +318,110 @@ func TestDownloadHandler_GetDownloadStatus(t *testing.T) {
 	if response.Progress != 50 {
 		t.Errorf("Expected progress 50, got %d", response.Progress)
 	}
-}
\ No newline at end of file
+}
+func TestDownloadHandler_CancelDownload(t *testing.T) {
+	handler := handlers.NewDownloadHandler(&MockDownloadService{})
+
+	tests := []struct {
+		name     string
+		method   string
+		jobID    string
+		expected int
+	}{
+		{"running job", http.MethodPost, "test-job-123", http.StatusOK},
+		{"unknown job", http.MethodPost, "missing-job", http.StatusNotFound},
+		{"finished job", http.MethodPost, "finished-job", http.StatusConflict},
+		{"missing job_id", http.MethodPost, "", http.StatusBadRequest},
+		{"wrong method", http.MethodGet, "test-job-123", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/download/cancel?job_id="+tt.jobID, nil)
+			w := httptest.NewRecorder()
+
+			handler.CancelDownload(w, req)
+
+			if w.Code != tt.expected {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expected, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var response handlers.JobStatus
+			json.Unmarshal(w.Body.Bytes(), &response)
+			if response.Status != "cancelled" || response.CancelledAt == nil {
+				t.Errorf("Expected a cancelled job, got %+v", response)
+			}
+		})
+	}
+}
+
+func TestDownloadHandler_ListDownloads(t *testing.T) {
+	mockService := &MockDownloadService{}
+	handler := handlers.NewDownloadHandler(mockService)
+
+	req := httptest.NewRequest("GET", "/api/download/jobs?status=failed,cancelled&status=interrupted&account_id=acc2&started_after=2025-04-01&started_before=2025-04-02T09:00:00%2B09:00&page_size=20&order_by=started_at", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListDownloads(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	filter := mockService.listFilter
+	if fmt.Sprint(filter.Statuses) != "[failed cancelled interrupted]" || filter.AccountID != "acc2" || filter.PageSize != 20 || !filter.Ascending {
+		t.Errorf("Unexpected filter %+v", filter)
+	}
+	if !filter.StartedAfter.Equal(time.Date(2025, 4, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Unexpected started_after %v", filter.StartedAfter)
+	}
+	if !filter.StartedBefore.Equal(time.Date(2025, 4, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected started_before %v", filter.StartedBefore)
+	}
+
+	var response handlers.JobList
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if len(response.Jobs) != 1 || response.NextPageToken != "next-token" {
+		t.Fatalf("Unexpected response %+v", response)
+	}
+	job := response.Jobs[0]
+	if job.JobID != "test-job-123" || job.AccountStatusCounts["completed"] != 1 || job.AccountStatusCounts["failed"] != 1 {
+		t.Errorf("Unexpected job summary %+v", job)
+	}
+	if len(job.Accounts) != 2 || job.Accounts[1].ErrorCode != "INVALID_CREDENTIALS" {
+		t.Errorf("Unexpected account results %+v", job.Accounts)
+	}
+}
+
+func TestDownloadHandler_ListDownloads_Errors(t *testing.T) {
+	handler := handlers.NewDownloadHandler(&MockDownloadService{})
+
+	tests := []struct {
+		name     string
+		method   string
+		query    string
+		expected int
+	}{
+		{"wrong method", http.MethodPost, "", http.StatusMethodNotAllowed},
+		{"invalid started_after", http.MethodGet, "started_after=yesterday", http.StatusBadRequest},
+		{"invalid page_size", http.MethodGet, "page_size=many", http.StatusBadRequest},
+		{"invalid order_by", http.MethodGet, "order_by=status", http.StatusBadRequest},
+		{"invalid page_token", http.MethodGet, "page_token=bad-token", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/download/jobs?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ListDownloads(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d: %s", tt.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package parser_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/internal/fakesite"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/parser"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func shiftJIS(t *testing.T, text string) []byte {
+	t.Helper()
+	data, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatalf("Failed to encode Shift_JIS: %v", err)
+	}
+	return data
+}
+
+const header = "利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考\r\n"
+
+func TestParse_SiteCSV(t *testing.T) {
+	exitAt := time.Date(2025, 1, 15, 9, 30, 0, 0, parser.Location)
+	data, err := fakesite.EncodeCSV([]fakesite.Record{
+		{
+			EntryAt: exitAt.Add(-45 * time.Minute), ExitAt: exitAt,
+			EntryIC: "東京", ExitIC: "横浜町田", BaseFare: 1320, Discount: 400, Fare: 920,
+			VehicleClass: "普通", VehicleNumber: "品川 300 あ 12-34", CardNumber: "************1234", Note: "深夜割引",
+		},
+		{
+			EntryAt: exitAt, ExitAt: exitAt.Add(time.Hour),
+			EntryIC: "横浜町田", ExitIC: "厚木", BaseFare: 800, Fare: 800,
+			VehicleClass: "普通", VehicleNumber: "品川 300 あ 12-34", CardNumber: "************1234",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode CSV: %v", err)
+	}
+
+	result, err := parser.Parse(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Encoding != parser.EncodingShiftJIS {
+		t.Errorf("Expected Shift_JIS, got %s", result.Encoding)
+	}
+	if len(result.Errors) != 0 || len(result.Records) != 2 {
+		t.Fatalf("Expected 2 records and no errors, got %d records, errors %v", len(result.Records), result.Errors)
+	}
+
+	record := result.Records[0]
+	if !record.UsageDate.Equal(exitAt) {
+		t.Errorf("Expected usage date %v, got %v", exitAt, record.UsageDate)
+	}
+	if record.EntryDate == nil || !record.EntryDate.Equal(exitAt.Add(-45*time.Minute)) {
+		t.Errorf("Unexpected entry date %v", record.EntryDate)
+	}
+	if record.EntryIC != "東京" || record.ExitIC != "横浜町田" {
+		t.Errorf("Unexpected ICs %q - %q", record.EntryIC, record.ExitIC)
+	}
+	if record.BaseAmount != 1320 || record.DiscountAmount != 400 || record.Amount != 920 {
+		t.Errorf("Unexpected amounts %d/%d/%d", record.BaseAmount, record.DiscountAmount, record.Amount)
+	}
+	if record.VehicleClass != "普通" || record.VehicleNumber != "品川 300 あ 12-34" ||
+		record.ETCCardNumber != "************1234" || record.Remarks != "深夜割引" {
+		t.Errorf("Unexpected text fields %+v", record)
+	}
+}
+
+func TestParse_ReportsBadLines(t *testing.T) {
+	csv := header +
+		"25/01/15,09:00,25/01/15,09:30,東京,横浜,1320,0,1320,普通,品川,****1234,\r\n" +
+		"25/01/15,09:00,25/13/40,09:30,東京,横浜,1320,0,1320,普通,品川,****1234,\r\n" +
+		"\r\n" +
+		"25/01/16,09:00,25/01/16,09:30,東京,横浜,1320,0,千円,普通,品川,****1234,\r\n" +
+		"25/01/17,09:00,25/01/17\r\n" +
+		",,2025/01/18,,,本線料金所,\"1,050\",,\"1,050\",普通,品川,****1234,単独\r\n"
+
+	result, err := parser.Parse(shiftJIS(t, csv))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.Records) != 2 {
+		t.Fatalf("Expected 2 good records, got %d", len(result.Records))
+	}
+	single := result.Records[1]
+	if single.EntryDate != nil || single.Amount != 1050 || single.BaseAmount != 1050 {
+		t.Errorf("Unexpected single-gantry record %+v", single)
+	}
+	if !single.UsageDate.Equal(time.Date(2025, 1, 18, 0, 0, 0, 0, parser.Location)) {
+		t.Errorf("Unexpected usage date %v", single.UsageDate)
+	}
+
+	expected := []struct {
+		line   int
+		column string
+	}{
+		{3, parser.ColumnExitDate},
+		{5, parser.ColumnAmount},
+		{6, ""},
+	}
+	if len(result.Errors) != len(expected) {
+		t.Fatalf("Expected %d line errors, got %v", len(expected), result.Errors)
+	}
+	for i, want := range expected {
+		got := result.Errors[i]
+		if got.Line != want.line || got.Column != want.column {
+			t.Errorf("Error %d: expected line %d column %q, got %v", i, want.line, want.column, got)
+		}
+	}
+	if !strings.HasPrefix(result.Errors[1].Error(), "line 5: 通行料金: ") {
+		t.Errorf("Unexpected error message %q", result.Errors[1].Error())
+	}
+}
+
+func TestParse_UTF8(t *testing.T) {
+	csv := "\xef\xbb\xbf" + header + "2025/01/15,09:00,2025/01/15,09:30,東京,横浜,1320,0,1320,普通,品川,****1234,\r\n"
+
+	result, err := parser.Parse([]byte(csv))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Encoding != parser.EncodingUTF8 || len(result.Records) != 1 || result.Records[0].ExitIC != "横浜" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
+
+func TestParse_MissingHeader(t *testing.T) {
+	tests := map[string][]byte{
+		"empty":          nil,
+		"no header":      shiftJIS(t, "25/01/15,09:00,25/01/15,09:30,東京,横浜,1320,0,1320,普通,品川,****1234,\r\n"),
+		"missing column": shiftJIS(t, strings.Replace(header, ",通行料金", "", 1)),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parser.Parse(data); !errors.Is(err, parser.ErrMissingHeader) {
+				t.Errorf("Expected ErrMissingHeader, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "user1_meisai.csv")
+	os.WriteFile(path, shiftJIS(t, header+"25/01/15,09:00,25/01/15,09:30,東京,横浜,1320,0,1320,普通,品川,****1234,\r\n"), 0644)
+
+	result, err := parser.ParseFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Records) != 1 || result.Records[0].CSVFileName != "user1_meisai.csv" {
+		t.Errorf("Unexpected records %+v", result.Records)
+	}
+
+	if _, err := parser.ParseFile(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
@@ -0,0 +1,276 @@
+package scraper_test
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+)
+
+// poolFactory counts Playwright starts and browser launches and keeps every launched browser.
+// New pages are page if set, otherwise fresh mock pages.
+type poolFactory struct {
+	page     scraper.PageInterface
+	runs     int
+	stops    int
+	browsers []*poolBrowser
+}
+
+type poolBrowser struct {
+	mocks.MockBrowser
+	closed   bool
+	contexts int
+}
+
+func (f *poolFactory) Install() error { return nil }
+
+func (f *poolFactory) Run() (scraper.PlaywrightInterface, error) {
+	f.runs++
+	return &mocks.MockPlaywright{
+		StopFunc: func() error {
+			f.stops++
+			return nil
+		},
+		Chromium: &mocks.MockBrowserType{
+			LaunchFunc: func(options scraper.BrowserTypeLaunchOptions) (scraper.BrowserInterface, error) {
+				b := &poolBrowser{}
+				b.NewContextFunc = func(options scraper.BrowserNewContextOptions) (scraper.BrowserContextInterface, error) {
+					b.contexts++
+					return &mocks.MockBrowserContext{
+						NewPageFunc: func() (scraper.PageInterface, error) {
+							if f.page != nil {
+								return f.page, nil
+							}
+							return mocks.NewMockPage(), nil
+						},
+					}, nil
+				}
+				b.CloseFunc = func() error {
+					b.closed = true
+					return nil
+				}
+				f.browsers = append(f.browsers, b)
+				return b, nil
+			},
+		},
+	}, nil
+}
+
+func newTestPool(factory *poolFactory, config scraper.BrowserPoolConfig) *scraper.BrowserPool {
+	return scraper.NewBrowserPool(factory, config, log.New(os.Stdout, "[TEST] ", log.LstdFlags))
+}
+
+func TestBrowserPool_SharesOneBrowser(t *testing.T) {
+	factory := &poolFactory{}
+	pool := newTestPool(factory, scraper.BrowserPoolConfig{})
+
+	if factory.runs != 0 {
+		t.Fatal("Playwright should not start before the first lease")
+	}
+
+	for i := 0; i < 3; i++ {
+		lease, err := pool.Acquire(context.Background())
+		if err != nil {
+			t.Fatalf("Acquire %d failed: %v", i, err)
+		}
+		lease.Release(false)
+	}
+
+	if factory.runs != 1 || len(factory.browsers) != 1 {
+		t.Errorf("Expected 1 Playwright start and 1 browser, got %d and %d", factory.runs, len(factory.browsers))
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !factory.browsers[0].closed || factory.stops != 1 {
+		t.Error("Expected Close to close the browser and stop Playwright")
+	}
+	if _, err := pool.Acquire(context.Background()); !errors.Is(err, scraper.ErrPoolClosed) {
+		t.Errorf("Expected ErrPoolClosed, got %v", err)
+	}
+}
+
+func TestBrowserPool_RecyclesAfterMaxUses(t *testing.T) {
+	factory := &poolFactory{}
+	pool := newTestPool(factory, scraper.BrowserPoolConfig{MaxUses: 2})
+	defer pool.Close()
+
+	first, _ := pool.Acquire(context.Background())
+	second, _ := pool.Acquire(context.Background())
+	third, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	if len(factory.browsers) != 2 {
+		t.Fatalf("Expected a new browser after 2 uses, got %d browsers", len(factory.browsers))
+	}
+	if third.Browser() == first.Browser() {
+		t.Error("Expected the third lease on the new browser")
+	}
+
+	// The old browser stays open until its last lease is released
+	first.Release(false)
+	if factory.browsers[0].closed {
+		t.Error("Retired browser closed while still leased")
+	}
+	second.Release(false)
+	if !factory.browsers[0].closed {
+		t.Error("Expected the retired browser to close after its last lease")
+	}
+	third.Release(false)
+	if factory.browsers[1].closed {
+		t.Error("Current browser should stay open")
+	}
+}
+
+func TestBrowserPool_ReplacesUnhealthyBrowser(t *testing.T) {
+	t.Run("disconnected", func(t *testing.T) {
+		factory := &poolFactory{}
+		pool := newTestPool(factory, scraper.BrowserPoolConfig{})
+		defer pool.Close()
+
+		lease, _ := pool.Acquire(context.Background())
+		lease.Release(false)
+		factory.browsers[0].Disconnected = true
+
+		if _, err := pool.Acquire(context.Background()); err != nil {
+			t.Fatalf("Acquire failed: %v", err)
+		}
+		if len(factory.browsers) != 2 || !factory.browsers[0].closed {
+			t.Error("Expected the disconnected browser to be closed and replaced")
+		}
+	})
+
+	t.Run("crashed", func(t *testing.T) {
+		factory := &poolFactory{}
+		pool := newTestPool(factory, scraper.BrowserPoolConfig{})
+		defer pool.Close()
+
+		lease, _ := pool.Acquire(context.Background())
+		lease.Release(true)
+		lease.Release(true) // no effect
+
+		if !factory.browsers[0].closed {
+			t.Error("Expected the crashed browser to be closed")
+		}
+		if _, err := pool.Acquire(context.Background()); err != nil {
+			t.Fatalf("Acquire failed: %v", err)
+		}
+		if len(factory.browsers) != 2 || factory.runs != 1 {
+			t.Errorf("Expected a new browser on the same Playwright, got %d browsers, %d runs", len(factory.browsers), factory.runs)
+		}
+	})
+}
+
+func TestBrowserPool_CapsConcurrentContexts(t *testing.T) {
+	factory := &poolFactory{}
+	pool := newTestPool(factory, scraper.BrowserPoolConfig{MaxContexts: 1})
+	defer pool.Close()
+
+	lease, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the second lease to wait, got %v", err)
+	}
+
+	acquired := make(chan error)
+	go func() {
+		second, err := pool.Acquire(context.Background())
+		if err == nil {
+			second.Release(false)
+		}
+		acquired <- err
+	}()
+	lease.Release(false)
+
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Errorf("Acquire after release failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Acquire did not proceed after the slot was released")
+	}
+}
+
+func TestETCScraper_UsesBrowserPool(t *testing.T) {
+	factory := &poolFactory{}
+	pool := newTestPool(factory, scraper.BrowserPoolConfig{})
+	defer pool.Close()
+
+	scraperFactory := &mocks.MockPlaywrightFactory{RunError: errors.New("scraper should not start playwright")}
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+
+	for _, userID := range []string{"user1", "user2"} {
+		config := &scraper.ScraperConfig{
+			UserID:       userID,
+			Password:     "pass",
+			DownloadPath: t.TempDir(),
+			TestMode:     true,
+			Browsers:     pool,
+		}
+		s, err := scraper.NewETCScraperWithFactory(config, logger, scraperFactory)
+		if err != nil {
+			t.Fatalf("Failed to create scraper: %v", err)
+		}
+		if err := s.Initialize(); err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+		s.Close()
+	}
+
+	if len(factory.browsers) != 1 {
+		t.Fatalf("Expected both scrapers to share 1 browser, got %d", len(factory.browsers))
+	}
+	browser := factory.browsers[0]
+	if browser.contexts != 2 {
+		t.Errorf("Expected a context per scraper, got %d", browser.contexts)
+	}
+	if browser.closed {
+		t.Error("Closing a scraper must not close the shared browser")
+	}
+}
+
+func TestETCScraper_ReleasesCrashedBrowser(t *testing.T) {
+	mockPage := mocks.NewMockPage()
+	mockPage.GotoError = errors.New("Target page, context or browser has been closed")
+	factory := &poolFactory{page: mockPage}
+	pool := newTestPool(factory, scraper.BrowserPoolConfig{})
+	defer pool.Close()
+
+	config := &scraper.ScraperConfig{
+		UserID:       "user1",
+		Password:     "pass",
+		DownloadPath: t.TempDir(),
+		TestMode:     true,
+		RetryCount:   -1,
+		Browsers:     pool,
+	}
+	s, err := scraper.NewETCScraperWithFactory(config, log.New(os.Stdout, "[TEST] ", log.LstdFlags), &mocks.MockPlaywrightFactory{})
+	if err != nil {
+		t.Fatalf("Failed to create scraper: %v", err)
+	}
+	if err := s.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if err := s.Login(); !errors.Is(err, scraper.ErrBrowserCrash) {
+		t.Fatalf("Expected ErrBrowserCrash, got %v", err)
+	}
+	s.Close()
+
+	if !factory.browsers[0].closed {
+		t.Error("Expected the crashed browser to be retired")
+	}
+}
@@ -0,0 +1,167 @@
+package scraper_test
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+)
+
+func TestETCScraper_DownloadMeisaiContext_Cancelled(t *testing.T) {
+	var pageClosed, browserClosed atomic.Bool
+
+	mockPage := mocks.NewMockPage()
+	mockPage.CloseFunc = func() error {
+		pageClosed.Store(true)
+		return nil
+	}
+	// CSV link exists but the download event never fires
+	mockPage.Locators["a:has-text('明細ＣＳＶ')"] = &mocks.MockLocator{CountValue: 1}
+
+	mockBrowser := &mocks.MockBrowser{
+		NewContextFunc: func(options scraper.BrowserNewContextOptions) (scraper.BrowserContextInterface, error) {
+			return &mocks.MockBrowserContext{
+				NewPageFunc: func() (scraper.PageInterface, error) { return mockPage, nil },
+			}, nil
+		},
+		CloseFunc: func() error {
+			browserClosed.Store(true)
+			return nil
+		},
+	}
+	factory := &mocks.MockPlaywrightFactory{
+		RunFunc: func() (scraper.PlaywrightInterface, error) {
+			return &mocks.MockPlaywright{
+				Chromium: &mocks.MockBrowserType{
+					LaunchFunc: func(options scraper.BrowserTypeLaunchOptions) (scraper.BrowserInterface, error) {
+						return mockBrowser, nil
+					},
+				},
+			}, nil
+		},
+	}
+
+	config := &scraper.ScraperConfig{
+		UserID:       "test",
+		Password:     "pass",
+		DownloadPath: "./test_downloads",
+		TestMode:     true,
+	}
+	defer os.RemoveAll(config.DownloadPath)
+
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	scraperInstance, err := scraper.NewETCScraperWithFactory(config, logger, factory)
+	if err != nil {
+		t.Fatalf("Failed to create scraper: %v", err)
+	}
+	if err := scraperInstance.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = scraperInstance.DownloadMeisaiContext(ctx, testRecentFrom(), testRecentTo())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Cancellation took too long: %v", elapsed)
+	}
+
+	// Browser teardown happens on the watcher goroutine
+	deadline := time.Now().Add(time.Second)
+	for !(pageClosed.Load() && browserClosed.Load()) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !pageClosed.Load() || !browserClosed.Load() {
+		t.Errorf("Expected page and browser to be closed, page=%v browser=%v", pageClosed.Load(), browserClosed.Load())
+	}
+
+	// Close after cancellation must be a no-op
+	if err := scraperInstance.Close(); err != nil {
+		t.Errorf("Close() after cancellation returned %v", err)
+	}
+}
+
+func TestETCScraper_InitializeContext_AlreadyCancelled(t *testing.T) {
+	config := &scraper.ScraperConfig{UserID: "test", Password: "pass", TestMode: true}
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	scraperInstance, err := scraper.NewETCScraperWithFactory(config, logger, createMockFactory(mocks.NewMockPage()))
+	if err != nil {
+		t.Fatalf("Failed to create scraper: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := scraperInstance.InitializeContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestAsContextScraper(t *testing.T) {
+	t.Run("wraps plain scraper and closes it on cancel", func(t *testing.T) {
+		var closed atomic.Bool
+		release := make(chan struct{})
+		defer close(release)
+
+		mock := mocks.NewConfigurableETCScraper()
+		mock.LoginFunc = func() error {
+			<-release
+			return nil
+		}
+		mock.CloseFunc = func() error {
+			closed.Store(true)
+			return nil
+		}
+
+		cs := scraper.AsContextScraper(mock)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		if err := cs.LoginContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+		}
+		if !closed.Load() {
+			t.Error("Expected scraper to be closed after cancellation")
+		}
+	})
+
+	t.Run("passes through results", func(t *testing.T) {
+		cs := scraper.AsContextScraper(mocks.NewConfigurableETCScraper())
+		path, err := cs.DownloadMeisaiContext(context.Background(), "2024-01-01", "2024-01-31")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if path != "/downloads/mock_2024-01-01_2024-01-31.csv" {
+			t.Errorf("Unexpected path: %s", path)
+		}
+	})
+
+	t.Run("returns native context scraper unchanged", func(t *testing.T) {
+		config := &scraper.ScraperConfig{UserID: "test", Password: "pass"}
+		etcScraper, _ := scraper.NewETCScraperWithFactory(config, nil, &mocks.MockPlaywrightFactory{})
+		if cs := scraper.AsContextScraper(etcScraper); cs != scraper.ContextScraper(etcScraper) {
+			t.Error("Expected ETCScraper to be returned as-is")
+		}
+	})
+}
+
+// testRecentFrom/testRecentTo return the first and last day of the previous month
+func testRecentFrom() string {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.Local).Format("2006-01-02")
+}
+
+func testRecentTo() string {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), 0, 0, 0, 0, 0, time.Local).Format("2006-01-02")
+}
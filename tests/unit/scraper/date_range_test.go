@@ -0,0 +1,104 @@
+package scraper_test
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+)
+
+func TestParseDateRange(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+
+	tests := []struct {
+		name          string
+		fromDate      string
+		toDate        string
+		expectError   bool
+		errorContains string
+	}{
+		{name: "hyphen format", fromDate: "2024-01-01", toDate: "2024-01-31"},
+		{name: "slash format", fromDate: "2024/01/01", toDate: "2024/01/31"},
+		{name: "single day", fromDate: "2024-01-15", toDate: "2024-01-15"},
+		{name: "invalid from date", fromDate: "2024-13-01", toDate: "2024-01-31", expectError: true, errorContains: "invalid from date"},
+		{name: "invalid to date", fromDate: "2024-01-01", toDate: "yesterday", expectError: true, errorContains: "invalid to date"},
+		{name: "reversed range", fromDate: "2024-02-01", toDate: "2024-01-01", expectError: true, errorContains: "from date is after to date"},
+		{name: "future to date", fromDate: "2024-01-01", toDate: tomorrow, expectError: true, errorContains: "to date is in the future"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dateRange, err := scraper.ParseDateRange(tt.fromDate, tt.toDate)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ParseDateRange() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err != nil {
+				var rangeErr *scraper.DateRangeError
+				if !errors.As(err, &rangeErr) {
+					t.Errorf("Expected *scraper.DateRangeError, got %T", err)
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Error should contain '%s', got '%s'", tt.errorContains, err.Error())
+				}
+				return
+			}
+			if dateRange.From.After(dateRange.To) {
+				t.Errorf("From %v should not be after To %v", dateRange.From, dateRange.To)
+			}
+		})
+	}
+}
+
+func TestETCScraper_DownloadMeisai_AppliesDateRange(t *testing.T) {
+	mockPage := mocks.NewMockPage()
+	selectors := map[string]string{
+		"select[name='fromYYYY']": "2024",
+		"select[name='fromMM']":   "01",
+		"select[name='fromDD']":   "05",
+		"select[name='toYYYY']":   "2024",
+		"select[name='toMM']":     "02",
+		"select[name='toDD']":     "20",
+	}
+	for selector := range selectors {
+		mockPage.Locators[selector] = &mocks.MockLocator{CountValue: 1}
+	}
+	mockPage.Locators["#searchPeriod"] = &mocks.MockLocator{
+		CountValue: 1,
+		TextValue:  "利用年月日 2024年01月05日 ～ 2024年02月20日",
+	}
+	mockPage.Locators["a:has-text('明細ＣＳＶ')"] = &mocks.MockLocator{CountValue: 1}
+	mockPage.SetDownloadHandler("test.csv")
+
+	config := &scraper.ScraperConfig{
+		UserID:       "test",
+		Password:     "pass",
+		DownloadPath: "./test_downloads",
+		TestMode:     true,
+	}
+	defer os.RemoveAll(config.DownloadPath)
+
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	scraperInstance, err := scraper.NewETCScraperWithFactory(config, logger, createMockFactory(mockPage))
+	if err != nil {
+		t.Fatalf("Failed to create scraper: %v", err)
+	}
+	if err := scraperInstance.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	if _, err := scraperInstance.DownloadMeisai("2024-01-05", "2024-02-20"); err != nil {
+		t.Fatalf("DownloadMeisai() unexpected error: %v", err)
+	}
+
+	for selector, expected := range selectors {
+		got := mockPage.Locators[selector].SelectedValues
+		if len(got) != 1 || got[0] != expected {
+			t.Errorf("Selector %s: expected %q, got %v", selector, expected, got)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package scraper_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+)
+
+func TestETCScraper_Login_TypedErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		expected  error
+		setupPage func(page *mocks.MockPage)
+	}{
+		{name: "invalid credentials", message: "ログインIDまたはパスワードが正しくありません", expected: scraper.ErrInvalidCredentials},
+		{name: "account locked", message: "アカウントがロックされています", expected: scraper.ErrAccountLocked},
+		{name: "password expired", message: "パスワードの有効期限が切れています", expected: scraper.ErrPasswordExpired},
+		{
+			name:     "maintenance page",
+			expected: scraper.ErrSiteMaintenance,
+			setupPage: func(page *mocks.MockPage) {
+				page.Locators["text=/システムメンテナンス中|メンテナンスのため/"] = &mocks.MockLocator{CountValue: 1}
+			},
+		},
+		{
+			name:     "browser crash",
+			expected: scraper.ErrBrowserCrash,
+			setupPage: func(page *mocks.MockPage) {
+				page.GotoError = errors.New("Target page, context or browser has been closed")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPage := mocks.NewMockPage()
+			if tt.message != "" {
+				mockPage.Locators[".error-message, .alert-danger, .error"] = &mocks.MockLocator{
+					CountValue: 1,
+					TextValue:  tt.message,
+				}
+			}
+			if tt.setupPage != nil {
+				tt.setupPage(mockPage)
+			}
+
+			s := newRetryTestScraper(t, mockPage, 3)
+			err := s.Login()
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, err)
+			}
+
+			var loginErr *scraper.LoginError
+			if tt.message != "" && (!errors.As(err, &loginErr) || loginErr.Message != tt.message) {
+				t.Errorf("Expected LoginError with site message %q, got %v", tt.message, err)
+			}
+			if got := len(s.Attempts()); got > 2 {
+				t.Errorf("Permanent errors should not be retried, got %d attempts", got)
+			}
+		})
+	}
+}
+
+func TestETCScraper_DownloadMeisai_TypedErrors(t *testing.T) {
+	t.Run("selector drift", func(t *testing.T) {
+		mockPage := mocks.NewMockPage()
+		mockPage.Locators["input[name='hakkoMeisai']"] = &mocks.MockLocator{CountValue: 3}
+
+		s := newRetryTestScraper(t, mockPage, 3)
+		_, err := s.DownloadMeisai(testRecentFrom(), testRecentTo())
+
+		var selectorErr *scraper.SelectorError
+		if !errors.Is(err, scraper.ErrSelectorDrift) || !errors.As(err, &selectorErr) {
+			t.Fatalf("Expected SelectorError, got %v", err)
+		}
+		if selectorErr.Element != "CSV download link" || len(selectorErr.Selectors) == 0 {
+			t.Errorf("Unexpected selector error: %+v", selectorErr)
+		}
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		s := newRetryTestScraper(t, mocks.NewMockPage(), 3)
+		_, err := s.DownloadMeisai(testRecentFrom(), testRecentTo())
+		if !errors.Is(err, scraper.ErrNoResults) {
+			t.Fatalf("Expected ErrNoResults, got %v", err)
+		}
+	})
+
+	t.Run("download timeout", func(t *testing.T) {
+		mockPage := mocks.NewMockPage()
+		mockPage.Locators["a:has-text('明細ＣＳＶ')"] = &mocks.MockLocator{CountValue: 1}
+
+		s := newRetryTestScraper(t, mockPage, 1)
+		_, err := s.DownloadMeisai(testRecentFrom(), testRecentTo())
+
+		var stepErr *scraper.StepError
+		if !errors.Is(err, scraper.ErrDownloadTimeout) || !errors.As(err, &stepErr) {
+			t.Fatalf("Expected StepError wrapping ErrDownloadTimeout, got %v", err)
+		}
+		if stepErr.Step != scraper.StepDownloadSave || stepErr.Attempts != 2 {
+			t.Errorf("Unexpected step error: %+v", stepErr)
+		}
+	})
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{nil, ""},
+		{&scraper.LoginError{Kind: scraper.ErrAccountLocked, Message: "locked"}, "ACCOUNT_LOCKED"},
+		{&scraper.StepError{Step: scraper.StepDownloadSave, Attempts: 4, Err: scraper.ErrDownloadTimeout}, "DOWNLOAD_TIMEOUT"},
+		{&scraper.SelectorError{Element: "CSV download link"}, "SELECTOR_DRIFT"},
+		{fmt.Errorf("download aborted: %w", context.Canceled), "CANCELLED"},
+		{&scraper.DateRangeError{Reason: "bad"}, "INVALID_DATE_RANGE"},
+		{errors.New("something else"), "UNKNOWN"},
+	}
+
+	for _, tt := range tests {
+		if got := scraper.ErrorCode(tt.err); got != tt.expected {
+			t.Errorf("ErrorCode(%v) = %q, expected %q", tt.err, got, tt.expected)
+		}
+	}
+}
@@ -311,6 +311,7 @@ func TestETCScraper_Login(t *testing.T) {
 		setupMock     func() (*mocks.MockPage, *mocks.MockPlaywrightFactory)
 		expectError   bool
 		errorContains string
+		errorIs       error
 	}{
 		{
 			name: "successful login",
@@ -452,10 +453,10 @@ func TestETCScraper_Login(t *testing.T) {
 			errorContains: "login failed: Invalid credentials",
 		},
 		{
-			name: "login completed without logout button",
+			name: "login page shown again without error text",
 			setupMock: func() (*mocks.MockPage, *mocks.MockPlaywrightFactory) {
 				mockPage := mocks.NewMockPage()
-				// Setup login link
+				// The site answers with the login form again and no message
 				mockPage.Locators["a[href*='funccode=1013000000']"] = &mocks.MockLocator{CountValue: 1}
 				mockPage.Locators["input[name='risLoginId']"] = &mocks.MockLocator{CountValue: 1}
 				mockPage.Locators["input[name='risPassword']"] = &mocks.MockLocator{CountValue: 1}
@@ -465,7 +466,24 @@ func TestETCScraper_Login(t *testing.T) {
 				factory := createMockFactory(mockPage)
 				return mockPage, factory
 			},
-			expectError: false,
+			expectError:   true,
+			errorContains: "login form was shown again",
+			errorIs:       scraper.ErrInvalidCredentials,
+		},
+		{
+			name: "neither logout link nor login form",
+			setupMock: func() (*mocks.MockPage, *mocks.MockPlaywrightFactory) {
+				mockPage := mocks.NewMockPage()
+				mockPage.Locators["a[href*='funccode=1013000000']"] = &mocks.MockLocator{CountValue: 1}
+				mockPage.Locators["input[name='risLoginId']"] = &mocks.MockLocator{CountValue: 0}
+				mockPage.Locators["input[name='risPassword']"] = &mocks.MockLocator{CountValue: 0}
+				mockPage.Locators["input[type='button'][value='ログイン']"] = &mocks.MockLocator{CountValue: 1}
+				mockPage.Locators["a:has-text('ログアウト')"] = &mocks.MockLocator{CountValue: 0}
+				factory := createMockFactory(mockPage)
+				return mockPage, factory
+			},
+			expectError: true,
+			errorIs:     scraper.ErrSelectorDrift,
 		},
 	}
 
@@ -529,6 +547,9 @@ func TestETCScraper_Login(t *testing.T) {
 					t.Errorf("Error should contain '%s', got '%s'", tt.errorContains, err.Error())
 				}
 			}
+			if tt.errorIs != nil && !errors.Is(err, tt.errorIs) {
+				t.Errorf("Expected %v, got %v", tt.errorIs, err)
+			}
 		})
 	}
 }
@@ -558,10 +579,8 @@ func TestETCScraper_DownloadMeisai(t *testing.T) {
 				}
 
 				// Setup date fields
-				mockPage.Locators["input[name='fromDate']"] = &mocks.MockLocator{CountValue: 1}
-				mockPage.Locators["input[name='toDate']"] = &mocks.MockLocator{CountValue: 1}
-				mockPage.Locators["button:has-text('検索')"] = &mocks.MockLocator{CountValue: 1}
-				mockPage.Locators["button:has-text('CSV')"] = &mocks.MockLocator{CountValue: 1}
+				mockPage.Locators["input[name='focusTarget']"] = &mocks.MockLocator{CountValue: 1}
+				mockPage.Locators["a:has-text('明細ＣＳＶ')"] = &mocks.MockLocator{CountValue: 1}
 
 				// Setup download handler
 				mockPage.OnFunc = func(event string, handler interface{}) {
@@ -602,10 +621,8 @@ func TestETCScraper_DownloadMeisai(t *testing.T) {
 				mockPage.GotoError = errors.New("navigation failed")
 
 				// Setup fields even though navigation failed
-				mockPage.Locators["input[name='fromDate']"] = &mocks.MockLocator{CountValue: 1}
-				mockPage.Locators["input[name='toDate']"] = &mocks.MockLocator{CountValue: 1}
-				mockPage.Locators["button:has-text('検索')"] = &mocks.MockLocator{CountValue: 1}
-				mockPage.Locators["button:has-text('CSV')"] = &mocks.MockLocator{CountValue: 1}
+				mockPage.Locators["input[name='focusTarget']"] = &mocks.MockLocator{CountValue: 1}
+				mockPage.Locators["a:has-text('明細ＣＳＶ')"] = &mocks.MockLocator{CountValue: 1}
 
 				// Setup download handler to complete successfully even if navigation failed
 				mockPage.OnFunc = func(event string, handler interface{}) {
@@ -634,9 +651,7 @@ func TestETCScraper_DownloadMeisai(t *testing.T) {
 				mockPage := mocks.NewMockPage()
 
 				// Setup fields but no download button
-				mockPage.Locators["input[name='fromDate']"] = &mocks.MockLocator{CountValue: 1}
-				mockPage.Locators["input[name='toDate']"] = &mocks.MockLocator{CountValue: 1}
-				mockPage.Locators["button:has-text('検索')"] = &mocks.MockLocator{CountValue: 1}
+				mockPage.Locators["input[name='focusTarget']"] = &mocks.MockLocator{CountValue: 1}
 				// No download button
 
 				factory := createMockFactory(mockPage)
@@ -645,7 +660,7 @@ func TestETCScraper_DownloadMeisai(t *testing.T) {
 			fromDate:      "2024-01-01",
 			toDate:        "2024-01-31",
 			expectError:   true,
-			errorContains: "could not find download button",
+			errorContains: "CSV download link not found",
 		},
 		{
 			name: "download timeout",
@@ -653,10 +668,8 @@ func TestETCScraper_DownloadMeisai(t *testing.T) {
 				mockPage := mocks.NewMockPage()
 
 				// Setup fields
-				mockPage.Locators["input[name='fromDate']"] = &mocks.MockLocator{CountValue: 1}
-				mockPage.Locators["input[name='toDate']"] = &mocks.MockLocator{CountValue: 1}
-				mockPage.Locators["button:has-text('検索')"] = &mocks.MockLocator{CountValue: 1}
-				mockPage.Locators["button:has-text('CSV')"] = &mocks.MockLocator{CountValue: 1}
+				mockPage.Locators["input[name='focusTarget']"] = &mocks.MockLocator{CountValue: 1}
+				mockPage.Locators["a:has-text('明細ＣＳＶ')"] = &mocks.MockLocator{CountValue: 1}
 
 				// Don't trigger download handler
 
@@ -668,6 +681,54 @@ func TestETCScraper_DownloadMeisai(t *testing.T) {
 			expectError:   true,
 			errorContains: "download timeout",
 		},
+		{
+			name: "from date after to date",
+			setupMock: func() (*mocks.MockPage, *mocks.MockPlaywrightFactory) {
+				mockPage := mocks.NewMockPage()
+				factory := createMockFactory(mockPage)
+				return mockPage, factory
+			},
+			fromDate:      "2024-02-01",
+			toDate:        "2024-01-31",
+			expectError:   true,
+			errorContains: "from date is after to date",
+		},
+		{
+			name: "year not offered by search form",
+			setupMock: func() (*mocks.MockPage, *mocks.MockPlaywrightFactory) {
+				mockPage := mocks.NewMockPage()
+				mockPage.Locators["select[name='fromYYYY']"] = &mocks.MockLocator{
+					CountValue: 1,
+					SelectOptionFunc: func(values []string, options scraper.LocatorSelectOptionOptions) ([]string, error) {
+						return []string{}, nil
+					},
+				}
+				factory := createMockFactory(mockPage)
+				return mockPage, factory
+			},
+			fromDate:      "2024-01-01",
+			toDate:        "2024-01-31",
+			expectError:   true,
+			errorContains: "site does not offer 2024",
+		},
+		{
+			name: "result page shows different period",
+			setupMock: func() (*mocks.MockPage, *mocks.MockPlaywrightFactory) {
+				mockPage := mocks.NewMockPage()
+				mockPage.Locators["input[name='focusTarget']"] = &mocks.MockLocator{CountValue: 1}
+				mockPage.Locators["#searchPeriod"] = &mocks.MockLocator{
+					CountValue: 1,
+					TextValue:  "2023年12月01日 ～ 2023年12月31日",
+				}
+				mockPage.Locators["a:has-text('明細ＣＳＶ')"] = &mocks.MockLocator{CountValue: 1}
+				factory := createMockFactory(mockPage)
+				return mockPage, factory
+			},
+			fromDate:      "2024-01-01",
+			toDate:        "2024-01-31",
+			expectError:   true,
+			errorContains: "result page shows 2023-12-01 - 2023-12-31",
+		},
 	}
 
 	for _, tt := range tests {
@@ -696,11 +757,12 @@ func TestETCScraper_DownloadMeisai(t *testing.T) {
 
 			// Normal test cases
 			config := &scraper.ScraperConfig{
-				UserID:       "test",
-				Password:     "pass",
-				DownloadPath: "./test_downloads",
-				TestMode:     true,
-				Timeout:      1000, // Short timeout for test
+				UserID:          "test",
+				Password:        "pass",
+				DownloadPath:    "./test_downloads",
+				TestMode:        true,
+				Timeout:         1000, // Short timeout for test
+				DownloadTimeout: 500 * time.Millisecond,
 			}
 			defer os.RemoveAll(config.DownloadPath)
 
@@ -1003,6 +1065,7 @@ func TestETCScraper_DownloadMeisaiToBuffer(t *testing.T) {
 								mockDownload := &mocks.MockDownload{
 									SuggestedName: "test.csv",
 									SaveError:     nil,
+									Content:       []byte("test csv data"),
 								}
 								downloadHandler(mockDownload)
 							}
@@ -1012,10 +1075,8 @@ func TestETCScraper_DownloadMeisaiToBuffer(t *testing.T) {
 
 				// Setup locators for successful flow
 				mockPage.Locators = map[string]*mocks.MockLocator{
-					"input[name='fromDate']": {CountValue: 1},
-					"input[name='toDate']":   {CountValue: 1},
-					"button:has-text('検索')":  {CountValue: 1},
-					"button:has-text('CSV')": {CountValue: 1},
+					"input[name='focusTarget']": {CountValue: 1},
+					"a:has-text('明細ＣＳＶ')":      {CountValue: 1},
 				}
 
 				mockContext := &mocks.MockBrowserContext{
@@ -1089,7 +1150,7 @@ func TestETCScraper_DownloadMeisaiToBuffer(t *testing.T) {
 			setupMock: func() *mocks.MockPlaywrightFactory {
 				mockPage := mocks.NewMockPage()
 				mockPage.Locators = map[string]*mocks.MockLocator{
-					"button:has-text('CSV')": {CountValue: 1},
+					"a:has-text('明細ＣＳＶ')": {CountValue: 1},
 				}
 				mockPage.OnFunc = func(event string, handler interface{}) {
 					if event == "download" {
@@ -1190,15 +1251,16 @@ func (m *MockPlaywrightDownload) String() string {
 func TestETCScraper_waitForNavigation(t *testing.T) {
 	t.Run("non-TestMode path", func(t *testing.T) {
 		config := &scraper.ScraperConfig{
-			UserID:   "test",
-			Password: "test",
-			TestMode: false, // Non-TestMode to trigger sleep
+			UserID:       "test",
+			Password:     "test",
+			DownloadPath: t.TempDir(),
+			TestMode:     false, // Non-TestMode to trigger sleep
 		}
 		logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 
 		mockPage := mocks.NewMockPage()
 		mockPage.Locators = map[string]*mocks.MockLocator{
-			"button:has-text('検索')": {CountValue: 1},
+			"input[name='focusTarget']": {CountValue: 1},
 		}
 		factory := createMockFactory(mockPage)
 
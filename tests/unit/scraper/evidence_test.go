@@ -0,0 +1,137 @@
+package scraper_test
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+)
+
+// fakeDialog mimics the parts of playwright.Dialog the scraper uses
+type fakeDialog struct {
+	message string
+}
+
+func (d *fakeDialog) Message() string                   { return d.message }
+func (d *fakeDialog) Accept(promptText ...string) error { return nil }
+
+func TestETCScraper_DownloadMeisai_CapturesEvidence(t *testing.T) {
+	mockPage := mocks.NewMockPage()
+	mockPage.URLValue = "https://www.etc-meisai.jp/etc/R?funccode=1032000000"
+	mockPage.ContentValue = "<html><body>result page</body></html>"
+	mockPage.Locators["input[name='hakkoMeisai']"] = &mocks.MockLocator{CountValue: 3}
+
+	handlers := map[string]interface{}{}
+	mockPage.OnFunc = func(event string, handler interface{}) {
+		handlers[event] = handler
+	}
+
+	s := newRetryTestScraper(t, mockPage, 0)
+	handlers["dialog"].(func(interface{}))(&fakeDialog{message: "明細ＣＳＶを出力します。よろしいですか？"})
+	handlers["console"].(func(string))("[error] goOutput is not defined")
+
+	_, err := s.DownloadMeisai(testRecentFrom(), testRecentTo())
+	if !errors.Is(err, scraper.ErrSelectorDrift) {
+		t.Fatalf("Expected ErrSelectorDrift, got %v", err)
+	}
+
+	dir := scraper.EvidencePath(err)
+	if dir == "" {
+		t.Fatal("Expected an evidence bundle path on the error")
+	}
+
+	screenshot, _ := os.ReadFile(filepath.Join(dir, scraper.EvidenceScreenshotFile))
+	if string(screenshot) != "mock screenshot" {
+		t.Errorf("Unexpected screenshot: %q", screenshot)
+	}
+	html, _ := os.ReadFile(filepath.Join(dir, scraper.EvidenceHTMLFile))
+	if string(html) != mockPage.ContentValue {
+		t.Errorf("Unexpected page HTML: %q", html)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, scraper.EvidenceManifestFile))
+	if err != nil {
+		t.Fatalf("Manifest not written: %v", err)
+	}
+	var evidence scraper.Evidence
+	if err := json.Unmarshal(raw, &evidence); err != nil {
+		t.Fatalf("Invalid manifest: %v", err)
+	}
+	if evidence.Step != scraper.StepCSVLink || evidence.URL != mockPage.URLValue {
+		t.Errorf("Unexpected step/URL: %+v", evidence)
+	}
+	if evidence.Element != "CSV download link" || len(evidence.Selectors) == 0 {
+		t.Errorf("Expected the failed selectors, got %+v", evidence)
+	}
+	if len(evidence.Dialogs) != 1 || !strings.Contains(evidence.Dialogs[0], "明細ＣＳＶ") {
+		t.Errorf("Expected the dialog message, got %v", evidence.Dialogs)
+	}
+	if len(evidence.Console) != 1 || evidence.Console[0] != "[error] goOutput is not defined" {
+		t.Errorf("Expected the console log, got %v", evidence.Console)
+	}
+}
+
+func TestHTTPScraper_CapturesEvidence(t *testing.T) {
+	server := newUnsupportedServer(t)
+
+	s := newHTTPScraper(t, server.URL+"/", "user", "pass")
+	err := s.Login()
+	dir := scraper.EvidencePath(err)
+	if dir == "" {
+		t.Fatalf("Expected an evidence bundle path, got %v", err)
+	}
+
+	html, _ := os.ReadFile(filepath.Join(dir, scraper.EvidenceHTMLFile))
+	if !strings.Contains(string(html), `<div id="app">`) {
+		t.Errorf("Expected the page HTML, got %q", html)
+	}
+	if _, err := os.Stat(filepath.Join(dir, scraper.EvidenceScreenshotFile)); !os.IsNotExist(err) {
+		t.Error("The HTTP backend has no screenshot to save")
+	}
+}
+
+func TestHTTPScraper_EvidencePerAccount(t *testing.T) {
+	server := newUnsupportedServer(t)
+	sessionFolder := t.TempDir()
+
+	// Accounts of a job share the session folder and can fail at the same step at the same time
+	dirs := map[string]bool{}
+	for _, userID := range []string{"corp1", "corp2", "corp1"} {
+		s, err := scraper.NewHTTPScraper(&scraper.ScraperConfig{
+			UserID:        userID,
+			Password:      "pass",
+			BaseURL:       server.URL + "/",
+			SessionFolder: sessionFolder,
+			RetryCount:    -1,
+			TestMode:      true,
+		}, log.New(os.Stdout, "[TEST] ", log.LstdFlags))
+		if err != nil {
+			t.Fatalf("Failed to create scraper: %v", err)
+		}
+		if err := s.Initialize(); err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+		dir := scraper.EvidencePath(s.Login())
+		s.Close()
+
+		if filepath.Dir(dir) != filepath.Join(sessionFolder, "evidence") || !strings.Contains(filepath.Base(dir), "_"+userID+"_") {
+			t.Errorf("Expected an evidence folder of %s under the session folder, got %q", userID, dir)
+		}
+		if dirs[dir] {
+			t.Errorf("Evidence folder %s was reused", dir)
+		}
+		dirs[dir] = true
+
+		raw, _ := os.ReadFile(filepath.Join(dir, scraper.EvidenceManifestFile))
+		var evidence scraper.Evidence
+		if err := json.Unmarshal(raw, &evidence); err != nil || evidence.AccountID != userID {
+			t.Errorf("Expected the manifest of %s, got %+v (%v)", userID, evidence, err)
+		}
+	}
+}
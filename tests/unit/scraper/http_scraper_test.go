@@ -0,0 +1,387 @@
+package scraper_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/internal/fakesite"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// lastMonth returns the first and last day of the previous month
+func lastMonth() (time.Time, time.Time) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.Local)
+	return from, from.AddDate(0, 1, -1)
+}
+
+func newHTTPScraper(t *testing.T, baseURL, userID, password string) *scraper.HTTPScraper {
+	t.Helper()
+
+	config := &scraper.ScraperConfig{
+		UserID:       userID,
+		Password:     password,
+		BaseURL:      baseURL,
+		DownloadPath: t.TempDir(),
+		Timeout:      5000,
+		RetryCount:   1,
+		TestMode:     true,
+	}
+	s, err := scraper.NewHTTPScraper(config, log.New(os.Stdout, "[TEST] ", log.LstdFlags))
+	if err != nil {
+		t.Fatalf("Failed to create scraper: %v", err)
+	}
+	if err := s.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestHTTPScraper_FakeSite_Download(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+
+	from, to := lastMonth()
+	usedAt := from.Add(10*24*time.Hour + 8*time.Hour)
+	site.AddAccount("http-user", "http-pass", fakesite.Record{
+		EntryAt: usedAt.Add(-40 * time.Minute), ExitAt: usedAt,
+		EntryIC: "東京", ExitIC: "横浜", BaseFare: 1320, Fare: 1320,
+		VehicleClass: "普通車", VehicleNumber: "品川 300 あ 12-34", CardNumber: "1234567890123456",
+	})
+
+	s := newHTTPScraper(t, site.URL+"/", "http-user", "http-pass")
+	if err := s.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	// The session drops before the search; the scraper must log in again
+	site.ExpireSessions()
+
+	path, err := s.DownloadMeisai(from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		t.Fatalf("DownloadMeisai failed: %v", err)
+	}
+	if !strings.HasPrefix(filepath.Base(path), "http-user_") {
+		t.Errorf("Expected file name prefixed with the user ID, got %s", path)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Downloaded file not readable: %v", err)
+	}
+	decoded, _ := japanese.ShiftJIS.NewDecoder().Bytes(raw)
+	if !strings.Contains(string(decoded), "東京,横浜,1320") {
+		t.Errorf("Downloaded CSV does not contain the record: %q", decoded)
+	}
+	if site.Downloads() != 1 {
+		t.Errorf("Expected 1 CSV download, got %d", site.Downloads())
+	}
+
+	expired := false
+	for _, attempt := range s.Attempts() {
+		if attempt.Class == scraper.ErrorClassSessionExpired {
+			expired = true
+		}
+	}
+	if !expired {
+		t.Error("Expected the expired session to be recorded")
+	}
+}
+
+func TestHTTPScraper_FakeSite_ShiftJIS(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+	site.SetShiftJIS(true)
+
+	from, to := lastMonth()
+	usedAt := from.Add(10*24*time.Hour + 8*time.Hour)
+	site.AddAccount("sjis-user", "パスワード", fakesite.Record{
+		EntryAt: usedAt.Add(-40 * time.Minute), ExitAt: usedAt,
+		EntryIC: "東京", ExitIC: "横浜", BaseFare: 1320, Fare: 1320,
+	})
+
+	// The site only accepts the password when it is posted in Shift_JIS
+	s := newHTTPScraper(t, site.URL+"/", "sjis-user", "パスワード")
+	if err := s.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if _, err := s.DownloadMeisai(from.Format("2006-01-02"), to.Format("2006-01-02")); err != nil {
+		t.Fatalf("DownloadMeisai failed: %v", err)
+	}
+	if site.Downloads() != 1 {
+		t.Errorf("Expected 1 CSV download, got %d", site.Downloads())
+	}
+}
+
+func TestHTTPScraper_FakeSite_Errors(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+	site.AddAccount("user", "pass")
+	site.AddAccount("locked", "pass").Locked = true
+	site.AddAccount("rejected", "pass").Rejected = true
+
+	from, to := lastMonth()
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		s := newHTTPScraper(t, site.URL+"/", "user", "wrong")
+		if err := s.Login(); !errors.Is(err, scraper.ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+
+	t.Run("locked account", func(t *testing.T) {
+		s := newHTTPScraper(t, site.URL+"/", "locked", "pass")
+		if err := s.Login(); !errors.Is(err, scraper.ErrAccountLocked) {
+			t.Errorf("Expected ErrAccountLocked, got %v", err)
+		}
+	})
+
+	t.Run("login form without message", func(t *testing.T) {
+		s := newHTTPScraper(t, site.URL+"/", "rejected", "pass")
+		if err := s.Login(); !errors.Is(err, scraper.ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		s := newHTTPScraper(t, site.URL+"/", "user", "pass")
+		if err := s.Login(); err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+		_, err := s.DownloadMeisai(from.Format("2006-01-02"), to.Format("2006-01-02"))
+		if !errors.Is(err, scraper.ErrNoResults) {
+			t.Errorf("Expected ErrNoResults, got %v", err)
+		}
+	})
+
+	t.Run("date not offered by the site", func(t *testing.T) {
+		s := newHTTPScraper(t, site.URL+"/", "user", "pass")
+		if err := s.Login(); err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+		old := time.Now().AddDate(-5, 0, 0).Format("2006-01-02")
+		_, err := s.DownloadMeisai(old, old)
+		var rangeErr *scraper.DateRangeError
+		if !errors.As(err, &rangeErr) {
+			t.Errorf("Expected *scraper.DateRangeError, got %v", err)
+		}
+	})
+
+	t.Run("maintenance", func(t *testing.T) {
+		site.SetMaintenance(true)
+		defer site.SetMaintenance(false)
+
+		s := newHTTPScraper(t, site.URL+"/", "user", "pass")
+		if err := s.Login(); !errors.Is(err, scraper.ErrSiteMaintenance) {
+			t.Errorf("Expected ErrSiteMaintenance, got %v", err)
+		}
+	})
+}
+
+// newShiftJISServer serves the login flow in Shift_JIS like the real site, rejecting the login as locked.
+// With header set the charset is sent in Content-Type, otherwise only in a <meta> tag.
+func newShiftJISServer(t *testing.T, header bool) *httptest.Server {
+	t.Helper()
+	write := func(w http.ResponseWriter, body string) {
+		encoded, err := japanese.ShiftJIS.NewEncoder().String(`<html><head><meta charset="Shift_JIS"></head><body>` + body + `</body></html>`)
+		if err != nil {
+			t.Fatalf("Failed to encode page: %v", err)
+		}
+		if header {
+			w.Header().Set("Content-Type", "text/html; charset=Shift_JIS")
+		} else {
+			w.Header().Set("Content-Type", "text/html")
+		}
+		fmt.Fprint(w, encoded)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			write(w, `<div class="error-message">アカウントがロックされています</div>`)
+		case r.URL.Query().Get("funccode") == "1013000000":
+			write(w, `<form method="post" action="/login"><input name="risLoginId"><input type="password" name="risPassword"></form>`)
+		default:
+			write(w, `<a href="/login?funccode=1013000000">ログイン</a>`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPScraper_ShiftJISPages(t *testing.T) {
+	for name, header := range map[string]bool{"charset in header": true, "charset in meta tag": false} {
+		t.Run(name, func(t *testing.T) {
+			server := newShiftJISServer(t, header)
+			s := newHTTPScraper(t, server.URL+"/", "user", "pass")
+			if err := s.Login(); !errors.Is(err, scraper.ErrAccountLocked) {
+				t.Errorf("Expected ErrAccountLocked from the decoded message, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHTTPScraper_UsesSelectorPack(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+	site.AddAccount("user", "pass")
+
+	tests := []struct {
+		name      string
+		element   string
+		selectors string
+		wantErr   error
+	}{
+		{"playwright-only selector first", scraper.ElementLoginUserID, `["#login-id", "input[name='risLoginId']"]`, nil},
+		{"renamed field", scraper.ElementLoginUserID, `["input[name='loginId']"]`, scraper.ErrUnsupportedSite},
+		{"no field name", scraper.ElementLoginUserID, `["text=ログインID"]`, scraper.ErrUnsupportedSite},
+		{"login link by href", scraper.ElementLoginLink, `["a#login", "a[href*='funccode=1013000000']"]`, nil},
+		{"moved login link", scraper.ElementLoginLink, `["a[href*='funccode=1099000000']"]`, scraper.ErrUnsupportedSite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pack, err := scraper.ParseSelectorPack([]byte(`{"version": "test", "elements": {"` + tt.element + `": ` + tt.selectors + `}}`))
+			if err != nil {
+				t.Fatalf("ParseSelectorPack failed: %v", err)
+			}
+			s, err := scraper.NewHTTPScraper(&scraper.ScraperConfig{
+				UserID:       "user",
+				Password:     "pass",
+				BaseURL:      site.URL + "/",
+				DownloadPath: t.TempDir(),
+				Timeout:      5000,
+				RetryCount:   1,
+				TestMode:     true,
+				Selectors:    pack,
+			}, log.New(os.Stdout, "[TEST] ", log.LstdFlags))
+			if err != nil {
+				t.Fatalf("Failed to create scraper: %v", err)
+			}
+			defer s.Close()
+			if err := s.Initialize(); err != nil {
+				t.Fatalf("Initialize failed: %v", err)
+			}
+			if err := s.Login(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestHTTPScraper_FakeSite_Logout(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+	site.AddAccount("user", "pass")
+
+	s := newHTTPScraper(t, site.URL+"/", "user", "pass")
+	if err := s.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if site.Sessions() != 1 {
+		t.Fatalf("Expected one login session, got %d", site.Sessions())
+	}
+
+	fallback := scraper.NewFallbackScraper(s, func() (scraper.ContextScraper, error) {
+		t.Fatal("Fallback must not be created for logout")
+		return nil, nil
+	}, nil)
+	if err := scraper.Logout(context.Background(), fallback); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+	if site.Sessions() != 0 {
+		t.Errorf("Expected the site session to be closed, got %d", site.Sessions())
+	}
+
+	// Scrapers without logout support are left to Close
+	if err := scraper.Logout(context.Background(), mocks.NewMockETCScraper()); err != nil {
+		t.Errorf("Expected no error for a scraper without logout, got %v", err)
+	}
+}
+
+// newUnsupportedServer serves a script-rendered page without the links the HTTP backend looks for
+func newUnsupportedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><div id="app"></div><script src="/app.js"></script></body></html>`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPScraper_UnsupportedSite(t *testing.T) {
+	server := newUnsupportedServer(t)
+
+	s := newHTTPScraper(t, server.URL+"/", "user", "pass")
+	err := s.Login()
+	if !errors.Is(err, scraper.ErrUnsupportedSite) {
+		t.Fatalf("Expected ErrUnsupportedSite, got %v", err)
+	}
+	if scraper.ClassifyError(err) != scraper.ErrorClassPermanent {
+		t.Error("Unsupported pages must not be retried")
+	}
+}
+
+func TestFallbackScraper_SwitchesOnUnsupportedSite(t *testing.T) {
+	server := newUnsupportedServer(t)
+
+	primary := newHTTPScraper(t, server.URL+"/", "user", "pass")
+	fallback := mocks.NewMockETCScraper()
+	fallback.DownloadResult = "/tmp/fallback.csv"
+
+	created := 0
+	s := scraper.NewFallbackScraper(primary, func() (scraper.ContextScraper, error) {
+		created++
+		return scraper.AsContextScraper(fallback), nil
+	}, nil)
+
+	if err := s.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if created != 1 || !fallback.InitializeCalled || !fallback.LoginCalled {
+		t.Fatalf("Expected the fallback to be created, initialized and logged in (created=%d)", created)
+	}
+
+	path, err := s.DownloadMeisai("2025-01-01", "2025-01-31")
+	if err != nil || path != "/tmp/fallback.csv" {
+		t.Errorf("Expected the fallback download, got %q, %v", path, err)
+	}
+	if created != 1 {
+		t.Errorf("Expected the fallback to be created once, got %d", created)
+	}
+
+	if len(s.Attempts()) == 0 {
+		t.Error("Expected the primary attempts to be kept")
+	}
+
+	s.Close()
+	if !fallback.CloseCalled {
+		t.Error("Expected Close to reach the fallback")
+	}
+}
+
+func TestFallbackScraper_KeepsPrimaryErrors(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+	site.AddAccount("user", "pass")
+
+	primary := newHTTPScraper(t, site.URL+"/", "user", "wrong")
+	s := scraper.NewFallbackScraper(primary, func() (scraper.ContextScraper, error) {
+		t.Fatal("Fallback must not be created for supported pages")
+		return nil, nil
+	}, nil)
+
+	if err := s.Login(); !errors.Is(err, scraper.ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package scraper_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+)
+
+func TestRateLimiter_Wait(t *testing.T) {
+	limiter := scraper.NewRateLimiter(50 * time.Millisecond)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected 3 requests to take at least 100ms, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	var nilLimiter *scraper.RateLimiter
+	for _, limiter := range []*scraper.RateLimiter{nilLimiter, scraper.NewRateLimiter(0)} {
+		start := time.Now()
+		for i := 0; i < 10; i++ {
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Fatalf("Wait failed: %v", err)
+			}
+		}
+		if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+			t.Errorf("Expected no waiting, took %v", elapsed)
+		}
+	}
+}
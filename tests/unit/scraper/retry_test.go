@@ -0,0 +1,276 @@
+package scraper_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected scraper.ErrorClass
+	}{
+		{"playwright timeout", errors.New("Timeout 30000ms exceeded"), scraper.ErrorClassTransient},
+		{"network idle", fmt.Errorf("failed to wait: %w", errors.New("waiting for networkidle")), scraper.ErrorClassTransient},
+		{"connection reset", errors.New("net::ERR_CONNECTION_RESET at https://www.etc-meisai.jp/"), scraper.ErrorClassTransient},
+		{"session expired", fmt.Errorf("search: %w", scraper.ErrSessionExpired), scraper.ErrorClassSessionExpired},
+		{"bad credentials", &scraper.LoginError{Kind: scraper.ErrInvalidCredentials, Message: "Invalid credentials"}, scraper.ErrorClassPermanent},
+		{"no results", fmt.Errorf("%w: timeout text in page", scraper.ErrNoResults), scraper.ErrorClassPermanent},
+		{"download timeout", fmt.Errorf("%w after 1m0s", scraper.ErrDownloadTimeout), scraper.ErrorClassTransient},
+		{"browser crash", fmt.Errorf("%w: Target closed", scraper.ErrBrowserCrash), scraper.ErrorClassPermanent},
+		{"invalid range", &scraper.DateRangeError{Reason: "from date is after to date"}, scraper.ErrorClassPermanent},
+		{"cancelled", fmt.Errorf("download aborted: %w", context.DeadlineExceeded), scraper.ErrorClassPermanent},
+		{"unknown", errors.New("element not found"), scraper.ErrorClassPermanent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scraper.ClassifyError(tt.err); got != tt.expected {
+				t.Errorf("ClassifyError(%v) = %s, expected %s", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := scraper.RetryPolicy{
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+	}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := policy.Backoff(i + 1); got != want {
+			t.Errorf("Backoff(%d) = %v, expected %v", i+1, got, want)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		got := policy.Backoff(1)
+		if got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("Backoff with jitter out of range: %v", got)
+		}
+	}
+}
+
+func TestScraperConfig_RetryDefaults(t *testing.T) {
+	defaults := scraper.DefaultScraperConfig()
+	if defaults.RetryBackoff != scraper.DefaultRetryBackoff || defaults.RetryJitter != scraper.DefaultRetryJitter {
+		t.Errorf("Unexpected retry defaults: backoff %v, jitter %v", defaults.RetryBackoff, defaults.RetryJitter)
+	}
+	if defaults.RetryCount != 3 || defaults.Selectors == nil {
+		t.Errorf("Expected the other defaults to be filled in, got %+v", defaults)
+	}
+
+	// Zero backoff and jitter turn them off instead of falling back to the defaults
+	mockPage := mocks.NewMockPage()
+	gotoCount := 0
+	mockPage.GotoFunc = func(url string, options scraper.PageGotoOptions) (scraper.Response, error) {
+		gotoCount++
+		if gotoCount == 1 {
+			return nil, errors.New("net::ERR_CONNECTION_RESET")
+		}
+		return nil, nil
+	}
+	mockPage.Locators["a:has-text('ログアウト')"] = &mocks.MockLocator{CountValue: 1}
+
+	config := &scraper.ScraperConfig{
+		UserID:       "test",
+		Password:     "pass",
+		DownloadPath: t.TempDir(),
+		RetryCount:   1,
+	}
+	s, err := scraper.NewETCScraperWithFactory(config, log.New(os.Stdout, "[TEST] ", log.LstdFlags), createMockFactory(mockPage))
+	if err != nil {
+		t.Fatalf("Failed to create scraper: %v", err)
+	}
+	if config.RetryBackoff != 0 || config.RetryJitter != 0 {
+		t.Errorf("Expected zero backoff and jitter to be kept, got %v and %v", config.RetryBackoff, config.RetryJitter)
+	}
+	if err := s.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	if err := s.Login(); err != nil {
+		t.Fatalf("Expected login to succeed after retry, got %v", err)
+	}
+
+	attempts := s.Attempts()
+	if len(attempts) < 2 {
+		t.Fatalf("Expected a retried navigation, got %+v", attempts)
+	}
+	if wait := attempts[1].StartedAt.Sub(attempts[0].StartedAt) - attempts[0].Duration; wait > 500*time.Millisecond {
+		t.Errorf("Expected the retry without backoff, waited %v", wait)
+	}
+}
+
+func newRetryTestScraper(t *testing.T, mockPage *mocks.MockPage, retryCount int) *scraper.ETCScraper {
+	t.Helper()
+
+	config := &scraper.ScraperConfig{
+		UserID:          "test",
+		Password:        "pass",
+		DownloadPath:    "./test_downloads",
+		TestMode:        true,
+		RetryCount:      retryCount,
+		DownloadTimeout: 200 * time.Millisecond,
+	}
+	t.Cleanup(func() { os.RemoveAll(config.DownloadPath) })
+
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	s, err := scraper.NewETCScraperWithFactory(config, logger, createMockFactory(mockPage))
+	if err != nil {
+		t.Fatalf("Failed to create scraper: %v", err)
+	}
+	if err := s.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	return s
+}
+
+func TestETCScraper_Login_RetriesTransientNavigation(t *testing.T) {
+	mockPage := mocks.NewMockPage()
+	gotoCount := 0
+	mockPage.GotoFunc = func(url string, options scraper.PageGotoOptions) (scraper.Response, error) {
+		gotoCount++
+		if gotoCount == 1 {
+			return nil, errors.New("net::ERR_CONNECTION_RESET")
+		}
+		return nil, nil
+	}
+	mockPage.Locators["a:has-text('ログアウト')"] = &mocks.MockLocator{CountValue: 1}
+
+	s := newRetryTestScraper(t, mockPage, 3)
+	if err := s.Login(); err != nil {
+		t.Fatalf("Expected login to succeed after retry, got %v", err)
+	}
+
+	attempts := s.Attempts()
+	if len(attempts) != 3 {
+		t.Fatalf("Expected 3 attempts (2 navigation, 1 login), got %d: %+v", len(attempts), attempts)
+	}
+	if attempts[0].Step != scraper.StepNavigation || attempts[0].Class != scraper.ErrorClassTransient {
+		t.Errorf("Unexpected first attempt: %+v", attempts[0])
+	}
+	if attempts[1].Step != scraper.StepNavigation || attempts[1].Number != 2 || attempts[1].Err != nil {
+		t.Errorf("Unexpected second attempt: %+v", attempts[1])
+	}
+	if got := scraper.RetryCount(attempts); got != 1 {
+		t.Errorf("Expected 1 retry, got %d", got)
+	}
+}
+
+func TestETCScraper_Login_DoesNotRetryRejectedCredentials(t *testing.T) {
+	mockPage := mocks.NewMockPage()
+	mockPage.Locators[".error-message, .alert-danger, .error"] = &mocks.MockLocator{
+		CountValue: 1,
+		TextValue:  "Invalid credentials",
+	}
+
+	s := newRetryTestScraper(t, mockPage, 3)
+	err := s.Login()
+	if !errors.Is(err, scraper.ErrInvalidCredentials) {
+		t.Fatalf("Expected ErrInvalidCredentials, got %v", err)
+	}
+	if got := scraper.RetryCount(s.Attempts()); got != 0 {
+		t.Errorf("Expected no retries for rejected credentials, got %d", got)
+	}
+}
+
+func TestETCScraper_Login_GivesUpAfterRetryCount(t *testing.T) {
+	mockPage := mocks.NewMockPage()
+	mockPage.GotoError = errors.New("Timeout 30000ms exceeded")
+
+	s := newRetryTestScraper(t, mockPage, 2)
+	err := s.Login()
+	if err == nil || !contains(err.Error(), "navigation failed after 3 attempts") {
+		t.Fatalf("Expected exhausted retries error, got %v", err)
+	}
+	if got := len(s.Attempts()); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestETCScraper_DownloadMeisai_ReloginOnExpiredSession(t *testing.T) {
+	mockPage := mocks.NewMockPage()
+	loginFormChecks := 0
+	mockPage.Locators["input[name='risLoginId']"] = &mocks.MockLocator{
+		CountFunc: func() (int, error) {
+			loginFormChecks++
+			if loginFormChecks == 1 {
+				return 1, nil // Result page redirected to the login form
+			}
+			return 0, nil
+		},
+	}
+	mockPage.Locators["a:has-text('ログアウト')"] = &mocks.MockLocator{CountValue: 1}
+	mockPage.Locators["a:has-text('明細ＣＳＶ')"] = &mocks.MockLocator{CountValue: 1}
+	mockPage.OnFunc = func(event string, handler interface{}) {
+		if event == "download" {
+			go func() {
+				if downloadHandler, ok := handler.(func(scraper.Download)); ok {
+					downloadHandler(&MockPlaywrightDownload{suggestedName: "meisai.csv"})
+				}
+			}()
+		}
+	}
+
+	s := newRetryTestScraper(t, mockPage, 3)
+	if _, err := s.DownloadMeisai(testRecentFrom(), testRecentTo()); err != nil {
+		t.Fatalf("Expected download to succeed after re-login, got %v", err)
+	}
+
+	var steps []string
+	for _, attempt := range s.Attempts() {
+		steps = append(steps, fmt.Sprintf("%s#%d", attempt.Step, attempt.Number))
+	}
+	expected := []string{"search#1", "navigation#1", "login#1", "search#2", "csv_link#1", "download_save#1"}
+	if fmt.Sprint(steps) != fmt.Sprint(expected) {
+		t.Errorf("Expected attempts %v, got %v", expected, steps)
+	}
+	if s.Attempts()[0].Class != scraper.ErrorClassSessionExpired {
+		t.Errorf("Expected first search attempt to be classified as session_expired, got %s", s.Attempts()[0].Class)
+	}
+}
+
+func TestETCScraper_DownloadMeisai_RetriesDownloadTimeout(t *testing.T) {
+	mockPage := mocks.NewMockPage()
+	csvClicks := 0
+	var downloadHandler func(scraper.Download)
+	mockPage.Locators["a:has-text('明細ＣＳＶ')"] = &mocks.MockLocator{
+		CountValue: 1,
+		ClickFunc: func(options scraper.LocatorClickOptions) error {
+			csvClicks++
+			if csvClicks == 2 && downloadHandler != nil {
+				go downloadHandler(&MockPlaywrightDownload{suggestedName: "meisai.csv"})
+			}
+			return nil
+		},
+	}
+	mockPage.OnFunc = func(event string, handler interface{}) {
+		if h, ok := handler.(func(scraper.Download)); ok && event == "download" {
+			downloadHandler = h
+		}
+	}
+
+	s := newRetryTestScraper(t, mockPage, 3)
+	if _, err := s.DownloadMeisai(testRecentFrom(), testRecentTo()); err != nil {
+		t.Fatalf("Expected download to succeed on second click, got %v", err)
+	}
+	if csvClicks != 2 {
+		t.Errorf("Expected CSV link to be clicked twice, got %d", csvClicks)
+	}
+	if got := scraper.RetryCount(s.Attempts()); got != 1 {
+		t.Errorf("Expected 1 retry, got %d", got)
+	}
+}
@@ -0,0 +1,97 @@
+package scraper_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/internal/fakesite"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+)
+
+// countingSecrets returns fixed passwords and counts the lookups
+type countingSecrets struct {
+	passwords map[string]string
+	lookups   int
+}
+
+func (s *countingSecrets) Password(ctx context.Context, accountID string) (string, error) {
+	s.lookups++
+	password, ok := s.passwords[accountID]
+	if !ok {
+		return "", scraper.ErrSecretNotFound
+	}
+	return password, nil
+}
+
+func TestHTTPScraper_SecretProvider(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+	site.AddAccount("vault-user", "s3cret:pass,word")
+
+	secrets := &countingSecrets{passwords: map[string]string{"vault-user": "s3cret:pass,word"}}
+	var logs bytes.Buffer
+	config := &scraper.ScraperConfig{
+		UserID:       "vault-user",
+		BaseURL:      site.URL + "/",
+		DownloadPath: t.TempDir(),
+		Timeout:      5000,
+		RetryCount:   1,
+		TestMode:     true,
+		Secrets:      secrets,
+	}
+	s, err := scraper.NewHTTPScraper(config, log.New(&logs, "", 0))
+	if err != nil {
+		t.Fatalf("Failed to create scraper: %v", err)
+	}
+	defer s.Close()
+	if err := s.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if secrets.lookups != 0 {
+		t.Errorf("Password must not be looked up before login, got %d lookups", secrets.lookups)
+	}
+
+	if err := s.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if secrets.lookups != 1 {
+		t.Errorf("Expected 1 lookup at login, got %d", secrets.lookups)
+	}
+	if config.Password != "" {
+		t.Errorf("Password must not be copied into the config")
+	}
+	if strings.Contains(logs.String(), "s3cret") {
+		t.Errorf("Password appeared in the logs:\n%s", logs.String())
+	}
+}
+
+func TestHTTPScraper_SecretProviderMissing(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+	site.AddAccount("unknown", "pass")
+
+	config := &scraper.ScraperConfig{
+		UserID:       "unknown",
+		BaseURL:      site.URL + "/",
+		DownloadPath: t.TempDir(),
+		Timeout:      5000,
+		RetryCount:   1,
+		TestMode:     true,
+		Secrets:      &countingSecrets{},
+	}
+	s, err := scraper.NewHTTPScraper(config, log.New(&bytes.Buffer{}, "", 0))
+	if err != nil {
+		t.Fatalf("Failed to create scraper: %v", err)
+	}
+	defer s.Close()
+	if err := s.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if err := s.Login(); !errors.Is(err, scraper.ErrSecretNotFound) {
+		t.Errorf("Expected ErrSecretNotFound, got %v", err)
+	}
+}
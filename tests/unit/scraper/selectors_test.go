@@ -0,0 +1,166 @@
+package scraper_test
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+)
+
+func TestDefaultSelectorPack(t *testing.T) {
+	pack := scraper.DefaultSelectorPack()
+	if pack.Version == "" {
+		t.Error("Expected the built-in pack to have a version")
+	}
+	for _, element := range scraper.Elements {
+		if len(pack.Selectors(element)) == 0 {
+			t.Errorf("Built-in pack has no selectors for %s", element)
+		}
+	}
+	if len(pack.Selectors(scraper.ElementCSVLink)) != 3 {
+		t.Errorf("Expected 3 CSV link fallbacks, got %v", pack.Selectors(scraper.ElementCSVLink))
+	}
+
+	// Every call returns an independent copy
+	pack.Elements[scraper.ElementLoginLink] = []string{"changed"}
+	if scraper.DefaultSelectorPack().Selectors(scraper.ElementLoginLink)[0] == "changed" {
+		t.Error("Modifying a pack must not change the built-in pack")
+	}
+}
+
+func TestParseSelectorPack(t *testing.T) {
+	pack, err := scraper.ParseSelectorPack([]byte(`{
+		"version": "2026.01-test",
+		"elements": {"csv_link": ["a.csv-download", "a:has-text('明細ＣＳＶ')"]}
+	}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pack.Version != "2026.01-test" {
+		t.Errorf("Unexpected version %q", pack.Version)
+	}
+	if got := pack.Selectors(scraper.ElementCSVLink); len(got) != 2 || got[0] != "a.csv-download" {
+		t.Errorf("Expected overridden CSV link selectors, got %v", got)
+	}
+	if got := pack.Selectors(scraper.ElementLoginLink); len(got) != 1 || got[0] != "a[href*='funccode=1013000000']" {
+		t.Errorf("Expected default login link selector, got %v", got)
+	}
+
+	invalid := []struct {
+		name    string
+		data    string
+		message string
+	}{
+		{"not json", `version: 1`, "invalid selector pack"},
+		{"missing version", `{"elements": {}}`, "version is required"},
+		{"unknown element", `{"version": "1", "elements": {"logout_button": ["a"]}}`, `unknown element "logout_button"`},
+		{"empty selectors", `{"version": "1", "elements": {"csv_link": []}}`, `element "csv_link" has no selectors`},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := scraper.ParseSelectorPack([]byte(tt.data)); err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+}
+
+func TestLoadSelectorPack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "selectors.json")
+	os.WriteFile(path, []byte(`{"version": "file-pack", "elements": {}}`), 0644)
+
+	pack, err := scraper.LoadSelectorPack(path)
+	if err != nil || pack.Version != "file-pack" {
+		t.Errorf("Expected pack from file, got %+v, %v", pack, err)
+	}
+
+	if _, err := scraper.LoadSelectorPack(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestValidateSelectors(t *testing.T) {
+	mockPage := mocks.NewMockPage()
+	mockPage.Locators["a[href*='funccode=1013000000']"] = &mocks.MockLocator{CountValue: 1}
+	// Only the last CSV link fallback matches
+	mockPage.Locators["a[onclick*='1032500000']"] = &mocks.MockLocator{CountValue: 2}
+
+	checks := scraper.ValidateSelectors(mockPage, scraper.DefaultSelectorPack())
+	if len(checks) != len(scraper.Elements) {
+		t.Fatalf("Expected a check per element, got %d", len(checks))
+	}
+
+	resolved := map[string]scraper.SelectorCheck{}
+	for _, check := range checks {
+		if check.Resolved {
+			resolved[check.Element] = check
+		}
+	}
+	if len(resolved) != 2 {
+		t.Errorf("Expected 2 resolved elements, got %v", resolved)
+	}
+	if check := resolved[scraper.ElementCSVLink]; check.Selector != "a[onclick*='1032500000']" || check.Count != 2 {
+		t.Errorf("Expected CSV link to resolve through its fallback, got %+v", check)
+	}
+}
+
+func TestETCScraper_UsesConfiguredSelectorPack(t *testing.T) {
+	pack, err := scraper.ParseSelectorPack([]byte(`{
+		"version": "custom",
+		"elements": {
+			"login_link": ["a#login-v2"],
+			"csv_link": ["a.csv-v2", "a.csv-legacy"]
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	mockPage := mocks.NewMockPage()
+	loginClicks := 0
+	mockPage.Locators["a#login-v2"] = &mocks.MockLocator{
+		ClickFunc: func(options scraper.LocatorClickOptions) error {
+			loginClicks++
+			return nil
+		},
+	}
+	mockPage.Locators["a:has-text('ログアウト')"] = &mocks.MockLocator{CountValue: 1}
+	mockPage.Locators["input[name='hakkoMeisai']"] = &mocks.MockLocator{CountValue: 1}
+
+	config := &scraper.ScraperConfig{
+		UserID:       "test",
+		Password:     "pass",
+		DownloadPath: t.TempDir(),
+		TestMode:     true,
+		RetryCount:   -1,
+		Selectors:    pack,
+	}
+	s, err := scraper.NewETCScraperWithFactory(config, log.New(os.Stdout, "[TEST] ", log.LstdFlags), createMockFactory(mockPage))
+	if err != nil {
+		t.Fatalf("Failed to create scraper: %v", err)
+	}
+	if err := s.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	if err := s.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if loginClicks != 1 {
+		t.Errorf("Expected the pack's login link to be clicked once, got %d", loginClicks)
+	}
+
+	_, err = s.DownloadMeisai(testRecentFrom(), testRecentTo())
+	var selectorErr *scraper.SelectorError
+	if !errors.As(err, &selectorErr) {
+		t.Fatalf("Expected *scraper.SelectorError, got %v", err)
+	}
+	if strings.Join(selectorErr.Selectors, ",") != "a.csv-v2,a.csv-legacy" {
+		t.Errorf("Expected the pack's CSV selectors in the error, got %v", selectorErr.Selectors)
+	}
+}
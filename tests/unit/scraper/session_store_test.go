@@ -0,0 +1,257 @@
+package scraper_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+)
+
+var sessionKey = bytes.Repeat([]byte{0x17}, 32)
+
+const savedState = `{"cookies":[{"name":"JSESSIONID","value":"abc123"}],"origins":[]}`
+
+func newTestSessionStore(t *testing.T, ttl time.Duration) *scraper.SessionStore {
+	t.Helper()
+	store, err := scraper.NewSessionStore(t.TempDir(), sessionKey, ttl)
+	if err != nil {
+		t.Fatalf("NewSessionStore failed: %v", err)
+	}
+	return store
+}
+
+func TestSessionStore_SaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	store, err := scraper.NewSessionStore(dir, sessionKey, time.Hour)
+	if err != nil {
+		t.Fatalf("NewSessionStore failed: %v", err)
+	}
+
+	err = store.Save(&scraper.SavedSession{
+		UserID: "corp-user",
+		State:  []byte(savedState),
+		URL:    "https://www.etc-meisai.jp/etc/R?funccode=1032000000",
+	})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != 1 {
+		t.Fatalf("Expected one session file, got %v", files)
+	}
+	raw, _ := os.ReadFile(files[0])
+	if bytes.Contains(raw, []byte("abc123")) || bytes.Contains(raw, []byte("corp-user")) ||
+		bytes.Contains([]byte(filepath.Base(files[0])), []byte("corp-user")) {
+		t.Error("Session file must not reveal the account or its cookies")
+	}
+
+	loaded, err := store.Load("corp-user")
+	if err != nil || loaded == nil {
+		t.Fatalf("Expected saved session, got %v, %v", loaded, err)
+	}
+	if string(loaded.State) != savedState || loaded.URL == "" {
+		t.Errorf("Unexpected session: %+v", loaded)
+	}
+
+	if other, err := store.Load("other-user"); other != nil || err != nil {
+		t.Errorf("Expected no session for other account, got %v, %v", other, err)
+	}
+}
+
+func TestSessionStore_Expired(t *testing.T) {
+	store := newTestSessionStore(t, time.Minute)
+	store.Save(&scraper.SavedSession{
+		UserID:  "corp-user",
+		State:   []byte(savedState),
+		SavedAt: time.Now().Add(-2 * time.Minute),
+	})
+
+	if loaded, err := store.Load("corp-user"); loaded != nil || err != nil {
+		t.Errorf("Expected expired session to be ignored, got %v, %v", loaded, err)
+	}
+}
+
+func TestSessionStore_WrongKey(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := scraper.NewSessionStore(dir, sessionKey, time.Hour)
+	store.Save(&scraper.SavedSession{UserID: "corp-user", State: []byte(savedState)})
+
+	otherStore, _ := scraper.NewSessionStore(dir, bytes.Repeat([]byte{0x99}, 32), time.Hour)
+	if loaded, err := otherStore.Load("corp-user"); loaded != nil || err == nil {
+		t.Errorf("Expected decryption error, got %v, %v", loaded, err)
+	}
+
+	if _, err := scraper.NewSessionStore(dir, []byte("short"), time.Hour); err == nil {
+		t.Error("Expected error for short key")
+	}
+}
+
+// newSessionTestScraper builds a scraper whose browser context reports state and records the state it was created with
+func newSessionTestScraper(t *testing.T, mockPage *mocks.MockPage, store *scraper.SessionStore, restored *[]byte) *scraper.ETCScraper {
+	t.Helper()
+
+	mockContext := &mocks.MockBrowserContext{
+		NewPageFunc: func() (scraper.PageInterface, error) { return mockPage, nil },
+		State:       []byte(savedState),
+	}
+	factory := &mocks.MockPlaywrightFactory{
+		RunFunc: func() (scraper.PlaywrightInterface, error) {
+			return &mocks.MockPlaywright{Chromium: &mocks.MockBrowserType{
+				LaunchFunc: func(options scraper.BrowserTypeLaunchOptions) (scraper.BrowserInterface, error) {
+					return &mocks.MockBrowser{
+						NewContextFunc: func(options scraper.BrowserNewContextOptions) (scraper.BrowserContextInterface, error) {
+							*restored = options.StorageState
+							return mockContext, nil
+						},
+					}, nil
+				},
+			}}, nil
+		},
+	}
+
+	config := &scraper.ScraperConfig{
+		UserID:       "corp-user",
+		Password:     "pass",
+		DownloadPath: t.TempDir(),
+		TestMode:     true,
+		Sessions:     store,
+	}
+	s, err := scraper.NewETCScraperWithFactory(config, log.New(os.Stdout, "[TEST] ", log.LstdFlags), factory)
+	if err != nil {
+		t.Fatalf("Failed to create scraper: %v", err)
+	}
+	if err := s.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	return s
+}
+
+func TestETCScraper_Login_ReusesSavedSession(t *testing.T) {
+	store := newTestSessionStore(t, time.Hour)
+	resumeURL := "https://www.etc-meisai.jp/etc/R?funccode=1032000000"
+	store.Save(&scraper.SavedSession{UserID: "corp-user", State: []byte(savedState), URL: resumeURL})
+
+	mockPage := mocks.NewMockPage()
+	mockPage.Locators["a:has-text('ログアウト')"] = &mocks.MockLocator{CountValue: 1}
+	loginClicks := 0
+	mockPage.Locators["a[href*='funccode=1013000000']"] = &mocks.MockLocator{
+		ClickFunc: func(options scraper.LocatorClickOptions) error {
+			loginClicks++
+			return nil
+		},
+	}
+
+	var restored []byte
+	s := newSessionTestScraper(t, mockPage, store, &restored)
+	if string(restored) != savedState {
+		t.Errorf("Expected saved state to be restored into the context, got %q", restored)
+	}
+
+	if err := s.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if loginClicks != 0 {
+		t.Error("Expected the login form to be skipped")
+	}
+	if mockPage.URL() != resumeURL {
+		t.Errorf("Expected the saved page to be reopened, got %s", mockPage.URL())
+	}
+}
+
+func TestETCScraper_Login_FallsBackWhenSavedSessionInvalid(t *testing.T) {
+	store := newTestSessionStore(t, time.Hour)
+	store.Save(&scraper.SavedSession{
+		UserID: "corp-user",
+		State:  []byte(`{"cookies":[],"origins":[]}`),
+		URL:    "https://www.etc-meisai.jp/etc/R?funccode=1032000000",
+	})
+
+	mockPage := mocks.NewMockPage()
+	loginClicks := 0
+	mockPage.Locators["a[href*='funccode=1013000000']"] = &mocks.MockLocator{
+		ClickFunc: func(options scraper.LocatorClickOptions) error {
+			loginClicks++
+			return nil
+		},
+	}
+	// The saved page shows the login form; only the full login reaches the logged-in menu
+	mockPage.Locators["a:has-text('ログアウト')"] = &mocks.MockLocator{
+		CountFunc: func() (int, error) { return loginClicks, nil },
+	}
+
+	var restored []byte
+	s := newSessionTestScraper(t, mockPage, store, &restored)
+	if err := s.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if loginClicks != 1 {
+		t.Errorf("Expected one full login, got %d", loginClicks)
+	}
+
+	// The fresh login replaces the stale session
+	saved, err := store.Load("corp-user")
+	if err != nil || saved == nil || string(saved.State) != savedState {
+		t.Errorf("Expected the new session to be saved, got %+v, %v", saved, err)
+	}
+}
+
+func TestETCScraper_Login_SavesSessionAfterLogin(t *testing.T) {
+	store := newTestSessionStore(t, time.Hour)
+
+	mockPage := mocks.NewMockPage()
+	mockPage.Locators["a:has-text('ログアウト')"] = &mocks.MockLocator{CountValue: 1}
+
+	var restored []byte
+	s := newSessionTestScraper(t, mockPage, store, &restored)
+	if restored != nil {
+		t.Errorf("Expected no state to restore, got %q", restored)
+	}
+	if err := s.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	saved, err := store.Load("corp-user")
+	if err != nil || saved == nil {
+		t.Fatalf("Expected session to be saved, got %v", err)
+	}
+	if saved.URL != mockPage.URL() {
+		t.Errorf("Expected saved URL %s, got %s", mockPage.URL(), saved.URL)
+	}
+}
+
+func TestETCScraper_Logout_ForgetsSavedSession(t *testing.T) {
+	store := newTestSessionStore(t, time.Hour)
+
+	mockPage := mocks.NewMockPage()
+	logoutClicks := 0
+	mockPage.Locators["a:has-text('ログアウト')"] = &mocks.MockLocator{
+		CountValue: 1,
+		ClickFunc: func(options scraper.LocatorClickOptions) error {
+			logoutClicks++
+			return nil
+		},
+	}
+
+	var restored []byte
+	s := newSessionTestScraper(t, mockPage, store, &restored)
+	if err := s.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if err := scraper.Logout(context.Background(), s); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+
+	if logoutClicks != 1 {
+		t.Errorf("Expected the logout link to be clicked once, got %d", logoutClicks)
+	}
+	if saved, err := store.Load("corp-user"); err != nil || saved != nil {
+		t.Errorf("Expected the saved session to be deleted, got %+v, %v", saved, err)
+	}
+}
@@ -0,0 +1,242 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/models"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+)
+
+const testAccountFile = `
+accounts:
+  - id: corp1
+    password: "p@ss:word,1"
+    type: corporate
+    display_name: 本社
+    tags: [tokyo, main]
+    download_dir: %s
+    scraper:
+      timeout: 45s
+      headless: false
+  - id: personal1
+    password: secret
+    type: personal
+  - id: retired
+    password: old
+    type: corporate
+    enabled: false
+`
+
+// writeAccountFile writes the test account file with corp1 downloading to downloadDir
+func writeAccountFile(t *testing.T, downloadDir string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "accounts.yaml")
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(testAccountFile, downloadDir)), 0600); err != nil {
+		t.Fatalf("Failed to write account file: %v", err)
+	}
+	return path
+}
+
+func TestParseAccountFile(t *testing.T) {
+	registry, err := services.ParseAccountFile([]byte(fmt.Sprintf(testAccountFile, "./downloads/corp1")))
+	if err != nil {
+		t.Fatalf("ParseAccountFile() error: %v", err)
+	}
+
+	if ids := fmt.Sprint(registry.AccountIDs()); ids != "[corp1 personal1]" {
+		t.Errorf("Expected only the enabled accounts, got %s", ids)
+	}
+	if password, err := registry.Password("corp1"); err != nil || password != "p@ss:word,1" {
+		t.Errorf("Password(corp1) = %q, %v", password, err)
+	}
+
+	corp, err := registry.Account("corp1")
+	if err != nil {
+		t.Fatalf("Account(corp1) error: %v", err)
+	}
+	if corp.Type != models.AccountTypeCorporate || corp.DisplayName != "本社" || fmt.Sprint(corp.Tags) != "[tokyo main]" ||
+		!corp.Enabled || corp.DownloadDir != "./downloads/corp1" || corp.Password != "" {
+		t.Errorf("Unexpected account %+v", corp)
+	}
+	if corp.Scraper.Timeout != 45*time.Second || corp.Scraper.Headless == nil || *corp.Scraper.Headless {
+		t.Errorf("Unexpected scraper overrides %+v", corp.Scraper)
+	}
+
+	personal, _ := registry.Account("personal1")
+	if personal.Type != models.AccountTypePersonal || personal.DisplayName != "personal1" || !personal.Enabled || personal.Scraper.Headless != nil {
+		t.Errorf("Unexpected defaults %+v", personal)
+	}
+	if retired, _ := registry.Account("retired"); retired.Enabled {
+		t.Errorf("Expected retired to be disabled")
+	}
+	if _, err := registry.Account("missing"); !errors.Is(err, services.ErrUnknownAccount) {
+		t.Errorf("Expected ErrUnknownAccount, got %v", err)
+	}
+}
+
+func TestParseAccountFile_JSON(t *testing.T) {
+	registry, err := services.ParseAccountFile([]byte(`{"accounts": [
+		{"id": "corp1", "password": "pass", "type": "corporate", "scraper": {"headless": true}}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseAccountFile() error: %v", err)
+	}
+	account, err := registry.Account("corp1")
+	if err != nil || account.Scraper.Headless == nil || !*account.Scraper.Headless {
+		t.Errorf("Unexpected account %+v (%v)", account, err)
+	}
+}
+
+func TestFileAccountRegistry_KeepsFormat(t *testing.T) {
+	files := map[string]string{
+		"accounts.yaml": fmt.Sprintf(testAccountFile, "./downloads/corp1"),
+		"accounts.json": `{"accounts": [
+			{"id": "corp1", "password": "p@ss:word,1", "type": "corporate", "scraper": {"timeout": "45s", "headless": false}},
+			{"id": "personal1", "password": "secret", "type": "personal"}
+		]}`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatalf("Failed to write account file: %v", err)
+			}
+			registry, err := services.LoadAccountFile(path)
+			if err != nil {
+				t.Fatalf("LoadAccountFile() error: %v", err)
+			}
+			if err := registry.AddAccount(models.ETCAccount{ID: "new1", Type: models.AccountTypePersonal, Tags: []string{"osaka"}, Enabled: true}); err != nil {
+				t.Fatalf("AddAccount() error: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read account file: %v", err)
+			}
+			if isJSON := strings.HasPrefix(string(data), "{"); isJSON != (filepath.Ext(name) == ".json") || (isJSON && !json.Valid(data)) {
+				t.Fatalf("Expected %s to be written back in its format, got:\n%s", name, data)
+			}
+
+			// Everything survives the round trip
+			reloaded, err := services.LoadAccountFile(path)
+			if err != nil {
+				t.Fatalf("Reloading %s failed: %v", name, err)
+			}
+			if ids := fmt.Sprint(reloaded.AccountIDs()); !strings.HasPrefix(ids, "[corp1 personal1") || !strings.HasSuffix(ids, "new1]") {
+				t.Errorf("Unexpected accounts after reload: %s", ids)
+			}
+			corp1, _ := reloaded.Account("corp1")
+			if password, err := reloaded.Password("corp1"); err != nil || password != "p@ss:word,1" ||
+				corp1.Scraper.Timeout != 45*time.Second || corp1.Scraper.Headless == nil || *corp1.Scraper.Headless {
+				t.Errorf("Unexpected corp1 after reload: %+v, %q, %v", corp1, password, err)
+			}
+			if added, err := reloaded.Account("new1"); err != nil || added.Type != models.AccountTypePersonal || fmt.Sprint(added.Tags) != "[osaka]" {
+				t.Errorf("Unexpected new1 after reload: %+v, %v", added, err)
+			}
+		})
+	}
+}
+
+func TestParseAccountFile_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"missing id", "accounts:\n  - type: corporate\n", "id is required"},
+		{"id with separator", "accounts:\n  - id: a:b\n    type: corporate\n", "must not contain"},
+		{"unknown type", "accounts:\n  - id: a\n    type: company\n", "type must be"},
+		{"duplicate", "accounts:\n  - id: a\n    type: corporate\n  - id: a\n    type: personal\n", "duplicate account"},
+		{"bad timeout", "accounts:\n  - id: a\n    type: corporate\n    scraper:\n      timeout: 30\n", "invalid scraper timeout"},
+		{"unknown field", "accounts:\n  - id: a\n    type: corporate\n    passwd: x\n", "passwd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := services.ParseAccountFile([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected an error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestDownloadService_AccountFile(t *testing.T) {
+	downloadDir := filepath.Join(t.TempDir(), "corp1")
+	t.Setenv("ETC_ACCOUNTS_FILE", writeAccountFile(t, downloadDir))
+	t.Setenv("ETC_CORPORATE_ACCOUNTS", "envonly:pass")
+	t.Setenv("ETC_HEADLESS", "true")
+
+	var mu sync.Mutex
+	configs := map[string]scraper.ScraperConfig{}
+	passwords := map[string]string{}
+	csvFactory := newCSVScraperFactory(t)
+	factory := &MockScraperFactory{
+		CreateFunc: func(config *scraper.ScraperConfig, logger *log.Logger) (scraper.ScraperInterface, error) {
+			mu.Lock()
+			configs[config.UserID] = *config
+			passwords[config.UserID] = loginPassword(t, config)
+			mu.Unlock()
+			return csvFactory.CreateScraper(config, logger)
+		},
+	}
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceWithFactory(nil, logger, factory)
+	defer service.Shutdown(context.Background())
+
+	if ids := fmt.Sprint(service.GetAllAccountIDs()); ids != "[corp1 personal1]" {
+		t.Fatalf("Expected the accounts of the file, got %s", ids)
+	}
+	if _, err := service.ResolveAccounts([]string{"retired"}); !errors.Is(err, services.ErrAccountDisabled) {
+		t.Errorf("Expected ErrAccountDisabled, got %v", err)
+	}
+	if code := services.ErrorCode(&services.AccountError{AccountID: "retired", Err: services.ErrAccountDisabled}); code != "ACCOUNT_DISABLED" {
+		t.Errorf("Unexpected error code %s", code)
+	}
+
+	accounts, err := service.ResolveAccounts(nil)
+	if err != nil {
+		t.Fatalf("ResolveAccounts() error: %v", err)
+	}
+	service.ProcessAsync("file-job", accounts, testFromDate, testToDate)
+	waitForJobStatus(t, service, "file-job", "completed")
+
+	mu.Lock()
+	defer mu.Unlock()
+	corp := configs["corp1"]
+	if passwords["corp1"] != "p@ss:word,1" || corp.DownloadPath != downloadDir || filepath.Dir(corp.SessionFolder) != downloadDir {
+		t.Errorf("Expected the account's password and download folder, got %+v", corp)
+	}
+	if corp.Timeout != 45000 || corp.Headless || corp.Browsers != nil {
+		t.Errorf("Expected the scraper overrides of corp1, got %+v", corp)
+	}
+	personal := configs["personal1"]
+	if passwords["personal1"] != "secret" || personal.DownloadPath != "./downloads" || personal.Timeout != 30000 || !personal.Headless {
+		t.Errorf("Expected the default settings for personal1, got %+v", personal)
+	}
+}
+
+func TestDownloadService_AccountFileFallback(t *testing.T) {
+	t.Setenv("ETC_ACCOUNTS_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+	t.Setenv("ETC_CORPORATE_ACCOUNTS", "corp1:pass1")
+	t.Setenv("ETC_PERSONAL_ACCOUNTS", "")
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceWithFactory(nil, logger, mocks.NewMockScraperFactory())
+	defer service.Shutdown(context.Background())
+
+	if ids := fmt.Sprint(service.GetAllAccountIDs()); ids != "[corp1]" {
+		t.Errorf("Expected the environment accounts, got %s", ids)
+	}
+}
@@ -0,0 +1,173 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/models"
+	pb "github.com/yhonda-ohishi/etc_meisai_scraper/src/pb"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// loginPassword returns the password the scraper would submit for config at login.
+// Accounts must get it from config.Secrets, never in clear in config.Password.
+func loginPassword(t *testing.T, config *scraper.ScraperConfig) string {
+	t.Helper()
+	if config.Secrets == nil {
+		t.Fatalf("Expected a secret provider in the config of %s", config.UserID)
+	}
+	if config.Password != "" {
+		t.Errorf("Expected no password in the config of %s", config.UserID)
+	}
+	password, err := config.Secrets.Password(context.Background(), config.UserID)
+	if err != nil {
+		t.Errorf("Password lookup for %s failed: %v", config.UserID, err)
+	}
+	return password
+}
+
+// registerAccounts registers accountIDs as corporate accounts with the password "pass",
+// so that downloads can refer to them by ID.
+func registerAccounts(t *testing.T, accountIDs ...string) {
+	t.Helper()
+	entries := make([]string, len(accountIDs))
+	for i, accountID := range accountIDs {
+		entries[i] = accountID + ":pass"
+	}
+	t.Setenv("ETC_ACCOUNTS_FILE", "")
+	t.Setenv("ETC_CORPORATE_ACCOUNTS", strings.Join(entries, ","))
+	t.Setenv("ETC_PERSONAL_ACCOUNTS", "")
+}
+
+func TestEnvAccountRegistry(t *testing.T) {
+	t.Setenv("ETC_CORPORATE_ACCOUNTS", "corp1:pass1, corp2:pa:ss2")
+	t.Setenv("ETC_PERSONAL_ACCOUNTS", "personal1:pass3,nopass")
+	registry := services.NewEnvAccountRegistry()
+
+	if ids := fmt.Sprint(registry.AccountIDs()); ids != "[corp1 corp2 personal1 nopass]" {
+		t.Errorf("Unexpected account IDs %s", ids)
+	}
+
+	for accountID, expected := range map[string]string{"corp1": "pass1", "corp2": "pa:ss2", "personal1": "pass3"} {
+		if password, err := registry.Password(accountID); err != nil || password != expected {
+			t.Errorf("Password(%s) = %q, %v", accountID, password, err)
+		}
+	}
+	if _, err := registry.Password("nopass"); !errors.Is(err, services.ErrInvalidAccountFormat) {
+		t.Errorf("Expected ErrInvalidAccountFormat, got %v", err)
+	}
+	if _, err := registry.Password("missing"); !errors.Is(err, services.ErrUnknownAccount) {
+		t.Errorf("Expected ErrUnknownAccount, got %v", err)
+	}
+
+	for accountID, expected := range map[string]string{"corp2": models.AccountTypeCorporate, "personal1": models.AccountTypePersonal} {
+		account, err := registry.Account(accountID)
+		if err != nil || account.Type != expected || !account.Enabled || account.Password != "" {
+			t.Errorf("Account(%s) = %+v, %v", accountID, account, err)
+		}
+	}
+}
+
+func TestDownloadService_ResolveAccounts(t *testing.T) {
+	t.Setenv("ETC_CORPORATE_ACCOUNTS", "corp1:pass1")
+	t.Setenv("ETC_PERSONAL_ACCOUNTS", "personal1:pass2")
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceWithFactory(nil, logger, mocks.NewMockScraperFactory())
+	defer service.Shutdown(context.Background())
+
+	tests := []struct {
+		name     string
+		accounts []string
+		expected string
+	}{
+		{"all accounts", nil, "[corp1 personal1]"},
+		{"by ID", []string{"personal1"}, "[personal1]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, err := service.ResolveAccounts(tt.accounts)
+			if err != nil {
+				t.Fatalf("ResolveAccounts() error: %v", err)
+			}
+			if got := fmt.Sprint(resolved); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	_, err := service.ResolveAccounts([]string{"corp1", "missing"})
+	var accountErr *services.AccountError
+	if !errors.Is(err, services.ErrUnknownAccount) || !errors.As(err, &accountErr) || accountErr.AccountID != "missing" {
+		t.Errorf("Expected an unknown account error for missing, got %v", err)
+	}
+	// Clients never send passwords: accountID:password entries are rejected without echoing the password
+	for _, account := range []string{"corp1:pass1", "other:secret", "corp1:"} {
+		_, err := service.ResolveAccounts([]string{"personal1", account})
+		if !errors.Is(err, services.ErrInvalidAccountFormat) || !errors.As(err, &accountErr) {
+			t.Errorf("%q: expected an invalid account format error, got %v", account, err)
+			continue
+		}
+		if strings.Contains(accountErr.AccountID, ":") || strings.Contains(err.Error(), "secret") {
+			t.Errorf("%q: error must not include the password, got %v", account, err)
+		}
+	}
+
+	t.Setenv("ETC_CORPORATE_ACCOUNTS", "")
+	t.Setenv("ETC_PERSONAL_ACCOUNTS", "")
+	if _, err := service.ResolveAccounts(nil); !errors.Is(err, services.ErrNoAccountsConfigured) {
+		t.Errorf("Expected ErrNoAccountsConfigured, got %v", err)
+	}
+}
+
+func TestDownloadServiceGRPC_DownloadAsync_AllAccounts(t *testing.T) {
+	t.Setenv("ETC_CORPORATE_ACCOUNTS", "corp1:pass1")
+	t.Setenv("ETC_PERSONAL_ACCOUNTS", "personal1:pass2")
+
+	var mu sync.Mutex
+	passwords := map[string]string{}
+	csvFactory := newCSVScraperFactory(t)
+	factory := &MockScraperFactory{
+		CreateFunc: func(config *scraper.ScraperConfig, logger *log.Logger) (scraper.ScraperInterface, error) {
+			mu.Lock()
+			passwords[config.UserID] = loginPassword(t, config)
+			mu.Unlock()
+			return csvFactory.CreateScraper(config, logger)
+		},
+	}
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	downloadService := services.NewDownloadServiceWithFactory(nil, logger, factory)
+	defer downloadService.Shutdown(context.Background())
+	service := services.NewDownloadServiceGRPCWithService(downloadService)
+
+	resp, err := service.DownloadAsync(context.Background(), &pb.DownloadRequest{FromDate: testFromDate, ToDate: testToDate})
+	if err != nil {
+		t.Fatalf("DownloadAsync failed: %v", err)
+	}
+	job := waitForJobStatus(t, downloadService, resp.JobId, "completed")
+	if len(job.Accounts) != 2 || job.Accounts[0].AccountID != "corp1" || job.Accounts[1].AccountID != "personal1" {
+		t.Errorf("Expected both configured accounts, got %+v", job.Accounts)
+	}
+	mu.Lock()
+	if passwords["corp1"] != "pass1" || passwords["personal1"] != "pass2" {
+		t.Errorf("Expected the configured passwords to reach the scraper, got %v", passwords)
+	}
+	mu.Unlock()
+
+	_, err = service.DownloadSync(context.Background(), &pb.DownloadRequest{Accounts: []string{"corp1", "missing"}})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected NotFound, got %v", err)
+	}
+	if info := errorInfo(t, err); info.Reason != "UNKNOWN_ACCOUNT" || info.Metadata["account_id"] != "missing" {
+		t.Errorf("Unexpected error info %+v", info)
+	}
+}
@@ -0,0 +1,373 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/internal/fakesite"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/credentials"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/models"
+	pb "github.com/yhonda-ohishi/etc_meisai_scraper/src/pb"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// newAccountFileService returns a service on the test account file and the path of that file.
+// Downloads go under a temporary ETC_DOWNLOAD_ROOT, with corp1 in its corp1 folder.
+func newAccountFileService(t *testing.T, factory services.ScraperFactory) (*services.DownloadService, string) {
+	t.Helper()
+	root := t.TempDir()
+	t.Setenv("ETC_DOWNLOAD_ROOT", root)
+	path := writeAccountFile(t, filepath.Join(root, "corp1"))
+	t.Setenv("ETC_ACCOUNTS_FILE", path)
+	t.Setenv("ETC_VAULT_PATH", "")
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceWithFactory(nil, logger, factory)
+	t.Cleanup(func() { service.Shutdown(context.Background()) })
+	return service, path
+}
+
+func TestDownloadService_ManageAccounts(t *testing.T) {
+	service, path := newAccountFileService(t, mocks.NewMockScraperFactory())
+
+	added, err := service.AddAccount(models.ETCAccount{ID: "new1", Type: models.AccountTypePersonal, Tags: []string{"osaka"}, Enabled: true}, "")
+	if err != nil {
+		t.Fatalf("AddAccount() error: %v", err)
+	}
+	if added.DisplayName != "new1" || !added.Enabled || added.Password != "" {
+		t.Errorf("Unexpected added account %+v", added)
+	}
+	if ids := fmt.Sprint(service.GetAllAccountIDs()); ids != "[corp1 personal1 new1]" {
+		t.Errorf("Expected the new account to be downloaded, got %s", ids)
+	}
+
+	timeout := 20 * time.Second
+	added.DisplayName = "大阪"
+	added.Scraper.Timeout = timeout
+	added.DownloadDir = filepath.Join(os.Getenv("ETC_DOWNLOAD_ROOT"), "osaka")
+	if _, err := service.UpdateAccount(added, ""); err != nil {
+		t.Fatalf("UpdateAccount() error: %v", err)
+	}
+	if _, err := service.DisableAccount("corp1"); err != nil {
+		t.Fatalf("DisableAccount() error: %v", err)
+	}
+
+	// Changes are written back to the account file, keeping the passwords
+	reloaded, err := services.LoadAccountFile(path)
+	if err != nil {
+		t.Fatalf("LoadAccountFile() error: %v", err)
+	}
+	if ids := fmt.Sprint(reloaded.AccountIDs()); ids != "[personal1 new1]" {
+		t.Errorf("Expected corp1 to be disabled in the file, got %s", ids)
+	}
+	account, _ := reloaded.Account("new1")
+	if account.DisplayName != "大阪" || account.Scraper.Timeout != timeout || fmt.Sprint(account.Tags) != "[osaka]" || filepath.Base(account.DownloadDir) != "osaka" {
+		t.Errorf("Unexpected saved account %+v", account)
+	}
+	if password, err := reloaded.Password("corp1"); err != nil || password != "p@ss:word,1" {
+		t.Errorf("Expected the password of corp1 to be kept, got %q, %v", password, err)
+	}
+	if _, err := reloaded.Password("new1"); !errors.Is(err, services.ErrInvalidAccountFormat) {
+		t.Errorf("Expected no password for new1, got %v", err)
+	}
+	if len(service.ListAccounts()) != 4 {
+		t.Errorf("Expected disabled accounts to be listed, got %+v", service.ListAccounts())
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{"duplicate", second(service.AddAccount(models.ETCAccount{ID: "corp1", Type: models.AccountTypeCorporate}, "x")), services.ErrAccountExists},
+		{"unknown type", second(service.AddAccount(models.ETCAccount{ID: "bad", Type: "company"}, "x")), services.ErrInvalidAccount},
+		{"unknown account", second(service.UpdateAccount(models.ETCAccount{ID: "missing", Type: models.AccountTypeCorporate}, "")), services.ErrUnknownAccount},
+		{"disable unknown account", second(service.DisableAccount("missing")), services.ErrUnknownAccount},
+		{"password without vault", second(service.AddAccount(models.ETCAccount{ID: "new2", Type: models.AccountTypeCorporate}, "x")), services.ErrVaultRequired},
+		{"download dir outside root", second(service.AddAccount(models.ETCAccount{ID: "new3", Type: models.AccountTypeCorporate, DownloadDir: "/tmp"}, "")), services.ErrInvalidAccount},
+		{"download dir escaping root", second(service.UpdateAccount(models.ETCAccount{ID: "new1", Type: models.AccountTypeCorporate,
+			DownloadDir: filepath.Join(os.Getenv("ETC_DOWNLOAD_ROOT"), "..", "escape")}, "")), services.ErrInvalidAccount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, tt.err)
+			}
+		})
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "new2") || strings.Contains(string(data), "escape") {
+		t.Errorf("Expected rejected changes not to be saved:\n%s", data)
+	}
+}
+
+// second returns the error of a call returning a value and an error
+func second[T any](_ T, err error) error {
+	return err
+}
+
+func TestDownloadService_AddAccountToVault(t *testing.T) {
+	service, path := newAccountFileService(t, mocks.NewMockScraperFactory())
+	vault, err := credentials.Open(filepath.Join(t.TempDir(), "vault.json"), bytes.Repeat([]byte{0x31}, 32))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	service.SetSecretProvider(vault)
+
+	if _, err := service.AddAccount(models.ETCAccount{ID: "new1", Type: models.AccountTypeCorporate}, "vaulted"); err != nil {
+		t.Fatalf("AddAccount() error: %v", err)
+	}
+	// Existing accounts get their password rotated in the vault
+	account, _ := service.GetAccount("corp1")
+	if _, err := service.UpdateAccount(account, "first"); err != nil {
+		t.Fatalf("UpdateAccount() error: %v", err)
+	}
+	if _, err := service.UpdateAccount(account, "second"); err != nil {
+		t.Fatalf("UpdateAccount() error: %v", err)
+	}
+
+	for accountID, expected := range map[string]string{"new1": "vaulted", "corp1": "second"} {
+		if password, err := vault.Password(context.Background(), accountID); err != nil || password != expected {
+			t.Errorf("vault password of %s = %q, %v", accountID, password, err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "vaulted") || strings.Contains(string(data), "second") {
+		t.Errorf("Expected no vault password in the account file:\n%s", data)
+	}
+}
+
+func TestDownloadService_AccountSaveFailureKeepsVault(t *testing.T) {
+	service, path := newAccountFileService(t, mocks.NewMockScraperFactory())
+	vault, err := credentials.Open(filepath.Join(t.TempDir(), "vault.json"), bytes.Repeat([]byte{0x31}, 32))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	service.SetSecretProvider(vault)
+	account, _ := service.GetAccount("corp1")
+	if _, err := service.UpdateAccount(account, "old"); err != nil {
+		t.Fatalf("UpdateAccount() error: %v", err)
+	}
+
+	// A directory in place of the account file makes every save fail
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "blocked"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.AddAccount(models.ETCAccount{ID: "new1", Type: models.AccountTypeCorporate}, "added"); err == nil {
+		t.Fatal("Expected AddAccount to fail")
+	}
+	if _, err := vault.Password(context.Background(), "new1"); !errors.Is(err, scraper.ErrSecretNotFound) {
+		t.Errorf("Expected the password of new1 to be removed from the vault, got %v", err)
+	}
+
+	if _, err := service.UpdateAccount(account, "new"); err == nil {
+		t.Fatal("Expected UpdateAccount to fail")
+	}
+	if password, err := vault.Password(context.Background(), "corp1"); err != nil || password != "old" {
+		t.Errorf("Expected the old password of corp1 to be kept, got %q, %v", password, err)
+	}
+}
+
+func TestAccountServiceGRPC_Manage(t *testing.T) {
+	downloadService, _ := newAccountFileService(t, mocks.NewMockScraperFactory())
+	service := services.NewAccountServiceGRPC(downloadService)
+	ctx := context.Background()
+
+	added, err := service.AddAccount(ctx, &pb.AddAccountRequest{
+		Account: &pb.Account{AccountId: "new1", Type: "corporate", Timeout: durationpb.New(time.Minute)},
+	})
+	if err != nil {
+		t.Fatalf("AddAccount failed: %v", err)
+	}
+	if !added.GetEnabled() || added.DisplayName != "new1" || added.Timeout.AsDuration() != time.Minute {
+		t.Errorf("Unexpected added account %+v", added)
+	}
+
+	updated, err := service.UpdateAccount(ctx, &pb.UpdateAccountRequest{
+		Account:    &pb.Account{AccountId: "new1", DisplayName: "新規", Type: "personal"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"display_name"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateAccount failed: %v", err)
+	}
+	if updated.DisplayName != "新規" || updated.Type != "corporate" || updated.Timeout.AsDuration() != time.Minute {
+		t.Errorf("Expected only the display name to change, got %+v", updated)
+	}
+
+	disabled, err := service.DisableAccount(ctx, &pb.DisableAccountRequest{AccountId: "new1"})
+	if err != nil || disabled.GetEnabled() {
+		t.Fatalf("Expected new1 to be disabled, got %+v, %v", disabled, err)
+	}
+
+	list, err := service.ListAccounts(ctx, &pb.ListAccountsRequest{})
+	if err != nil {
+		t.Fatalf("ListAccounts failed: %v", err)
+	}
+	var ids []string
+	for _, account := range list.Accounts {
+		ids = append(ids, account.AccountId)
+	}
+	if fmt.Sprint(ids) != "[corp1 personal1 retired new1]" {
+		t.Errorf("Unexpected accounts %v", ids)
+	}
+	corp := list.Accounts[0]
+	if corp.DisplayName != "本社" || fmt.Sprint(corp.Tags) != "[tokyo main]" || corp.Timeout.AsDuration() != 45*time.Second || corp.Headless == nil || *corp.Headless {
+		t.Errorf("Unexpected metadata of corp1 %+v", corp)
+	}
+	if strings.Contains(fmt.Sprint(list), "p@ss") || strings.Contains(fmt.Sprint(list), "secret") {
+		t.Errorf("Expected no passwords in the response, got %v", list)
+	}
+
+	tests := []struct {
+		name   string
+		call   func() error
+		code   codes.Code
+		reason string
+	}{
+		{"duplicate", func() error {
+			_, err := service.AddAccount(ctx, &pb.AddAccountRequest{Account: &pb.Account{AccountId: "corp1", Type: "corporate"}})
+			return err
+		}, codes.AlreadyExists, "ACCOUNT_EXISTS"},
+		{"invalid id", func() error {
+			_, err := service.AddAccount(ctx, &pb.AddAccountRequest{Account: &pb.Account{AccountId: "a:b", Type: "corporate"}})
+			return err
+		}, codes.InvalidArgument, "INVALID_ACCOUNT"},
+		{"unknown mask path", func() error {
+			_, err := service.UpdateAccount(ctx, &pb.UpdateAccountRequest{
+				Account:    &pb.Account{AccountId: "corp1"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"password"}},
+			})
+			return err
+		}, codes.InvalidArgument, "INVALID_ACCOUNT"},
+		{"unknown account", func() error {
+			_, err := service.DisableAccount(ctx, &pb.DisableAccountRequest{AccountId: "missing"})
+			return err
+		}, codes.NotFound, "UNKNOWN_ACCOUNT"},
+		{"password without vault", func() error {
+			_, err := service.AddAccount(ctx, &pb.AddAccountRequest{Account: &pb.Account{AccountId: "new2", Type: "corporate"}, Password: "secret"})
+			return err
+		}, codes.FailedPrecondition, "CREDENTIAL_VAULT_REQUIRED"},
+		{"download dir outside root", func() error {
+			_, err := service.UpdateAccount(ctx, &pb.UpdateAccountRequest{
+				Account:    &pb.Account{AccountId: "new1", DownloadDir: "/etc"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"download_dir"}},
+			})
+			return err
+		}, codes.InvalidArgument, "INVALID_ACCOUNT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if status.Code(err) != tt.code {
+				t.Fatalf("Expected %v, got %v", tt.code, err)
+			}
+			if info := errorInfo(t, err); info.Reason != tt.reason {
+				t.Errorf("Expected reason %s, got %+v", tt.reason, info)
+			}
+		})
+	}
+}
+
+func TestAccountServiceGRPC_ReadOnlyAccounts(t *testing.T) {
+	t.Setenv("ETC_ACCOUNTS_FILE", "")
+	t.Setenv("ETC_CORPORATE_ACCOUNTS", "corp1:pass1")
+	t.Setenv("ETC_PERSONAL_ACCOUNTS", "")
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	downloadService := services.NewDownloadServiceWithFactory(nil, logger, mocks.NewMockScraperFactory())
+	defer downloadService.Shutdown(context.Background())
+	service := services.NewAccountServiceGRPC(downloadService)
+
+	list, err := service.ListAccounts(context.Background(), &pb.ListAccountsRequest{})
+	if err != nil || len(list.Accounts) != 1 || list.Accounts[0].Type != "corporate" || !list.Accounts[0].GetEnabled() {
+		t.Fatalf("Unexpected accounts %v, %v", list, err)
+	}
+
+	_, err = service.DisableAccount(context.Background(), &pb.DisableAccountRequest{AccountId: "corp1"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected FailedPrecondition, got %v", err)
+	}
+	if info := errorInfo(t, err); info.Reason != "ACCOUNTS_READ_ONLY" || info.Metadata["account_id"] != "corp1" {
+		t.Errorf("Unexpected error info %+v", info)
+	}
+}
+
+func TestAccountServiceGRPC_ValidateAccount(t *testing.T) {
+	site := fakesite.New()
+	defer site.Close()
+	site.AddAccount("corp1", "p@ss:word,1")
+	site.AddAccount("personal1", "secret").Locked = true
+	t.Setenv("ETC_SCRAPER_BACKEND", scraper.BackendHTTP)
+	t.Setenv("ETC_BASE_URL", site.URL+"/")
+	t.Setenv("ETC_REQUEST_INTERVAL", "1ms")
+	// The HTTP scraper creates the default download folder of personal1
+	t.Chdir(t.TempDir())
+
+	downloadService, _ := newAccountFileService(t, &services.DefaultScraperFactory{})
+	service := services.NewAccountServiceGRPC(downloadService)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		req       *pb.ValidateAccountRequest
+		valid     bool
+		errorCode string
+	}{
+		{"registered password", &pb.ValidateAccountRequest{AccountId: "corp1"}, true, ""},
+		{"password override", &pb.ValidateAccountRequest{AccountId: "corp1", Password: ptr("wrong")}, false, "INVALID_CREDENTIALS"},
+		{"locked account", &pb.ValidateAccountRequest{AccountId: "personal1"}, false, "ACCOUNT_LOCKED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := service.ValidateAccount(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("ValidateAccount failed: %v", err)
+			}
+			if resp.AccountId != tt.req.AccountId || resp.Valid != tt.valid || resp.ErrorCode != tt.errorCode || resp.CheckedAt == nil {
+				t.Errorf("Unexpected response %v", resp)
+			}
+			if strings.Contains(resp.ErrorMessage, "p@ss") || strings.Contains(resp.ErrorMessage, "wrong") {
+				t.Errorf("Expected no password in the error message, got %q", resp.ErrorMessage)
+			}
+			if site.Sessions() != 0 {
+				t.Errorf("Expected the validation to log out, got %d open sessions", site.Sessions())
+			}
+		})
+	}
+	if site.Downloads() != 0 {
+		t.Errorf("Expected no downloads, got %d", site.Downloads())
+	}
+
+	// The candidate password is only tried, not stored
+	if resp, err := service.ValidateAccount(ctx, &pb.ValidateAccountRequest{AccountId: "corp1"}); err != nil || !resp.Valid {
+		t.Errorf("Expected the registered password to stay valid, got %v, %v", resp, err)
+	}
+
+	_, err := service.ValidateAccount(ctx, &pb.ValidateAccountRequest{AccountId: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown account, got %v", err)
+	}
+}
+
+// ptr returns a pointer to value for optional proto fields
+func ptr[T any](value T) *T {
+	return &value
+}
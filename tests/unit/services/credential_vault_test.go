@@ -0,0 +1,141 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/credentials"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+)
+
+// staticSecrets is a custom secret provider with fixed passwords
+type staticSecrets map[string]string
+
+func (s staticSecrets) Password(ctx context.Context, accountID string) (string, error) {
+	password, ok := s[accountID]
+	if !ok {
+		return "", scraper.ErrSecretNotFound
+	}
+	return password, nil
+}
+
+// newPasswordRecordingService returns a service whose scrapers look up the password at login
+// like the real ones do and record it
+func newPasswordRecordingService(t *testing.T) (*services.DownloadService, func() map[string]string) {
+	t.Helper()
+	var mu sync.Mutex
+	passwords := map[string]string{}
+	csvFactory := newCSVScraperFactory(t)
+	factory := &MockScraperFactory{
+		CreateFunc: func(config *scraper.ScraperConfig, logger *log.Logger) (scraper.ScraperInterface, error) {
+			created, err := csvFactory.CreateScraper(config, logger)
+			if err != nil {
+				return nil, err
+			}
+			mock := created.(*mocks.ConfigurableETCScraper)
+			login := mock.LoginFunc
+			mock.LoginFunc = func() error {
+				password := config.Password
+				if config.Secrets != nil {
+					if password, err = config.Secrets.Password(context.Background(), config.UserID); err != nil {
+						return err
+					}
+				}
+				mu.Lock()
+				passwords[config.UserID] = password
+				mu.Unlock()
+				return login()
+			}
+			return mock, nil
+		},
+	}
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceWithFactory(nil, logger, factory)
+	t.Cleanup(func() { service.Shutdown(context.Background()) })
+	return service, func() map[string]string {
+		mu.Lock()
+		defer mu.Unlock()
+		return passwords
+	}
+}
+
+func TestDownloadService_CredentialVault(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{0x24}, 32)
+	keyPath := filepath.Join(dir, "vault.key")
+	if err := os.WriteFile(keyPath, []byte(hex.EncodeToString(key)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	vaultPath := filepath.Join(dir, "vault.json")
+	vault, err := credentials.Open(vaultPath, key)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := vault.Add("corp1", "vault:pass"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// corp1 has no password in the environment; personal1 is not in the vault
+	t.Setenv("ETC_CORPORATE_ACCOUNTS", "corp1")
+	t.Setenv("ETC_PERSONAL_ACCOUNTS", "personal1:envpass")
+	t.Setenv("ETC_VAULT_PATH", vaultPath)
+	t.Setenv("ETC_VAULT_KEY_FILE", keyPath)
+	service, passwords := newPasswordRecordingService(t)
+
+	accounts, err := service.ResolveAccounts(nil)
+	if err != nil {
+		t.Fatalf("ResolveAccounts() error: %v", err)
+	}
+	service.ProcessAsync("vault-job", accounts, testFromDate, testToDate)
+	waitForJobStatus(t, service, "vault-job", "completed")
+
+	if got := passwords(); got["corp1"] != "vault:pass" || got["personal1"] != "envpass" {
+		t.Errorf("Expected the vault password with the environment as fallback, got %v", got)
+	}
+
+	// A rotated password is used by the next job without restarting the service
+	if err := vault.Rotate("corp1", "rotated"); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	service.ProcessAsync("rotated-job", []string{"corp1"}, testFromDate, testToDate)
+	waitForJobStatus(t, service, "rotated-job", "completed")
+	if got := passwords(); got["corp1"] != "rotated" {
+		t.Errorf("Expected the rotated password, got %q", got["corp1"])
+	}
+}
+
+func TestDownloadService_SetSecretProvider(t *testing.T) {
+	t.Setenv("ETC_CORPORATE_ACCOUNTS", "corp1:envpass1,corp2:envpass2")
+	t.Setenv("ETC_PERSONAL_ACCOUNTS", "")
+	service, passwords := newPasswordRecordingService(t)
+	service.SetSecretProvider(staticSecrets{"corp1": "custom"})
+
+	service.ProcessAsync("custom-job", []string{"corp1", "corp2"}, testFromDate, testToDate)
+	waitForJobStatus(t, service, "custom-job", "completed")
+
+	if got := passwords(); got["corp1"] != "custom" || got["corp2"] != "envpass2" {
+		t.Errorf("Expected the custom provider with the environment as fallback, got %v", got)
+	}
+}
+
+func TestDownloadService_MissingPassword(t *testing.T) {
+	// Without a vault key the vault is disabled and corp1 has no password anywhere
+	t.Setenv("ETC_CORPORATE_ACCOUNTS", "corp1")
+	t.Setenv("ETC_PERSONAL_ACCOUNTS", "")
+	t.Setenv("ETC_VAULT_PATH", filepath.Join(t.TempDir(), "vault.json"))
+	service, _ := newPasswordRecordingService(t)
+
+	service.ProcessAsync("nopass-job", []string{"corp1"}, testFromDate, testToDate)
+	job := waitForJobStatus(t, service, "nopass-job", "failed")
+	if job.Accounts[0].ErrorCode != "INVALID_ACCOUNT_FORMAT" {
+		t.Errorf("Expected INVALID_ACCOUNT_FORMAT, got %+v", job.Accounts[0])
+	}
+}
@@ -0,0 +1,225 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/internal/fakesite"
+	pb "github.com/yhonda-ohishi/etc_meisai_scraper/src/pb"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// stubBufferDownloader returns fixed CSV data and records the request
+type stubBufferDownloader struct {
+	data     []byte
+	err      error
+	accounts []string
+}
+
+func (d *stubBufferDownloader) ResolveAccounts(accounts []string) ([]string, error) {
+	return accounts, nil
+}
+
+func (d *stubBufferDownloader) DownloadBuffer(ctx context.Context, accounts []string, fromDate, toDate string) ([]byte, error) {
+	d.accounts = accounts
+	return d.data, d.err
+}
+
+// chunkStream collects the chunks sent by DownloadStream
+type chunkStream struct {
+	grpc.ServerStream
+	chunks []*pb.ChunkResponse
+}
+
+func (s *chunkStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *chunkStream) Send(chunk *pb.ChunkResponse) error {
+	s.chunks = append(s.chunks, chunk)
+	return nil
+}
+
+func newBufferService(t *testing.T, failLogin ...string) *services.DownloadBufferServiceGRPC {
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	downloadService := services.NewDownloadServiceWithFactory(nil, logger, newCSVScraperFactory(t, failLogin...))
+	return services.NewDownloadBufferServiceGRPC(downloadService)
+}
+
+func siteCSV(t *testing.T, records int) []byte {
+	t.Helper()
+	exitAt := time.Date(2025, 3, 31, 9, 30, 0, 0, time.Local)
+	var rows []fakesite.Record
+	for i := 0; i < records; i++ {
+		rows = append(rows, fakesite.Record{
+			EntryAt: exitAt, ExitAt: exitAt.Add(time.Duration(i) * time.Hour),
+			EntryIC: "東京", ExitIC: "横浜", BaseFare: 1320, Fare: 1320,
+			VehicleClass: "普通", VehicleNumber: "品川 300 あ 12-34", CardNumber: "1234",
+		})
+	}
+	data, err := fakesite.EncodeCSV(rows)
+	if err != nil {
+		t.Fatalf("Failed to encode CSV: %v", err)
+	}
+	return data
+}
+
+func TestDownloadBufferServiceGRPC_DownloadAsBuffer(t *testing.T) {
+	registerAccounts(t, "test1")
+	service := newBufferService(t)
+
+	resp, err := service.DownloadAsBuffer(context.Background(), &pb.BufferDownloadRequest{
+		Accounts: []string{"test1"},
+		FromDate: testFromDate,
+		ToDate:   testToDate,
+	})
+	if err != nil {
+		t.Fatalf("DownloadAsBuffer failed: %v", err)
+	}
+
+	if resp.RecordCount != 1 {
+		t.Errorf("Expected 1 record, got %d", resp.RecordCount)
+	}
+	if resp.SizeBytes != int64(len(resp.CsvData)) || resp.SizeBytes == 0 {
+		t.Errorf("Expected size_bytes to match %d bytes of CSV, got %d", len(resp.CsvData), resp.SizeBytes)
+	}
+	if resp.ContentType != "text/csv; charset=Shift_JIS" {
+		t.Errorf("Unexpected content type %q", resp.ContentType)
+	}
+}
+
+func TestDownloadBufferServiceGRPC_DownloadAsProto(t *testing.T) {
+	registerAccounts(t, "test1", "test2")
+	service := newBufferService(t)
+
+	resp, err := service.DownloadAsProto(context.Background(), &pb.BufferDownloadRequest{
+		Accounts: []string{"test1", "test2"},
+		FromDate: testFromDate,
+		ToDate:   testToDate,
+	})
+	if err != nil {
+		t.Fatalf("DownloadAsProto failed: %v", err)
+	}
+
+	if len(resp.Records) != 2 || resp.Metadata.TotalCount != 2 {
+		t.Fatalf("Expected 2 records, got %d (total_count %d)", len(resp.Records), resp.Metadata.TotalCount)
+	}
+	if resp.Metadata.AccountId != "test1,test2" {
+		t.Errorf("Unexpected account_id %q", resp.Metadata.AccountId)
+	}
+	if resp.Metadata.DownloadedAt == nil {
+		t.Error("Expected downloaded_at")
+	}
+
+	record := resp.Records[0]
+	if record.Date != testFromDate || record.Time != "01:00" {
+		t.Errorf("Unexpected date and time %s %s", record.Date, record.Time)
+	}
+	if record.EntranceIc != "東京" || record.ExitIc != "横浜" || record.Fare != 1320 || record.CardNumber != "test1" {
+		t.Errorf("Unexpected record %+v", record)
+	}
+}
+
+func TestDownloadBufferServiceGRPC_DownloadStream(t *testing.T) {
+	registerAccounts(t, "test1")
+	data := siteCSV(t, 5)
+	downloader := &stubBufferDownloader{data: data}
+	service := services.NewDownloadBufferServiceGRPCWithChunkSize(downloader, 64)
+
+	stream := &chunkStream{}
+	err := service.DownloadStream(&pb.BufferDownloadRequest{Accounts: []string{"test1"}}, stream)
+	if err != nil {
+		t.Fatalf("DownloadStream failed: %v", err)
+	}
+
+	expectedChunks := (len(data) + 63) / 64
+	if len(stream.chunks) != expectedChunks {
+		t.Fatalf("Expected %d chunks, got %d", expectedChunks, len(stream.chunks))
+	}
+	var received []byte
+	for i, chunk := range stream.chunks {
+		if chunk.SequenceNumber != int32(i) {
+			t.Errorf("Chunk %d has sequence number %d", i, chunk.SequenceNumber)
+		}
+		if chunk.IsLast != (i == len(stream.chunks)-1) {
+			t.Errorf("Chunk %d has is_last %v", i, chunk.IsLast)
+		}
+		received = append(received, chunk.Chunk...)
+	}
+	if !bytes.Equal(received, data) {
+		t.Error("Reassembled chunks differ from the CSV")
+	}
+}
+
+func TestDownloadBufferServiceGRPC_Errors(t *testing.T) {
+	registerAccounts(t, "test1", "bad")
+	t.Run("no accounts", func(t *testing.T) {
+		downloader := &stubBufferDownloader{}
+		service := services.NewDownloadBufferServiceGRPC(downloader)
+
+		_, err := service.DownloadAsBuffer(context.Background(), &pb.BufferDownloadRequest{})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+		if downloader.accounts != nil {
+			t.Error("Expected no download without accounts")
+		}
+	})
+
+	t.Run("login rejected", func(t *testing.T) {
+		service := newBufferService(t, "bad")
+
+		_, err := service.DownloadAsProto(context.Background(), &pb.BufferDownloadRequest{
+			Accounts: []string{"test1", "bad"},
+			FromDate: testFromDate,
+			ToDate:   testToDate,
+		})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("Expected Unauthenticated, got %v", err)
+		}
+		if info := errorInfo(t, err); info.Metadata["account_id"] != "bad" {
+			t.Errorf("Expected account_id of the failed account, got %v", info.Metadata)
+		}
+	})
+
+	t.Run("not a meisai CSV", func(t *testing.T) {
+		data := []byte("<html></html>\n")
+		service := services.NewDownloadBufferServiceGRPC(&stubBufferDownloader{data: data})
+		req := &pb.BufferDownloadRequest{Accounts: []string{"test1"}}
+
+		// Only DownloadAsProto needs the records; the raw RPCs return the data as is
+		if _, err := service.DownloadAsProto(context.Background(), req); status.Code(err) != codes.Internal {
+			t.Errorf("Expected Internal from DownloadAsProto, got %v", err)
+		}
+		resp, err := service.DownloadAsBuffer(context.Background(), req)
+		if err != nil {
+			t.Fatalf("DownloadAsBuffer() error: %v", err)
+		}
+		if !bytes.Equal(resp.CsvData, data) || resp.RecordCount != 0 || resp.ContentType != "text/csv" {
+			t.Errorf("Unexpected buffer response %+v", resp)
+		}
+		stream := &chunkStream{}
+		if err := service.DownloadStream(req, stream); err != nil {
+			t.Fatalf("DownloadStream() error: %v", err)
+		}
+		if len(stream.chunks) != 1 || !bytes.Equal(stream.chunks[0].Chunk, data) {
+			t.Errorf("Expected the data in one chunk, got %+v", stream.chunks)
+		}
+	})
+
+	t.Run("download error", func(t *testing.T) {
+		service := services.NewDownloadBufferServiceGRPC(&stubBufferDownloader{err: errors.New("boom")})
+
+		if _, err := service.DownloadAsBuffer(context.Background(), &pb.BufferDownloadRequest{Accounts: []string{"test1"}}); err == nil {
+			t.Error("Expected an error")
+		}
+	})
+}
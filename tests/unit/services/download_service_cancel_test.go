@@ -0,0 +1,160 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+)
+
+// newBlockingScraperFactory returns a factory whose scrapers block in DownloadMeisai until released
+func newBlockingScraperFactory(release <-chan struct{}, closed *atomic.Int32) *MockScraperFactory {
+	return &MockScraperFactory{
+		CreateFunc: func(config *scraper.ScraperConfig, logger *log.Logger) (scraper.ScraperInterface, error) {
+			mock := mocks.NewConfigurableETCScraper()
+			mock.DownloadFunc = func(fromDate, toDate string) (string, error) {
+				<-release
+				return "", nil
+			}
+			mock.CloseFunc = func() error {
+				closed.Add(1)
+				return nil
+			}
+			return mock, nil
+		},
+	}
+}
+
+func waitForJobStatus(t *testing.T, service *services.DownloadService, jobID, status string) *services.DownloadJob {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, exists := service.GetJobStatus(jobID); exists && job.Status == status {
+			return job
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	job, _ := service.GetJobStatus(jobID)
+	t.Fatalf("Job %s did not reach status %q, last: %+v", jobID, status, job)
+	return nil
+}
+
+func TestDownloadService_ProcessAsyncContext_Cancel(t *testing.T) {
+	registerAccounts(t, "acc1", "acc2")
+	release := make(chan struct{})
+	defer close(release)
+	var closed atomic.Int32
+
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceWithFactory(nil, logger, newBlockingScraperFactory(release, &closed))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	jobID := "cancel-job"
+	service.ProcessAsyncContext(ctx, jobID, []string{"acc1", "acc2"}, testFromDate, testToDate)
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	job := waitForJobStatus(t, service, jobID, "cancelled")
+	if job.CompletedAt == nil {
+		t.Error("Expected CompletedAt to be set for cancelled job")
+	}
+	if closed.Load() == 0 {
+		t.Error("Expected in-flight scraper to be closed")
+	}
+}
+
+func TestDownloadService_Shutdown(t *testing.T) {
+	registerAccounts(t, "acc1")
+	release := make(chan struct{})
+	defer close(release)
+	var closed atomic.Int32
+
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceWithFactory(nil, logger, newBlockingScraperFactory(release, &closed))
+
+	jobID := "shutdown-job"
+	service.ProcessAsync(jobID, []string{"acc1"}, testFromDate, testToDate)
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := service.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error: %v", err)
+	}
+
+	job, _ := service.GetJobStatus(jobID)
+	if job.Status != "cancelled" {
+		t.Errorf("Expected status 'cancelled', got %s", job.Status)
+	}
+}
+
+func TestDownloadService_CancelJob(t *testing.T) {
+	registerAccounts(t, "acc1", "acc2")
+	release := make(chan struct{})
+	defer close(release)
+	var closed atomic.Int32
+
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceWithFactory(nil, logger, newBlockingScraperFactory(release, &closed))
+	defer service.Shutdown(context.Background())
+
+	jobID := "cancel-request-job"
+	service.ProcessAsync(jobID, []string{"acc1", "acc2"}, testFromDate, testToDate)
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	job, err := service.CancelJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("CancelJob() error: %v", err)
+	}
+	if job.Status != "cancelled" || job.CancelledAt == nil || job.CompletedAt == nil {
+		t.Errorf("Expected a cancelled job with timestamps, got %+v", job)
+	}
+	if !strings.Contains(job.ErrorMessage, services.ErrJobCancelled.Error()) {
+		t.Errorf("Expected error message to mention the cancel request, got %q", job.ErrorMessage)
+	}
+	for _, account := range job.Accounts {
+		if account.Status == "pending" || account.Status == "processing" {
+			t.Errorf("Account %s left in status %s", account.AccountID, account.Status)
+		}
+	}
+	if closed.Load() == 0 {
+		t.Error("Expected in-flight scraper to be closed")
+	}
+
+	// 2回目のキャンセルは同じジョブを返す
+	again, err := service.CancelJob(ctx, jobID)
+	if err != nil || again.Status != "cancelled" || !again.CancelledAt.Equal(*job.CancelledAt) {
+		t.Errorf("Expected the cancelled job again, got %+v (%v)", again, err)
+	}
+}
+
+func TestDownloadService_CancelJob_Errors(t *testing.T) {
+	registerAccounts(t, "acc1")
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceWithFactory(nil, logger, newCSVScraperFactory(t))
+	defer service.Shutdown(context.Background())
+
+	if _, err := service.CancelJob(context.Background(), "missing-job"); !errors.Is(err, services.ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+
+	service.ProcessAsync("finished-job", []string{"acc1"}, testFromDate, testToDate)
+	waitForJobStatus(t, service, "finished-job", "completed")
+	if _, err := service.CancelJob(context.Background(), "finished-job"); !errors.Is(err, services.ErrJobAlreadyFinished) {
+		t.Errorf("Expected ErrJobAlreadyFinished, got %v", err)
+	}
+	if job, _ := service.GetJobStatus("finished-job"); job.Status != "completed" || job.CancelledAt != nil {
+		t.Errorf("Finished job should be left alone, got %+v", job)
+	}
+}
@@ -30,13 +30,14 @@ func TestNewDownloadService(t *testing.T) {
 }
 
 func TestDownloadService_ProcessAsync_CompleteFlow(t *testing.T) {
+	registerAccounts(t, "test1")
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 	service := services.NewDownloadService(nil, logger)
 
 	jobID := "test-complete-job"
-	accounts := []string{"test1:pass1"}
-	fromDate := "2024-01-01"
-	toDate := "2024-01-31"
+	accounts := []string{"test1"}
+	fromDate := testFromDate
+	toDate := testToDate
 
 	// Start async processing
 	service.ProcessAsync(jobID, accounts, fromDate, toDate)
@@ -73,8 +74,8 @@ func TestDownloadService_ProcessAsync_InvalidAccount(t *testing.T) {
 	// Test with invalid account format (missing password)
 	jobID := "test-invalid-account"
 	accounts := []string{"invalid_account_no_password"}
-	fromDate := "2024-01-01"
-	toDate := "2024-01-31"
+	fromDate := testFromDate
+	toDate := testToDate
 
 	service.ProcessAsync(jobID, accounts, fromDate, toDate)
 
@@ -99,8 +100,8 @@ func TestDownloadService_ProcessAsync_Panic_Recovery(t *testing.T) {
 	// Create a job that might cause issues
 	jobID := "test-panic-job"
 	accounts := []string{} // Empty accounts
-	fromDate := "2024-01-01"
-	toDate := "2024-01-31"
+	fromDate := testFromDate
+	toDate := testToDate
 
 	// This should not panic
 	service.ProcessAsync(jobID, accounts, fromDate, toDate)
@@ -223,7 +224,7 @@ func TestDownloadService_ConcurrentAccess(t *testing.T) {
 			jobID := fmt.Sprintf("concurrent-job-%d", id)
 			accounts := []string{fmt.Sprintf("account%d:pass%d", id, id)}
 
-			service.ProcessAsync(jobID, accounts, "2024-01-01", "2024-01-31")
+			service.ProcessAsync(jobID, accounts, testFromDate, testToDate)
 
 			// Check status multiple times
 			for j := 0; j < 5; j++ {
@@ -248,12 +249,13 @@ func TestDownloadService_ConcurrentAccess(t *testing.T) {
 }
 
 func TestDownloadService_UpdateJobProgress(t *testing.T) {
+	registerAccounts(t, "test")
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 	service := services.NewDownloadService(nil, logger)
 
 	// Create a job
 	jobID := "progress-test-job"
-	service.ProcessAsync(jobID, []string{"test:pass"}, "2024-01-01", "2024-01-31")
+	service.ProcessAsync(jobID, []string{"test"}, testFromDate, testToDate)
 
 	// Give it a moment to start
 	time.Sleep(50 * time.Millisecond)
@@ -286,7 +288,7 @@ func TestDownloadService_ErrorMessage(t *testing.T) {
 	jobID := "error-test-job"
 	accounts := []string{"invalid"} // Missing password part
 
-	service.ProcessAsync(jobID, accounts, "2024-01-01", "2024-01-31")
+	service.ProcessAsync(jobID, accounts, testFromDate, testToDate)
 
 	// Wait for processing
 	time.Sleep(2 * time.Second)
@@ -9,12 +9,12 @@ import (
 
 	pb "github.com/yhonda-ohishi/etc_meisai_scraper/src/pb"
 	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestDownloadServiceGRPC_GetJobStatus_With_CompletedAt_Coverage(t *testing.T) {
-	// Set up test environment
-	os.Setenv("ETC_CORPORATE_ACCOUNTS", "test1:pass1")
-	defer os.Unsetenv("ETC_CORPORATE_ACCOUNTS")
+	registerAccounts(t, "test1")
 
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 	service := services.NewDownloadServiceGRPC(nil, logger)
@@ -22,9 +22,9 @@ func TestDownloadServiceGRPC_GetJobStatus_With_CompletedAt_Coverage(t *testing.T
 
 	// Create a job
 	createReq := &pb.DownloadRequest{
-		Accounts: []string{"test1:pass1"},
-		FromDate: "2024-01-01",
-		ToDate:   "2024-01-31",
+		Accounts: []string{"test1"},
+		FromDate: testFromDate,
+		ToDate:   testToDate,
 	}
 
 	createResp, err := service.DownloadAsync(ctx, createReq)
@@ -101,12 +101,12 @@ func TestDownloadServiceGRPC_GetJobStatus_With_CompletedAt_Coverage(t *testing.T
 		}
 	})
 
-	// Test 3: Non-existent job (ensures nil response path)
-	t.Run("non-existent job returns nil", func(t *testing.T) {
+	// Test 3: Non-existent job
+	t.Run("non-existent job returns NotFound", func(t *testing.T) {
 		req := &pb.GetJobStatusRequest{JobId: "non-existent-job-12345"}
 		resp, err := service.GetJobStatus(ctx, req)
-		if err != nil {
-			t.Fatalf("GetJobStatus failed: %v", err)
+		if status.Code(err) != codes.NotFound {
+			t.Fatalf("Expected NotFound, got %v", err)
 		}
 
 		if resp != nil {
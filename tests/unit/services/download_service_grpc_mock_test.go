@@ -5,8 +5,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/models"
 	pb "github.com/yhonda-ohishi/etc_meisai_scraper/src/pb"
 	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // MockDownloadService implements DownloadServiceInterface for testing
@@ -24,6 +27,13 @@ func (m *MockDownloadService) GetAllAccountIDs() []string {
 	return []string{"test1", "test2"}
 }
 
+func (m *MockDownloadService) ResolveAccounts(accounts []string) ([]string, error) {
+	if len(accounts) == 0 {
+		accounts = m.GetAllAccountIDs()
+	}
+	return accounts, nil
+}
+
 func (m *MockDownloadService) ProcessAsync(jobID string, accounts []string, fromDate, toDate string) {
 	now := time.Now()
 	m.jobs[jobID] = &services.DownloadJob{
@@ -35,11 +45,38 @@ func (m *MockDownloadService) ProcessAsync(jobID string, accounts []string, from
 	}
 }
 
+func (m *MockDownloadService) DownloadSync(ctx context.Context, accounts []string, fromDate, toDate string) (*models.DownloadResult, error) {
+	return &models.DownloadResult{Success: true, Records: []models.ETCMeisaiRecord{}}, nil
+}
+
 func (m *MockDownloadService) GetJobStatus(jobID string) (*services.DownloadJob, bool) {
 	job, exists := m.jobs[jobID]
 	return job, exists
 }
 
+func (m *MockDownloadService) ListJobs(filter services.JobFilter) (*services.JobPage, error) {
+	page := &services.JobPage{}
+	for _, job := range m.jobs {
+		page.Jobs = append(page.Jobs, job)
+	}
+	return page, nil
+}
+
+func (m *MockDownloadService) CancelJob(ctx context.Context, jobID string) (*services.DownloadJob, error) {
+	job, exists := m.jobs[jobID]
+	if !exists {
+		return nil, services.ErrJobNotFound
+	}
+	if job.Status != "processing" {
+		return nil, services.ErrJobAlreadyFinished
+	}
+	now := time.Now()
+	job.Status = "cancelled"
+	job.CompletedAt = &now
+	job.CancelledAt = &now
+	return job, nil
+}
+
 // TestDownloadServiceGRPC_GetJobStatus_WithMock tests GetJobStatus with mocked downloadService
 func TestDownloadServiceGRPC_GetJobStatus_WithMock(t *testing.T) {
 	ctx := context.Background()
@@ -130,8 +167,8 @@ func TestDownloadServiceGRPC_GetJobStatus_WithMock(t *testing.T) {
 
 		req := &pb.GetJobStatusRequest{JobId: "non-existent"}
 		resp, err := grpcService.GetJobStatus(ctx, req)
-		if err != nil {
-			t.Fatalf("GetJobStatus failed: %v", err)
+		if status.Code(err) != codes.NotFound {
+			t.Fatalf("Expected NotFound, got %v", err)
 		}
 
 		// Should return nil for non-existent job
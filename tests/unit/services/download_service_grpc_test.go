@@ -4,23 +4,66 @@ import (
 	"context"
 	"log"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/yhonda-ohishi/etc_meisai_scraper/internal/fakesite"
 	pb "github.com/yhonda-ohishi/etc_meisai_scraper/src/pb"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
 	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// newCSVScraperFactory returns a factory whose scrapers save a site CSV with one record per account,
+// except for the accounts in failLogin whose login is rejected
+func newCSVScraperFactory(t *testing.T, failLogin ...string) *MockScraperFactory {
+	dir := t.TempDir()
+	return &MockScraperFactory{
+		CreateFunc: func(config *scraper.ScraperConfig, logger *log.Logger) (scraper.ScraperInterface, error) {
+			userID := config.UserID
+			mock := mocks.NewConfigurableETCScraper()
+			mock.LoginFunc = func() error {
+				if slices.Contains(failLogin, userID) {
+					return &scraper.LoginError{Kind: scraper.ErrInvalidCredentials, Message: "ログインできません"}
+				}
+				return nil
+			}
+			mock.DownloadFunc = func(fromDate, toDate string) (string, error) {
+				exitAt, _ := time.ParseInLocation("2006-01-02", fromDate, time.Local)
+				data, err := fakesite.EncodeCSV([]fakesite.Record{{
+					EntryAt: exitAt, ExitAt: exitAt.Add(time.Hour),
+					EntryIC: "東京", ExitIC: "横浜", BaseFare: 1320, Fare: 1320,
+					VehicleClass: "普通", VehicleNumber: "品川 300 あ 12-34", CardNumber: userID,
+				}})
+				if err != nil {
+					return "", err
+				}
+				path := filepath.Join(dir, userID+"_meisai.csv")
+				return path, os.WriteFile(path, data, 0644)
+			}
+			return mock, nil
+		},
+	}
+}
+
 func TestDownloadServiceGRPC_DownloadSync(t *testing.T) {
+	registerAccounts(t, "test1")
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
-	service := services.NewDownloadServiceGRPC(nil, logger)
+	downloadService := services.NewDownloadServiceWithFactory(nil, logger, newCSVScraperFactory(t))
+	service := services.NewDownloadServiceGRPCWithMock(downloadService)
 
 	ctx := context.Background()
 	req := &pb.DownloadRequest{
-		Accounts: []string{"test1:pass1"},
-		FromDate: "2024-01-01",
-		ToDate:   "2024-01-31",
+		Accounts: []string{"test1"},
+		FromDate: testFromDate,
+		ToDate:   testToDate,
 	}
 
 	resp, err := service.DownloadSync(ctx, req)
@@ -33,27 +76,91 @@ func TestDownloadServiceGRPC_DownloadSync(t *testing.T) {
 	}
 
 	if !resp.Success {
-		t.Error("Expected success to be true")
+		t.Errorf("Expected success to be true, error: %s", resp.Error)
+	}
+	if resp.RecordCount != 1 || len(resp.Records) != 1 {
+		t.Fatalf("Expected 1 record, got %d (%d records)", resp.RecordCount, len(resp.Records))
+	}
+	if filepath.Base(resp.CsvPath) != "test1_meisai.csv" {
+		t.Errorf("Unexpected CSV path %q", resp.CsvPath)
+	}
+	record := resp.Records[0]
+	if record.AccountId != "test1" || record.ExitIc != "横浜" || record.Amount != 1320 || record.CsvFileName != "test1_meisai.csv" {
+		t.Errorf("Unexpected record %+v", record)
+	}
+	if record.DownloadedAt == nil || record.UsageDate == nil {
+		t.Error("Expected usage and download timestamps")
+	}
+}
+
+func TestDownloadServiceGRPC_DownloadSync_AccountFailure(t *testing.T) {
+	registerAccounts(t, "test1", "bad", "test2")
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	downloadService := services.NewDownloadServiceWithFactory(nil, logger, newCSVScraperFactory(t, "bad"))
+	service := services.NewDownloadServiceGRPCWithMock(downloadService)
+
+	resp, err := service.DownloadSync(context.Background(), &pb.DownloadRequest{
+		Accounts: []string{"test1", "bad", "test2"},
+		FromDate: testFromDate,
+		ToDate:   testToDate,
+	})
+	if err != nil {
+		t.Fatalf("DownloadSync failed: %v", err)
+	}
+
+	if resp.Success {
+		t.Error("Expected success to be false when an account fails")
+	}
+	if !strings.HasPrefix(resp.Error, "bad: ") || !strings.Contains(resp.Error, "ログインできません") {
+		t.Errorf("Expected the failed account in error, got %q", resp.Error)
+	}
+	if resp.RecordCount != 2 {
+		t.Errorf("Expected the records of the other accounts, got %d", resp.RecordCount)
+	}
+	if filepath.Base(resp.CsvPath) != "meisai_"+strings.ReplaceAll(testFromDate, "-", "")+"_"+strings.ReplaceAll(testToDate, "-", "")+".csv" {
+		t.Errorf("Expected a merged CSV, got %q", resp.CsvPath)
+	}
+}
+
+func TestDownloadServiceGRPC_DownloadSync_InvalidRequest(t *testing.T) {
+	registerAccounts(t, "test1")
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	downloadService := services.NewDownloadServiceWithFactory(nil, logger, newCSVScraperFactory(t))
+	service := services.NewDownloadServiceGRPCWithMock(downloadService)
+
+	requests := map[string]*pb.DownloadRequest{
+		"no accounts":       {FromDate: testFromDate, ToDate: testToDate},
+		"outside retention": {Accounts: []string{"test1"}, FromDate: "2000-01-01", ToDate: "2000-01-31"},
+	}
+	for name, req := range requests {
+		t.Run(name, func(t *testing.T) {
+			_, err := service.DownloadSync(context.Background(), req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Expected InvalidArgument, got %v", err)
+			}
+		})
 	}
 }
 
 func TestDownloadServiceGRPC_DownloadAsync(t *testing.T) {
+	registerAccounts(t, "test1")
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 	service := services.NewDownloadServiceGRPC(nil, logger)
 
 	tests := []struct {
 		name     string
 		req      *pb.DownloadRequest
-		wantFail bool
+		wantCode codes.Code
+		reason   string
 	}{
 		{
 			name: "with accounts",
 			req: &pb.DownloadRequest{
-				Accounts: []string{"test1:pass1"},
-				FromDate: "2024-01-01",
-				ToDate:   "2024-01-31",
+				Accounts: []string{"test1"},
+				FromDate: testFromDate,
+				ToDate:   testToDate,
 			},
-			wantFail: false,
+			wantCode: codes.OK,
 		},
 		{
 			name: "without accounts - use defaults",
@@ -62,7 +169,37 @@ func TestDownloadServiceGRPC_DownloadAsync(t *testing.T) {
 				FromDate: "",
 				ToDate:   "",
 			},
-			wantFail: true, // Will fail because no accounts are configured
+			wantCode: codes.OK, // all registered accounts
+		},
+		{
+			name: "invalid account format",
+			req: &pb.DownloadRequest{
+				Accounts: []string{"test1", "test2:pass2"},
+				FromDate: testFromDate,
+				ToDate:   testToDate,
+			},
+			wantCode: codes.InvalidArgument,
+			reason:   "INVALID_ACCOUNT_FORMAT",
+		},
+		{
+			name: "unknown account",
+			req: &pb.DownloadRequest{
+				Accounts: []string{"test1", "test2"},
+				FromDate: testFromDate,
+				ToDate:   testToDate,
+			},
+			wantCode: codes.NotFound, // test2 is not registered
+			reason:   "UNKNOWN_ACCOUNT",
+		},
+		{
+			name: "invalid dates",
+			req: &pb.DownloadRequest{
+				Accounts: []string{"test1"},
+				FromDate: testToDate,
+				ToDate:   testFromDate,
+			},
+			wantCode: codes.InvalidArgument,
+			reason:   "INVALID_DATE_RANGE",
 		},
 	}
 
@@ -71,35 +208,49 @@ func TestDownloadServiceGRPC_DownloadAsync(t *testing.T) {
 			ctx := context.Background()
 			resp, err := service.DownloadAsync(ctx, tt.req)
 
-			if err != nil {
-				t.Fatalf("DownloadAsync failed: %v", err)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Expected %v, got %v", tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				if info := errorInfo(t, err); info.Reason != tt.reason {
+					t.Errorf("Expected reason %s, got %s", tt.reason, info.Reason)
+				}
+				return
 			}
 
 			if resp == nil {
 				t.Fatal("Expected non-nil response")
 			}
-
-			if tt.wantFail && resp.Status != "failed" {
-				t.Error("Expected status to be 'failed'")
-			}
-
-			if !tt.wantFail && resp.JobId == "" {
+			if resp.JobId == "" {
 				t.Error("Expected non-empty job ID")
 			}
 		})
 	}
 }
 
+// errorInfo returns the ErrorInfo detail of a gRPC status error
+func errorInfo(t *testing.T, err error) *errdetails.ErrorInfo {
+	t.Helper()
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	t.Fatalf("Expected ErrorInfo detail in %v", err)
+	return nil
+}
+
 func TestDownloadServiceGRPC_GetJobStatus(t *testing.T) {
+	registerAccounts(t, "test1")
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 	service := services.NewDownloadServiceGRPC(nil, logger)
 
 	// First create a job
 	ctx := context.Background()
 	createReq := &pb.DownloadRequest{
-		Accounts: []string{"test1:pass1"},
-		FromDate: "2024-01-01",
-		ToDate:   "2024-01-31",
+		Accounts: []string{"test1"},
+		FromDate: testFromDate,
+		ToDate:   testToDate,
 	}
 
 	createResp, err := service.DownloadAsync(ctx, createReq)
@@ -170,8 +321,11 @@ func TestDownloadServiceGRPC_GetJobStatus(t *testing.T) {
 		}
 
 		resp, err := service.GetJobStatus(ctx, req)
-		if err != nil {
-			t.Fatalf("GetJobStatus failed: %v", err)
+		if status.Code(err) != codes.NotFound {
+			t.Fatalf("Expected NotFound, got %v", err)
+		}
+		if info := errorInfo(t, err); info.Reason != "JOB_NOT_FOUND" || info.Metadata["job_id"] != "non-existing-job" {
+			t.Errorf("Unexpected error info %+v", info)
 		}
 
 		if resp != nil {
@@ -180,6 +334,22 @@ func TestDownloadServiceGRPC_GetJobStatus(t *testing.T) {
 	})
 }
 
+func TestDownloadServiceGRPC_CancelJob(t *testing.T) {
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceGRPC(nil, logger)
+
+	resp, err := service.CancelJob(context.Background(), &pb.CancelJobRequest{JobId: "non-existing-job"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected NotFound, got %v", err)
+	}
+	if info := errorInfo(t, err); info.Reason != "JOB_NOT_FOUND" || info.Metadata["job_id"] != "non-existing-job" {
+		t.Errorf("Unexpected error info %+v", info)
+	}
+	if resp != nil {
+		t.Error("Expected nil response for non-existing job")
+	}
+}
+
 func TestDownloadServiceGRPC_GetAllAccountIDs(t *testing.T) {
 	// Setup environment variables
 	os.Setenv("ETC_CORPORATE_ACCOUNTS", "corp1:pass1,corp2:pass2")
@@ -217,13 +387,14 @@ func TestDownloadServiceGRPC_GetAllAccountIDs(t *testing.T) {
 }
 
 func TestDownloadServiceGRPC_SetDefaultDates(t *testing.T) {
+	registerAccounts(t, "test1")
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 	service := services.NewDownloadServiceGRPC(nil, logger)
 
 	// Test with empty dates (should set defaults)
 	ctx := context.Background()
 	req := &pb.DownloadRequest{
-		Accounts: []string{"test1:pass1"},
+		Accounts: []string{"test1"},
 		FromDate: "",
 		ToDate:   "",
 	}
@@ -239,9 +410,9 @@ func TestDownloadServiceGRPC_SetDefaultDates(t *testing.T) {
 
 	// Test with provided dates
 	req2 := &pb.DownloadRequest{
-		Accounts: []string{"test1:pass1"},
-		FromDate: "2024-01-01",
-		ToDate:   "2024-01-31",
+		Accounts: []string{"test1"},
+		FromDate: testFromDate,
+		ToDate:   testToDate,
 	}
 
 	resp2, err := service.DownloadSync(ctx, req2)
@@ -323,4 +494,4 @@ func TestJobStatus_Protobuf(t *testing.T) {
 	if !status.CompletedAt.AsTime().Equal(completedAt) {
 		t.Error("CompletedAt time mismatch")
 	}
-}
\ No newline at end of file
+}
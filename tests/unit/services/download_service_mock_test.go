@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -30,6 +31,7 @@ func (f *MockScraperFactory) CreateScraper(config *scraper.ScraperConfig, logger
 }
 
 func TestDownloadService_WithMockScraper_Success(t *testing.T) {
+	registerAccounts(t, "test1")
 	// Setup
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 	mockScraper := mocks.NewMockETCScraper()
@@ -37,17 +39,13 @@ func TestDownloadService_WithMockScraper_Success(t *testing.T) {
 		MockScraper: mockScraper,
 	}
 
-	// Set environment for accounts
-	os.Setenv("ETC_CORPORATE_ACCOUNTS", "corp1:pass1")
-	defer os.Unsetenv("ETC_CORPORATE_ACCOUNTS")
-
 	service := services.NewDownloadServiceWithFactory(nil, logger, mockFactory)
 
 	// Execute
 	jobID := "mock-test-job"
-	accounts := []string{"test1:pass1"}
-	fromDate := "2024-01-01"
-	toDate := "2024-01-31"
+	accounts := []string{"test1"}
+	fromDate := testFromDate
+	toDate := testToDate
 
 	service.ProcessAsync(jobID, accounts, fromDate, toDate)
 
@@ -83,6 +81,7 @@ func TestDownloadService_WithMockScraper_Success(t *testing.T) {
 }
 
 func TestDownloadService_WithMockScraper_InitializeError(t *testing.T) {
+	registerAccounts(t, "test")
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 	mockScraper := mocks.NewMockETCScraper()
 	mockScraper.InitializeError = errors.New("initialize failed")
@@ -95,7 +94,7 @@ func TestDownloadService_WithMockScraper_InitializeError(t *testing.T) {
 
 	// Execute
 	jobID := "init-error-job"
-	service.ProcessAsync(jobID, []string{"test:pass"}, "2024-01-01", "2024-01-31")
+	service.ProcessAsync(jobID, []string{"test"}, testFromDate, testToDate)
 
 	// Wait for processing
 	time.Sleep(2 * time.Second)
@@ -120,6 +119,7 @@ func TestDownloadService_WithMockScraper_InitializeError(t *testing.T) {
 }
 
 func TestDownloadService_WithMockScraper_LoginError(t *testing.T) {
+	registerAccounts(t, "test")
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 	mockScraper := mocks.NewMockETCScraper()
 	mockScraper.LoginError = errors.New("login failed")
@@ -132,7 +132,7 @@ func TestDownloadService_WithMockScraper_LoginError(t *testing.T) {
 
 	// Execute
 	jobID := "login-error-job"
-	service.ProcessAsync(jobID, []string{"test:pass"}, "2024-01-01", "2024-01-31")
+	service.ProcessAsync(jobID, []string{"test"}, testFromDate, testToDate)
 
 	// Wait for processing
 	time.Sleep(2 * time.Second)
@@ -156,6 +156,7 @@ func TestDownloadService_WithMockScraper_LoginError(t *testing.T) {
 }
 
 func TestDownloadService_WithMockScraper_DownloadError(t *testing.T) {
+	registerAccounts(t, "test")
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 	mockScraper := mocks.NewMockETCScraper()
 	mockScraper.DownloadError = errors.New("download failed")
@@ -168,7 +169,7 @@ func TestDownloadService_WithMockScraper_DownloadError(t *testing.T) {
 
 	// Execute
 	jobID := "download-error-job"
-	service.ProcessAsync(jobID, []string{"test:pass"}, "2024-01-01", "2024-01-31")
+	service.ProcessAsync(jobID, []string{"test"}, testFromDate, testToDate)
 
 	// Wait for processing
 	time.Sleep(2 * time.Second)
@@ -191,16 +192,17 @@ func TestDownloadService_WithMockScraper_DownloadError(t *testing.T) {
 	}
 
 	// Verify dates were passed correctly
-	if mockScraper.FromDate != "2024-01-01" {
-		t.Errorf("Expected FromDate '2024-01-01', got '%s'", mockScraper.FromDate)
+	if mockScraper.FromDate != testFromDate {
+		t.Errorf("Expected FromDate '%s', got '%s'", testFromDate, mockScraper.FromDate)
 	}
 
-	if mockScraper.ToDate != "2024-01-31" {
-		t.Errorf("Expected ToDate '2024-01-31', got '%s'", mockScraper.ToDate)
+	if mockScraper.ToDate != testToDate {
+		t.Errorf("Expected ToDate '%s', got '%s'", testToDate, mockScraper.ToDate)
 	}
 }
 
 func TestDownloadService_WithMockScraper_ScraperCreateError(t *testing.T) {
+	registerAccounts(t, "test")
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 
 	mockFactory := &MockScraperFactory{
@@ -211,7 +213,7 @@ func TestDownloadService_WithMockScraper_ScraperCreateError(t *testing.T) {
 
 	// Execute
 	jobID := "create-error-job"
-	service.ProcessAsync(jobID, []string{"test:pass"}, "2024-01-01", "2024-01-31")
+	service.ProcessAsync(jobID, []string{"test"}, testFromDate, testToDate)
 
 	// Wait for processing
 	time.Sleep(2 * time.Second)
@@ -229,6 +231,7 @@ func TestDownloadService_WithMockScraper_ScraperCreateError(t *testing.T) {
 }
 
 func TestDownloadService_UpdateJobStatus(t *testing.T) {
+	registerAccounts(t, "test")
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 
 	// Create a mock that will trigger panic recovery
@@ -250,7 +253,7 @@ func TestDownloadService_UpdateJobStatus(t *testing.T) {
 
 	// Execute - this should trigger panic recovery and updateJobStatus
 	jobID := "panic-recovery-job"
-	service.ProcessAsync(jobID, []string{"test:pass"}, "2024-01-01", "2024-01-31")
+	service.ProcessAsync(jobID, []string{"test"}, testFromDate, testToDate)
 
 	// Wait for panic recovery
 	time.Sleep(2 * time.Second)
@@ -269,6 +272,7 @@ func TestDownloadService_UpdateJobStatus(t *testing.T) {
 }
 
 func TestDownloadService_WithConfigurableMock(t *testing.T) {
+	registerAccounts(t, "test")
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 
 	// Create configurable mock with custom behavior
@@ -287,7 +291,7 @@ func TestDownloadService_WithConfigurableMock(t *testing.T) {
 
 	// Execute
 	jobID := "configurable-test"
-	service.ProcessAsync(jobID, []string{"test:pass"}, "2024-01-01", "2024-01-31")
+	service.ProcessAsync(jobID, []string{"test"}, testFromDate, testToDate)
 
 	// Wait for processing
 	time.Sleep(2 * time.Second)
@@ -309,31 +313,29 @@ func TestDownloadService_WithConfigurableMock(t *testing.T) {
 }
 
 func TestDownloadService_MultipleAccounts_WithMock(t *testing.T) {
+	registerAccounts(t, "acc1", "acc2", "acc3")
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 
-	// Track how many times each method is called
-	initCount := 0
-	loginCount := 0
-	downloadCount := 0
-	closeCount := 0
+	// Track how many times each method is called (accounts run in parallel)
+	var initCount, loginCount, downloadCount, closeCount atomic.Int32
 
 	mockFactory := &MockScraperFactory{
 		CreateFunc: func(config *scraper.ScraperConfig, logger *log.Logger) (scraper.ScraperInterface, error) {
 			mock := mocks.NewConfigurableETCScraper()
 			mock.InitializeFunc = func() error {
-				initCount++
+				initCount.Add(1)
 				return nil
 			}
 			mock.LoginFunc = func() error {
-				loginCount++
+				loginCount.Add(1)
 				return nil
 			}
 			mock.DownloadFunc = func(fromDate, toDate string) (string, error) {
-				downloadCount++
+				downloadCount.Add(1)
 				return "/test.csv", nil
 			}
 			mock.CloseFunc = func() error {
-				closeCount++
+				closeCount.Add(1)
 				return nil
 			}
 			return mock, nil
@@ -344,27 +346,27 @@ func TestDownloadService_MultipleAccounts_WithMock(t *testing.T) {
 
 	// Execute with multiple accounts
 	jobID := "multi-account-job"
-	accounts := []string{"acc1:pass1", "acc2:pass2", "acc3:pass3"}
-	service.ProcessAsync(jobID, accounts, "2024-01-01", "2024-01-31")
+	accounts := []string{"acc1", "acc2", "acc3"}
+	service.ProcessAsync(jobID, accounts, testFromDate, testToDate)
 
 	// Wait for processing
 	time.Sleep(3500 * time.Millisecond)
 
 	// Verify each account was processed
-	if initCount != 3 {
-		t.Errorf("Expected Initialize called 3 times, got %d", initCount)
+	if initCount.Load() != 3 {
+		t.Errorf("Expected Initialize called 3 times, got %d", initCount.Load())
 	}
 
-	if loginCount != 3 {
-		t.Errorf("Expected Login called 3 times, got %d", loginCount)
+	if loginCount.Load() != 3 {
+		t.Errorf("Expected Login called 3 times, got %d", loginCount.Load())
 	}
 
-	if downloadCount != 3 {
-		t.Errorf("Expected Download called 3 times, got %d", downloadCount)
+	if downloadCount.Load() != 3 {
+		t.Errorf("Expected Download called 3 times, got %d", downloadCount.Load())
 	}
 
-	if closeCount != 3 {
-		t.Errorf("Expected Close called 3 times, got %d", closeCount)
+	if closeCount.Load() != 3 {
+		t.Errorf("Expected Close called 3 times, got %d", closeCount.Load())
 	}
 
 	// Check job completed
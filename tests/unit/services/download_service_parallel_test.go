@@ -0,0 +1,119 @@
+package services_test
+
+import (
+	"errors"
+	"log"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+)
+
+func TestDownloadService_ProcessesAccountsInParallel(t *testing.T) {
+	registerAccounts(t, "acc1", "bad", "acc3", "acc4")
+	t.Setenv("ETC_DOWNLOAD_WORKERS", "2")
+
+	var running, maxRunning atomic.Int32
+	mockFactory := &MockScraperFactory{
+		CreateFunc: func(config *scraper.ScraperConfig, logger *log.Logger) (scraper.ScraperInterface, error) {
+			userID := config.UserID
+			mock := mocks.NewConfigurableETCScraper()
+			mock.DownloadFunc = func(fromDate, toDate string) (string, error) {
+				current := running.Add(1)
+				defer running.Add(-1)
+				for {
+					peak := maxRunning.Load()
+					if current <= peak || maxRunning.CompareAndSwap(peak, current) {
+						break
+					}
+				}
+				time.Sleep(100 * time.Millisecond)
+				if userID == "bad" {
+					return "", errors.New("download failed")
+				}
+				return "/" + userID + ".csv", nil
+			}
+			return mock, nil
+		},
+	}
+
+	service := services.NewDownloadServiceWithFactory(nil, log.New(os.Stdout, "[TEST] ", log.LstdFlags), mockFactory)
+
+	jobID := "parallel-job"
+	service.ProcessAsync(jobID, []string{"acc1", "bad", "acc3", "acc4"}, testFromDate, testToDate)
+	job := waitForJobStatus(t, service, jobID, "partially_failed")
+
+	if got := maxRunning.Load(); got != 2 {
+		t.Errorf("Expected 2 accounts at a time, got %d", got)
+	}
+	if job.Progress != 100 {
+		t.Errorf("Expected progress 100, got %d", job.Progress)
+	}
+	if len(job.Accounts) != 4 {
+		t.Fatalf("Expected 4 account results, got %d", len(job.Accounts))
+	}
+	for _, account := range job.Accounts {
+		if account.Progress != 100 {
+			t.Errorf("Account %s: expected progress 100, got %d", account.AccountID, account.Progress)
+		}
+		if account.AccountID == "bad" {
+			if account.Status != "failed" || account.ErrorMessage == "" {
+				t.Errorf("Expected failed result for bad account, got %+v", account)
+			}
+			continue
+		}
+		if account.Status != "completed" || account.CSVPath != "/"+account.AccountID+".csv" {
+			t.Errorf("Expected completed result, got %+v", account)
+		}
+	}
+}
+
+func TestDownloadService_ProgressCountsCompletedWork(t *testing.T) {
+	registerAccounts(t, "acc1", "acc2")
+	t.Setenv("ETC_DOWNLOAD_WORKERS", "1")
+
+	release := map[string]chan struct{}{
+		"acc1": make(chan struct{}),
+		"acc2": make(chan struct{}),
+	}
+	mockFactory := &MockScraperFactory{
+		CreateFunc: func(config *scraper.ScraperConfig, logger *log.Logger) (scraper.ScraperInterface, error) {
+			wait := release[config.UserID]
+			mock := mocks.NewConfigurableETCScraper()
+			mock.DownloadFunc = func(fromDate, toDate string) (string, error) {
+				<-wait
+				return "/test.csv", nil
+			}
+			return mock, nil
+		},
+	}
+
+	service := services.NewDownloadServiceWithFactory(nil, log.New(os.Stdout, "[TEST] ", log.LstdFlags), mockFactory)
+
+	jobID := "progress-job"
+	service.ProcessAsync(jobID, []string{"acc1", "acc2"}, testFromDate, testToDate)
+
+	// The first account has started but nothing has finished yet
+	time.Sleep(100 * time.Millisecond)
+	job, _ := service.GetJobStatus(jobID)
+	if job.Progress != 0 {
+		t.Errorf("Expected progress 0 while the first account runs, got %d", job.Progress)
+	}
+	if job.Accounts[0].Status != "processing" || job.Accounts[1].Status != "pending" {
+		t.Errorf("Unexpected account statuses: %+v", job.Accounts)
+	}
+
+	close(release["acc1"])
+	time.Sleep(100 * time.Millisecond)
+	job, _ = service.GetJobStatus(jobID)
+	if job.Progress != 50 {
+		t.Errorf("Expected progress 50 after the first account, got %d", job.Progress)
+	}
+
+	close(release["acc2"])
+	waitForJobStatus(t, service, jobID, "completed")
+}
@@ -0,0 +1,146 @@
+package services_test
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/yhonda-ohishi/etc_meisai_scraper/src/pb"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+)
+
+// attemptScraper adds a fixed attempt log to a mock scraper
+type attemptScraper struct {
+	scraper.ScraperInterface
+	attempts []scraper.Attempt
+}
+
+func (s *attemptScraper) Attempts() []scraper.Attempt {
+	return s.attempts
+}
+
+func TestDownloadService_AccountResults(t *testing.T) {
+	registerAccounts(t, "acc1", "bad")
+	csvFactory := newCSVScraperFactory(t, "bad")
+	factory := &MockScraperFactory{
+		CreateFunc: func(config *scraper.ScraperConfig, logger *log.Logger) (scraper.ScraperInterface, error) {
+			mock, err := csvFactory.CreateScraper(config, logger)
+			if err != nil {
+				return nil, err
+			}
+			return &attemptScraper{ScraperInterface: mock, attempts: []scraper.Attempt{
+				{Step: "login", Number: 1}, {Step: "login", Number: 2}, {Step: "download", Number: 1},
+			}}, nil
+		},
+	}
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceWithFactory(nil, logger, factory)
+	defer service.Shutdown(context.Background())
+
+	service.ProcessAsync("results-job", []string{"acc1", "bad"}, testFromDate, testToDate)
+	job := waitForJobStatus(t, service, "results-job", "partially_failed")
+
+	if job.CompletedAt == nil || job.RetryCount != 2 {
+		t.Errorf("Expected a finished job with 2 retries, got %+v", job)
+	}
+
+	completed := job.Accounts[0]
+	if completed.Status != "completed" || completed.CSVPath == "" || completed.RecordCount != 1 || completed.Attempts != 3 {
+		t.Errorf("Unexpected result for acc1: %+v", completed)
+	}
+	if completed.StartedAt == nil || completed.CompletedAt == nil || completed.Duration() <= 0 {
+		t.Errorf("Expected start and completion times for acc1, got %+v", completed)
+	}
+
+	failed := job.Accounts[1]
+	if failed.Status != "failed" || failed.ErrorCode != "INVALID_CREDENTIALS" || failed.ErrorMessage == "" || failed.Attempts != 3 {
+		t.Errorf("Unexpected result for bad: %+v", failed)
+	}
+	if failed.CSVPath != "" || failed.RecordCount != 0 || failed.CompletedAt == nil {
+		t.Errorf("Failed account should have no CSV, got %+v", failed)
+	}
+
+	grpcService := services.NewDownloadServiceGRPCWithService(service)
+	status, err := grpcService.GetJobStatus(context.Background(), &pb.GetJobStatusRequest{JobId: "results-job"})
+	if err != nil {
+		t.Fatalf("GetJobStatus failed: %v", err)
+	}
+	if status.Status != "partially_failed" || len(status.Accounts) != 2 {
+		t.Fatalf("Unexpected job status %v", status)
+	}
+	if account := status.Accounts[0]; account.RecordCount != 1 || account.Attempts != 3 || account.CsvPath != completed.CSVPath ||
+		account.StartedAt == nil || account.CompletedAt == nil || account.DurationMs != completed.Duration().Milliseconds() {
+		t.Errorf("Unexpected account result %v", account)
+	}
+	if account := status.Accounts[1]; account.Status != "failed" || account.ErrorCode != "INVALID_CREDENTIALS" {
+		t.Errorf("Unexpected account result %v", account)
+	}
+}
+
+func TestDownloadService_AllAccountsFailed(t *testing.T) {
+	registerAccounts(t, "bad1", "bad2")
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceWithFactory(nil, logger, newCSVScraperFactory(t, "bad1", "bad2"))
+	defer service.Shutdown(context.Background())
+
+	service.ProcessAsync("failed-job", []string{"bad1", "bad2"}, testFromDate, testToDate)
+	job := waitForJobStatus(t, service, "failed-job", "failed")
+
+	if job.ErrorCode != "INVALID_CREDENTIALS" || job.ErrorMessage == "" || job.CompletedAt == nil {
+		t.Errorf("Expected the login error on the job, got %+v", job)
+	}
+	for _, account := range job.Accounts {
+		if account.Status != "failed" {
+			t.Errorf("Expected account %s to fail, got %s", account.AccountID, account.Status)
+		}
+	}
+}
+
+func TestDownloadService_CountsRecords(t *testing.T) {
+	registerAccounts(t, "acc1", "acc2")
+	// 3 valid rows and 1 row the parser rejects
+	data := append(siteCSV(t, 3), []byte("broken,row\r\n")...)
+	dir := t.TempDir()
+	factory := &MockScraperFactory{
+		CreateFunc: func(config *scraper.ScraperConfig, logger *log.Logger) (scraper.ScraperInterface, error) {
+			userID := config.UserID
+			mock := mocks.NewConfigurableETCScraper()
+			mock.DownloadFunc = func(fromDate, toDate string) (string, error) {
+				path := filepath.Join(dir, userID+".csv")
+				return path, os.WriteFile(path, data, 0644)
+			}
+			return mock, nil
+		},
+	}
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceWithFactory(nil, logger, factory)
+	defer service.Shutdown(context.Background())
+
+	service.ProcessAsync("count-job", []string{"acc1", "acc2"}, testFromDate, testToDate)
+	job := waitForJobStatus(t, service, "count-job", "completed")
+
+	for _, account := range job.Accounts {
+		if account.RecordCount != 3 || account.RejectedRows != 1 || account.BytesDownloaded != int64(len(data)) {
+			t.Errorf("Unexpected counts for %s: %+v", account.AccountID, account)
+		}
+	}
+	if job.TotalRecords != 6 || job.RejectedRows != 2 || job.BytesDownloaded != 2*int64(len(data)) {
+		t.Errorf("Expected totals 6 records, 2 rejected rows and %d bytes, got %d, %d, %d",
+			2*len(data), job.TotalRecords, job.RejectedRows, job.BytesDownloaded)
+	}
+
+	status, err := services.NewDownloadServiceGRPCWithService(service).GetJobStatus(context.Background(), &pb.GetJobStatusRequest{JobId: "count-job"})
+	if err != nil {
+		t.Fatalf("GetJobStatus failed: %v", err)
+	}
+	if status.TotalRecords != 6 || status.RejectedRows != 2 || status.BytesDownloaded != 2*int64(len(data)) {
+		t.Errorf("Unexpected totals in job status %v", status)
+	}
+	if account := status.Accounts[1]; account.RecordCount != 3 || account.RejectedRows != 1 || account.BytesDownloaded != int64(len(data)) {
+		t.Errorf("Unexpected account counts %v", account)
+	}
+}
@@ -4,10 +4,19 @@ import (
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
 )
 
+// testFromDate/testToDate は明細サイトの保持期間内に収まる前月1日〜末日
+var testFromDate, testToDate = func() (string, string) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 1, -1)
+	return from.Format("2006-01-02"), to.Format("2006-01-02")
+}()
+
 func TestDownloadService_GetAllAccountIDs(t *testing.T) {
 	// Setup
 	os.Setenv("ETC_CORPORATE_ACCOUNTS", "corp1:pass1,corp2:pass2")
@@ -37,14 +46,15 @@ func TestDownloadService_GetAllAccountIDs(t *testing.T) {
 }
 
 func TestDownloadService_ProcessAsync(t *testing.T) {
+	registerAccounts(t, "test1", "test2")
 	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
 	service := services.NewDownloadService(nil, logger)
 
 	// Test async processing
 	jobID := "test-job-123"
-	accounts := []string{"test1:pass1", "test2:pass2"}
-	fromDate := "2024-01-01"
-	toDate := "2024-01-31"
+	accounts := []string{"test1", "test2"}
+	fromDate := testFromDate
+	toDate := testToDate
 
 	// This should not panic or error
 	service.ProcessAsync(jobID, accounts, fromDate, toDate)
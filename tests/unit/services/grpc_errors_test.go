@@ -0,0 +1,175 @@
+package services_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestScraperErrorStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		code     codes.Code
+		reason   string
+		metadata map[string]string
+	}{
+		{
+			name:     "invalid credentials",
+			err:      fmt.Errorf("login failed for account acc1: %w", &scraper.LoginError{Kind: scraper.ErrInvalidCredentials, Message: "認証エラー"}),
+			code:     codes.Unauthenticated,
+			reason:   "INVALID_CREDENTIALS",
+			metadata: map[string]string{"account_id": "acc1", "site_message": "認証エラー"},
+		},
+		{name: "account locked", err: &scraper.LoginError{Kind: scraper.ErrAccountLocked}, code: codes.PermissionDenied, reason: "ACCOUNT_LOCKED"},
+		{name: "password expired", err: &scraper.LoginError{Kind: scraper.ErrPasswordExpired}, code: codes.FailedPrecondition, reason: "PASSWORD_EXPIRED"},
+		{name: "maintenance", err: scraper.ErrSiteMaintenance, code: codes.Unavailable, reason: "SITE_MAINTENANCE"},
+		{name: "no results", err: scraper.ErrNoResults, code: codes.NotFound, reason: "NO_RESULTS"},
+		{
+			name:     "download timeout after retries",
+			err:      &scraper.StepError{Step: scraper.StepDownloadSave, Attempts: 4, Err: scraper.ErrDownloadTimeout},
+			code:     codes.DeadlineExceeded,
+			reason:   "DOWNLOAD_TIMEOUT",
+			metadata: map[string]string{"step": "download_save", "attempts": "4"},
+		},
+		{name: "selector drift", err: &scraper.SelectorError{Element: "CSV download link"}, code: codes.Internal, reason: "SELECTOR_DRIFT"},
+		{name: "browser crash", err: fmt.Errorf("%w: Target closed", scraper.ErrBrowserCrash), code: codes.Unavailable, reason: "BROWSER_CRASH"},
+		{
+			name:   "navigation error",
+			err:    &scraper.StepError{Step: scraper.StepNavigation, Attempts: 4, Err: errors.New("page.goto: net::ERR_CONNECTION_RESET at https://www.etc-meisai.jp/")},
+			code:   codes.Unavailable,
+			reason: "UNKNOWN",
+		},
+		{name: "server error", err: errors.New("server temporarily unavailable (502 Bad Gateway) for https://www.etc-meisai.jp/"), code: codes.Unavailable, reason: "UNKNOWN"},
+		{name: "outside retention", err: services.ErrRangeOutsideRetention, code: codes.InvalidArgument, reason: "RANGE_OUTSIDE_RETENTION"},
+		{name: "accounts required", err: services.ErrAccountsRequired, code: codes.InvalidArgument, reason: "ACCOUNTS_REQUIRED"},
+		{
+			name:     "invalid account format",
+			err:      &services.AccountError{AccountID: "acc1", Err: services.ErrInvalidAccountFormat},
+			code:     codes.InvalidArgument,
+			reason:   "INVALID_ACCOUNT_FORMAT",
+			metadata: map[string]string{"account_id": "acc1"},
+		},
+		{name: "no accounts configured", err: services.ErrNoAccountsConfigured, code: codes.FailedPrecondition, reason: "NO_ACCOUNTS_CONFIGURED"},
+		{name: "job not found", err: services.ErrJobNotFound, code: codes.NotFound, reason: "JOB_NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accountID := ""
+			if tt.metadata["account_id"] != "" {
+				accountID = tt.metadata["account_id"]
+			}
+
+			st, ok := status.FromError(services.ScraperErrorStatus(tt.err, accountID))
+			if !ok {
+				t.Fatal("Expected gRPC status error")
+			}
+			if st.Code() != tt.code {
+				t.Errorf("Expected code %v, got %v", tt.code, st.Code())
+			}
+
+			var info *errdetails.ErrorInfo
+			for _, detail := range st.Details() {
+				if d, ok := detail.(*errdetails.ErrorInfo); ok {
+					info = d
+				}
+			}
+			if info == nil {
+				t.Fatal("Expected ErrorInfo detail")
+			}
+			if info.Reason != tt.reason {
+				t.Errorf("Expected reason %s, got %s", tt.reason, info.Reason)
+			}
+			for key, value := range tt.metadata {
+				if info.Metadata[key] != value {
+					t.Errorf("Expected metadata %s=%s, got %q", key, value, info.Metadata[key])
+				}
+			}
+		})
+	}
+
+	if services.ScraperErrorStatus(nil, "acc1") != nil {
+		t.Error("Expected nil for nil error")
+	}
+
+	existing := status.Error(codes.Aborted, "already a status")
+	if !errors.Is(services.ScraperErrorStatus(existing, ""), existing) {
+		t.Error("Expected existing status errors to be returned unchanged")
+	}
+}
+
+func TestJobErrorStatus(t *testing.T) {
+	err := services.JobErrorStatus(&services.AccountError{AccountID: "acc1", Err: scraper.ErrSiteMaintenance}, "job-1")
+
+	st := status.Convert(err)
+	if st.Code() != codes.Unavailable {
+		t.Errorf("Expected Unavailable, got %v", st.Code())
+	}
+	info := errorInfo(t, err)
+	if info.Reason != "SITE_MAINTENANCE" || info.Metadata["job_id"] != "job-1" || info.Metadata["account_id"] != "acc1" {
+		t.Errorf("Unexpected error info %+v", info)
+	}
+
+	if services.JobErrorStatus(nil, "job-1") != nil {
+		t.Error("Expected nil for nil error")
+	}
+}
+
+func TestDownloadService_RecordsErrorCode(t *testing.T) {
+	registerAccounts(t, "acc1")
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	mockScraper := mocks.NewMockETCScraper()
+	mockScraper.LoginError = &scraper.LoginError{Kind: scraper.ErrAccountLocked, Message: "アカウントがロックされています"}
+
+	service := services.NewDownloadServiceWithFactory(nil, logger, &MockScraperFactory{MockScraper: mockScraper})
+	service.ProcessAsync("error-code-job", []string{"acc1"}, testFromDate, testToDate)
+
+	time.Sleep(2 * time.Second)
+
+	job, exists := service.GetJobStatus("error-code-job")
+	if !exists {
+		t.Fatal("Job should exist")
+	}
+	if job.ErrorCode != "ACCOUNT_LOCKED" {
+		t.Errorf("Expected error code ACCOUNT_LOCKED, got %q", job.ErrorCode)
+	}
+	if job.ErrorDetails["account_id"] != "acc1" {
+		t.Errorf("Expected account_id without the password, got %v", job.ErrorDetails)
+	}
+}
+
+func TestDownloadService_RecordsEvidencePath(t *testing.T) {
+	registerAccounts(t, "acc1")
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	mockScraper := mocks.NewMockETCScraper()
+	mockScraper.DownloadError = &scraper.StepError{
+		Step:     scraper.StepCSVLink,
+		Attempts: 1,
+		Err:      &scraper.SelectorError{Element: "CSV download link", Selectors: []string{"a:has-text('明細ＣＳＶ')"}},
+		Evidence: "downloads/20250101_000000/evidence/20250101_000001.000_csv_link",
+	}
+
+	service := services.NewDownloadServiceWithFactory(nil, logger, &MockScraperFactory{MockScraper: mockScraper})
+	service.ProcessAsync("evidence-job", []string{"acc1"}, testFromDate, testToDate)
+
+	time.Sleep(2 * time.Second)
+
+	job, _ := service.GetJobStatus("evidence-job")
+	if job.ErrorDetails["evidence_path"] != mockScraper.DownloadError.(*scraper.StepError).Evidence {
+		t.Errorf("Expected evidence_path in job error details, got %v", job.ErrorDetails)
+	}
+	if job.ErrorDetails["step"] != scraper.StepCSVLink || job.ErrorDetails["element"] != "CSV download link" {
+		t.Errorf("Unexpected error details: %v", job.ErrorDetails)
+	}
+}
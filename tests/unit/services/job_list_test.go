@@ -0,0 +1,201 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	pb "github.com/yhonda-ohishi/etc_meisai_scraper/src/pb"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newListJobsService returns a service whose store holds job-0 .. job-9 started an hour apart
+// Even jobs are completed for acc1, odd jobs failed for acc2
+func newListJobsService(t *testing.T, base time.Time) *services.DownloadService {
+	t.Helper()
+	store := services.NewMemoryJobStore()
+	for i := 0; i < 10; i++ {
+		job := &services.DownloadJob{
+			ID:        fmt.Sprintf("job-%d", i),
+			Status:    "completed",
+			StartedAt: base.Add(time.Duration(i) * time.Hour),
+			Accounts:  []services.AccountResult{{AccountID: "acc1", Status: "completed", Progress: 100}},
+		}
+		if i%2 == 1 {
+			job.Status = "failed"
+			job.Accounts = []services.AccountResult{
+				{AccountID: "acc1", Status: "completed", Progress: 100},
+				{AccountID: "acc2", Status: "failed", Progress: 100, ErrorCode: "INVALID_CREDENTIALS"},
+			}
+		}
+		store.Save(job)
+	}
+
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceWithStore(nil, logger, mocks.NewMockScraperFactory(), store)
+	t.Cleanup(func() { service.Shutdown(context.Background()) })
+	return service
+}
+
+func jobIDs(jobs []*services.DownloadJob) []string {
+	ids := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.ID)
+	}
+	return ids
+}
+
+func TestDownloadService_ListJobs_Filters(t *testing.T) {
+	base := time.Date(2025, 4, 1, 0, 0, 0, 0, time.Local)
+	service := newListJobsService(t, base)
+
+	tests := []struct {
+		name     string
+		filter   services.JobFilter
+		expected string
+	}{
+		{"newest first by default", services.JobFilter{PageSize: 3}, "[job-9 job-8 job-7]"},
+		{"oldest first", services.JobFilter{PageSize: 3, Ascending: true}, "[job-0 job-1 job-2]"},
+		{"status", services.JobFilter{Statuses: []string{"failed"}}, "[job-9 job-7 job-5 job-3 job-1]"},
+		{"account", services.JobFilter{AccountID: "acc2", Ascending: true}, "[job-1 job-3 job-5 job-7 job-9]"},
+		{"time window", services.JobFilter{StartedAfter: base.Add(2 * time.Hour), StartedBefore: base.Add(5 * time.Hour)}, "[job-4 job-3 job-2]"},
+		{"no match", services.JobFilter{AccountID: "unknown"}, "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := service.ListJobs(tt.filter)
+			if err != nil {
+				t.Fatalf("ListJobs() error: %v", err)
+			}
+			if got := fmt.Sprint(jobIDs(page.Jobs)); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDownloadService_ListJobs_Pagination(t *testing.T) {
+	registerAccounts(t, "acc1")
+	base := time.Date(2025, 4, 1, 0, 0, 0, 0, time.Local)
+	service := newListJobsService(t, base)
+
+	filter := services.JobFilter{Statuses: []string{"completed"}, PageSize: 2}
+	var pages []string
+	for {
+		page, err := service.ListJobs(filter)
+		if err != nil {
+			t.Fatalf("ListJobs() error: %v", err)
+		}
+		pages = append(pages, fmt.Sprint(jobIDs(page.Jobs)))
+		if page.NextPageToken == "" {
+			break
+		}
+		if len(pages) == 1 {
+			// ページの間に追加されたジョブは次のページに影響しない
+			service.ProcessAsync("new-job", []string{"acc1"}, testFromDate, testToDate)
+		}
+		filter.PageToken = page.NextPageToken
+	}
+
+	expected := "[[job-8 job-6] [job-4 job-2] [job-0]]"
+	if got := fmt.Sprint(pages); got != expected {
+		t.Errorf("Expected pages %s, got %s", expected, got)
+	}
+
+	// A full last page has no next page token
+	page, _ := service.ListJobs(services.JobFilter{Statuses: []string{"failed"}, PageSize: 5})
+	if len(page.Jobs) != 5 || page.NextPageToken != "" {
+		t.Errorf("Expected 5 jobs without a next page, got %v (token %q)", jobIDs(page.Jobs), page.NextPageToken)
+	}
+}
+
+func TestDownloadService_ListJobs_Errors(t *testing.T) {
+	base := time.Now()
+	service := newListJobsService(t, base)
+
+	tests := []struct {
+		name     string
+		filter   services.JobFilter
+		expected error
+	}{
+		{"negative page size", services.JobFilter{PageSize: -1}, services.ErrInvalidJobFilter},
+		{"empty time window", services.JobFilter{StartedAfter: base, StartedBefore: base}, services.ErrInvalidJobFilter},
+		{"malformed page token", services.JobFilter{PageToken: "not a token"}, services.ErrInvalidPageToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.ListJobs(tt.filter); !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestParseJobOrder(t *testing.T) {
+	for orderBy, expected := range map[string]bool{"": false, "started_at desc": false, "started_at": true, " Started_At  ASC ": true} {
+		ascending, err := services.ParseJobOrder(orderBy)
+		if err != nil || ascending != expected {
+			t.Errorf("ParseJobOrder(%q) = %v, %v", orderBy, ascending, err)
+		}
+	}
+	if _, err := services.ParseJobOrder("id"); !errors.Is(err, services.ErrInvalidJobFilter) {
+		t.Errorf("Expected ErrInvalidJobFilter, got %v", err)
+	}
+}
+
+func TestDownloadServiceGRPC_ListJobs(t *testing.T) {
+	base := time.Date(2025, 4, 1, 0, 0, 0, 0, time.Local)
+	service := services.NewDownloadServiceGRPCWithService(newListJobsService(t, base))
+
+	resp, err := service.ListJobs(context.Background(), &pb.ListJobsRequest{
+		Statuses:     []string{"failed"},
+		StartedAfter: timestamppb.New(base.Add(4 * time.Hour)),
+		PageSize:     2,
+		OrderBy:      "started_at",
+	})
+	if err != nil {
+		t.Fatalf("ListJobs failed: %v", err)
+	}
+	if len(resp.Jobs) != 2 || resp.Jobs[0].JobId != "job-5" || resp.Jobs[1].JobId != "job-7" || resp.NextPageToken == "" {
+		t.Fatalf("Unexpected response %v", resp)
+	}
+
+	summary := resp.Jobs[0]
+	if summary.AccountStatusCounts["completed"] != 1 || summary.AccountStatusCounts["failed"] != 1 {
+		t.Errorf("Unexpected account status counts %v", summary.AccountStatusCounts)
+	}
+	if len(summary.Accounts) != 2 || summary.Accounts[1].AccountId != "acc2" || summary.Accounts[1].ErrorCode != "INVALID_CREDENTIALS" {
+		t.Errorf("Unexpected account results %v", summary.Accounts)
+	}
+	if !summary.StartedAt.AsTime().Equal(base.Add(5 * time.Hour)) {
+		t.Errorf("Unexpected started_at %v", summary.StartedAt.AsTime())
+	}
+
+	next, err := service.ListJobs(context.Background(), &pb.ListJobsRequest{
+		Statuses:     []string{"failed"},
+		StartedAfter: timestamppb.New(base.Add(4 * time.Hour)),
+		PageSize:     2,
+		PageToken:    resp.NextPageToken,
+		OrderBy:      "started_at",
+	})
+	if err != nil || len(next.Jobs) != 1 || next.Jobs[0].JobId != "job-9" || next.NextPageToken != "" {
+		t.Errorf("Unexpected second page %v (%v)", next, err)
+	}
+
+	for _, req := range []*pb.ListJobsRequest{{OrderBy: "status"}, {PageToken: "!"}} {
+		_, err := service.ListJobs(context.Background(), req)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %v, got %v", req, err)
+		}
+	}
+}
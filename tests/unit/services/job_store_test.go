@@ -0,0 +1,207 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/tests/mocks"
+)
+
+func openJobStore(t *testing.T, path string) *services.FileJobStore {
+	t.Helper()
+	store, err := services.OpenFileJobStore(path, log.New(os.Stdout, "[TEST] ", log.LstdFlags))
+	if err != nil {
+		t.Fatalf("OpenFileJobStore failed: %v", err)
+	}
+	return store
+}
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read job store: %v", err)
+	}
+	return bytes.Count(data, []byte("\n"))
+}
+
+func TestMemoryJobStore_StoresCopies(t *testing.T) {
+	store := services.NewMemoryJobStore()
+	started := time.Now()
+
+	job := &services.DownloadJob{ID: "b", Status: "processing", StartedAt: started, Accounts: []services.AccountResult{{AccountID: "acc1"}}}
+	store.Save(job)
+	store.Save(&services.DownloadJob{ID: "a", Status: "completed", StartedAt: started.Add(-time.Minute)})
+	job.Status = "changed"
+	job.Accounts[0].Status = "changed"
+
+	saved, exists := store.Get("b")
+	if !exists {
+		t.Fatal("Expected job b")
+	}
+	if saved.Status != "processing" || saved.Accounts[0].Status != "" {
+		t.Errorf("Store should keep a copy, got %+v", saved)
+	}
+	saved.Accounts[0].Status = "changed"
+	if again, _ := store.Get("b"); again.Accounts[0].Status != "" {
+		t.Error("Get should return a copy")
+	}
+
+	jobs := store.List()
+	if len(jobs) != 2 || jobs[0].ID != "a" || jobs[1].ID != "b" {
+		t.Errorf("Expected jobs ordered by start time, got %v", jobs)
+	}
+	if _, exists := store.Get("missing"); exists {
+		t.Error("Unexpected job")
+	}
+}
+
+func TestFileJobStore_SurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs", "jobs.jsonl")
+	store := openJobStore(t, path)
+
+	completedAt := time.Now()
+	job := &services.DownloadJob{ID: "job-1", Status: "processing", StartedAt: completedAt.Add(-time.Minute)}
+	for progress := 0; progress <= 100; progress += 50 {
+		job.Progress = progress
+		if err := store.Save(job); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	job.Status = "failed"
+	job.ErrorCode = "ACCOUNT_LOCKED"
+	job.ErrorDetails = map[string]string{"account_id": "acc1"}
+	job.CompletedAt = &completedAt
+	store.Save(job)
+	store.Save(&services.DownloadJob{ID: "job-2", Status: "completed", StartedAt: completedAt})
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := store.Save(job); !errors.Is(err, services.ErrJobStoreClosed) {
+		t.Errorf("Expected ErrJobStoreClosed, got %v", err)
+	}
+
+	// A crash in the middle of an append leaves a partial last line
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	file.WriteString(`{"id":"job-1","status":"proc`)
+	file.Close()
+
+	reopened := openJobStore(t, path)
+	defer reopened.Close()
+
+	loaded, exists := reopened.Get("job-1")
+	if !exists {
+		t.Fatal("Expected job-1 after reopening")
+	}
+	if loaded.Status != "failed" || loaded.Progress != 100 || loaded.ErrorDetails["account_id"] != "acc1" {
+		t.Errorf("Expected the latest state of job-1, got %+v", loaded)
+	}
+	if loaded.CompletedAt == nil || !loaded.CompletedAt.Equal(completedAt) {
+		t.Errorf("Expected CompletedAt %v, got %v", completedAt, loaded.CompletedAt)
+	}
+	if len(reopened.List()) != 2 {
+		t.Errorf("Expected 2 jobs, got %d", len(reopened.List()))
+	}
+	if lines := countLines(t, path); lines != 2 {
+		t.Errorf("Expected the log to be compacted to 2 lines, got %d", lines)
+	}
+}
+
+func TestFileJobStore_CompactsWhileAppending(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.jsonl")
+	store := openJobStore(t, path)
+	defer store.Close()
+
+	job := &services.DownloadJob{ID: "job-1", Status: "processing", StartedAt: time.Now()}
+	for i := 0; i < 100; i++ {
+		job.Progress = i
+		if err := store.Save(job); err != nil {
+			t.Fatalf("Save %d failed: %v", i, err)
+		}
+	}
+
+	if lines := countLines(t, path); lines > 10 {
+		t.Errorf("Expected the log to be compacted, got %d lines", lines)
+	}
+	if loaded, _ := store.Get("job-1"); loaded.Progress != 99 {
+		t.Errorf("Expected progress 99, got %d", loaded.Progress)
+	}
+}
+
+func TestDownloadService_InterruptsJobsOnStartup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.jsonl")
+	store := openJobStore(t, path)
+	store.Save(&services.DownloadJob{
+		ID:        "crashed-job",
+		Status:    "processing",
+		StartedAt: time.Now(),
+		Accounts: []services.AccountResult{
+			{AccountID: "acc1", Status: "completed", Progress: 100},
+			{AccountID: "acc2", Status: "processing", Progress: 50},
+			{AccountID: "acc3", Status: "pending"},
+		},
+	})
+	store.Save(&services.DownloadJob{ID: "finished-job", Status: "completed", Progress: 100, StartedAt: time.Now()})
+	store.Close()
+
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceWithStore(nil, logger, mocks.NewMockScraperFactory(), openJobStore(t, path))
+	defer service.Shutdown(context.Background())
+
+	job, exists := service.GetJobStatus("crashed-job")
+	if !exists {
+		t.Fatal("Expected the job from the previous run")
+	}
+	if job.Status != "interrupted" || job.ErrorCode != "JOB_INTERRUPTED" || job.CompletedAt == nil {
+		t.Errorf("Expected an interrupted job, got %+v", job)
+	}
+	statuses := []string{job.Accounts[0].Status, job.Accounts[1].Status, job.Accounts[2].Status}
+	if statuses[0] != "completed" || statuses[1] != "interrupted" || statuses[2] != "interrupted" {
+		t.Errorf("Unexpected account statuses %v", statuses)
+	}
+
+	if finished, _ := service.GetJobStatus("finished-job"); finished.Status != "completed" {
+		t.Errorf("Finished jobs should be left alone, got %s", finished.Status)
+	}
+}
+
+func TestDownloadService_PersistsJobs(t *testing.T) {
+	registerAccounts(t, "acc1")
+	path := filepath.Join(t.TempDir(), "jobs.jsonl")
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	service := services.NewDownloadServiceWithStore(nil, logger, newCSVScraperFactory(t), openJobStore(t, path))
+
+	service.ProcessAsync("persisted-job", []string{"acc1"}, testFromDate, testToDate)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if job, _ := service.GetJobStatus("persisted-job"); job.Status == "completed" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Job did not complete")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := service.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	restarted := services.NewDownloadServiceWithStore(nil, logger, newCSVScraperFactory(t), openJobStore(t, path))
+	defer restarted.Shutdown(context.Background())
+
+	job, exists := restarted.GetJobStatus("persisted-job")
+	if !exists {
+		t.Fatal("Expected the job to survive the restart")
+	}
+	if job.Status != "completed" || len(job.Accounts) != 1 || job.Accounts[0].Status != "completed" {
+		t.Errorf("Unexpected job after restart %+v", job)
+	}
+}
@@ -0,0 +1,134 @@
+package services_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+)
+
+func TestPlanDateRangesAt(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name        string
+		fromDate    string
+		toDate      string
+		expected    []string
+		expectError error
+	}{
+		{
+			name:     "single month",
+			fromDate: "2025-05-01",
+			toDate:   "2025-05-31",
+			expected: []string{"2025-05-01 - 2025-05-31"},
+		},
+		{
+			name:     "partial months",
+			fromDate: "2025-03-15",
+			toDate:   "2025-05-10",
+			expected: []string{
+				"2025-03-15 - 2025-03-31",
+				"2025-04-01 - 2025-04-30",
+				"2025-05-01 - 2025-05-10",
+			},
+		},
+		{
+			name:     "across year boundary",
+			fromDate: "2024-12-20",
+			toDate:   "2025-01-05",
+			expected: []string{"2024-12-20 - 2024-12-31", "2025-01-01 - 2025-01-05"},
+		},
+		{
+			name:     "oldest searchable month",
+			fromDate: "2024-03-01",
+			toDate:   "2024-03-31",
+			expected: []string{"2024-03-01 - 2024-03-31"},
+		},
+		{
+			name:        "older than retention window",
+			fromDate:    "2024-02-29",
+			toDate:      "2024-03-31",
+			expectError: services.ErrRangeOutsideRetention,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			windows, err := services.PlanDateRangesAt(tt.fromDate, tt.toDate, now)
+			if tt.expectError != nil {
+				if !errors.Is(err, tt.expectError) {
+					t.Fatalf("Expected error %v, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(windows) != len(tt.expected) {
+				t.Fatalf("Expected %d windows, got %d: %v", len(tt.expected), len(windows), windows)
+			}
+			for i, window := range windows {
+				if window.String() != tt.expected[i] {
+					t.Errorf("Window %d: expected %s, got %s", i, tt.expected[i], window.String())
+				}
+			}
+		})
+	}
+}
+
+func TestPlanDateRanges_InvalidRange(t *testing.T) {
+	_, err := services.PlanDateRanges("2025-02-01", "2025-01-01")
+	var rangeErr *scraper.DateRangeError
+	if !errors.As(err, &rangeErr) {
+		t.Fatalf("Expected *scraper.DateRangeError, got %v", err)
+	}
+}
+
+func TestMergeCSVFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "a.csv")
+	second := filepath.Join(dir, "b.csv")
+	os.WriteFile(first, []byte("date,ic\r\n2025/03/31,A\r\n2025/03/31,B\r\n"), 0644)
+	os.WriteFile(second, []byte("date,ic\r\n2025/03/31,B\r\n2025/04/01,C\r\n\r\n"), 0644)
+
+	dest := filepath.Join(dir, "merged.csv")
+	rows, err := services.MergeCSVFiles([]string{first, second}, dest)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rows != 3 {
+		t.Errorf("Expected 3 rows, got %d", rows)
+	}
+
+	data, _ := os.ReadFile(dest)
+	expected := "date,ic\r\n2025/03/31,A\r\n2025/03/31,B\r\n2025/04/01,C\r\n"
+	if string(data) != expected {
+		t.Errorf("Unexpected merged content: %q", string(data))
+	}
+
+	if _, err := services.MergeCSVFiles([]string{filepath.Join(dir, "missing.csv")}, dest); err == nil ||
+		!strings.Contains(err.Error(), "failed to read CSV") {
+		t.Errorf("Expected read error, got %v", err)
+	}
+}
+
+func TestMergeCSV_KeepsIdenticalRowsWithinAFile(t *testing.T) {
+	// Two identical trips on the overlapping day are in both windows; the second also has two of its own
+	first := []byte("date,ic\r\n2025/03/31,A\r\n2025/03/31,A\r\n")
+	second := []byte("date,ic\r\n2025/03/31,A\r\n2025/03/31,A\r\n2025/04/01,C\r\n2025/04/01,C\r\n")
+
+	merged, rows := services.MergeCSV([][]byte{first, second})
+	if rows != 4 {
+		t.Errorf("Expected 4 rows, got %d", rows)
+	}
+	expected := "date,ic\r\n2025/03/31,A\r\n2025/03/31,A\r\n2025/04/01,C\r\n2025/04/01,C\r\n"
+	if string(merged) != expected {
+		t.Errorf("Unexpected merged content: %q", string(merged))
+	}
+}
@@ -0,0 +1,56 @@
+package services_test
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/scraper"
+	"github.com/yhonda-ohishi/etc_meisai_scraper/src/services"
+)
+
+func TestDefaultScraperFactory_Backend(t *testing.T) {
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	factory := services.NewDefaultScraperFactory()
+
+	tests := []struct {
+		backend     string
+		expectHTTP  bool
+		expectError bool
+	}{
+		{backend: ""},
+		{backend: scraper.BackendPlaywright},
+		{backend: scraper.BackendHTTP, expectHTTP: true},
+		{backend: "selenium", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			config := &scraper.ScraperConfig{
+				UserID:       "user",
+				Password:     "pass",
+				Backend:      tt.backend,
+				DownloadPath: t.TempDir(),
+			}
+			s, err := factory.CreateScraper(config, logger)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error for unknown backend")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			_, isFallback := s.(*scraper.FallbackScraper)
+			_, isETC := s.(*scraper.ETCScraper)
+			if tt.expectHTTP && !isFallback {
+				t.Errorf("Expected *scraper.FallbackScraper, got %T", s)
+			}
+			if !tt.expectHTTP && !isETC {
+				t.Errorf("Expected *scraper.ETCScraper, got %T", s)
+			}
+		})
+	}
+}